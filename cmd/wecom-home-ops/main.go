@@ -18,14 +18,17 @@ import (
 
 	"github.com/zcw199604/wecom-home-ops/internal/app"
 	"github.com/zcw199604/wecom-home-ops/internal/config"
+	"github.com/zcw199604/wecom-home-ops/internal/core"
 	"github.com/zcw199604/wecom-home-ops/internal/wecom"
 )
 
 func main() {
 	var configPath string
 	var wecomSyncMenu bool
+	var wecomCreateAppChat bool
 	flag.StringVar(&configPath, "config", "config.yaml", "配置文件路径（YAML）")
-	flag.BoolVar(&wecomSyncMenu, "wecom-sync-menu", false, "同步企业微信应用自定义菜单（menu/create）后退出")
+	flag.BoolVar(&wecomSyncMenu, "wecom-sync-menu", false, "对比当前菜单（menu/get）并输出差异，同步企业微信应用自定义菜单（menu/create）后退出")
+	flag.BoolVar(&wecomCreateAppChat, "wecom-create-appchat", false, "按 wecom.appchat.create 创建运维群聊（appchat/create）并输出 chatid 后退出")
 	flag.Parse()
 
 	startedAt := time.Now()
@@ -47,24 +50,32 @@ func main() {
 	slog.SetDefault(logger)
 
 	if wecomSyncMenu {
-		httpClient := &http.Client{
-			Timeout: cfg.Server.HTTPClientTimeout.ToDuration(),
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		failed := false
+		for _, wecomApp := range cfg.WeComApps() {
+			if err := syncWeComMenu(ctx, cfg, wecomApp); err != nil {
+				slog.Error("企业微信自定义菜单同步失败", "app", wecomApp.Name, "agentid", wecomApp.AgentID, "error", err)
+				failed = true
+			}
 		}
-		wecomClient := wecom.NewClient(wecom.ClientConfig{
-			APIBaseURL: cfg.WeCom.APIBaseURL,
-			CorpID:     cfg.WeCom.CorpID,
-			AgentID:    cfg.WeCom.AgentID,
-			Secret:     cfg.WeCom.Secret,
-		}, httpClient)
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
 
+	if wecomCreateAppChat {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 
-		if err := wecomClient.CreateMenu(ctx, wecom.DefaultMenu()); err != nil {
-			slog.Error("企业微信自定义菜单同步失败", "error", err)
+		chatID, err := createAppChat(ctx, cfg)
+		if err != nil {
+			slog.Error("企业微信群聊创建失败", "error", err)
 			os.Exit(1)
 		}
-		slog.Info("企业微信自定义菜单同步成功")
+		slog.Info("企业微信群聊创建成功（请将 chatid 写入 wecom.appchat.chats）", "chatid", chatID)
 		return
 	}
 
@@ -107,11 +118,52 @@ func main() {
 	}
 }
 
+// createAppChat 创建运维群聊并发送一条欢迎消息（新建群聊在首条消息下发前不会出现在客户端）。
+func createAppChat(ctx context.Context, cfg config.Config) (string, error) {
+	create := cfg.WeCom.AppChat.Create
+	userList := uniqueNonEmpty(create.UserList)
+	if len(userList) == 0 {
+		userList = uniqueNonEmpty(cfg.Auth.AllowedUserIDs)
+	}
+	owner := strings.TrimSpace(create.Owner)
+	if owner == "" && len(userList) > 0 {
+		owner = userList[0]
+	}
+	name := strings.TrimSpace(create.Name)
+	if name == "" {
+		name = "wecom-home-ops 运维群"
+	}
+
+	httpClient := &http.Client{
+		Timeout: cfg.Server.HTTPClientTimeout.ToDuration(),
+	}
+	wecomClient := wecom.NewClient(wecom.ClientConfig{
+		APIBaseURL: cfg.WeCom.APIBaseURL,
+		CorpID:     cfg.WeCom.CorpID,
+		AgentID:    cfg.WeCom.AgentID,
+		Secret:     cfg.WeCom.Secret,
+	}, httpClient)
+
+	chatID, err := wecomClient.CreateAppChat(ctx, wecom.AppChat{
+		Name:     name,
+		Owner:    owner,
+		UserList: userList,
+		ChatID:   create.ChatID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := wecomClient.SendAppChat(ctx, wecom.AppChatMessage{
+		ChatID:  chatID,
+		Content: fmt.Sprintf("%s 已创建，后续告警与操作播报将推送到本群。", name),
+	}); err != nil {
+		return chatID, fmt.Errorf("群聊已创建（chatid=%s），但欢迎消息发送失败：%w", chatID, err)
+	}
+	return chatID, nil
+}
+
 func sendStartupSuccessNotification(ctx context.Context, cfg config.Config, configPath string, listenerAddr string, startedAt, readyAt time.Time) {
 	userIDs := uniqueNonEmpty(cfg.Auth.AllowedUserIDs)
-	if len(userIDs) == 0 {
-		return
-	}
 
 	httpClient := &http.Client{
 		Timeout: cfg.Server.HTTPClientTimeout.ToDuration(),
@@ -122,18 +174,33 @@ func sendStartupSuccessNotification(ctx context.Context, cfg config.Config, conf
 		AgentID:    cfg.WeCom.AgentID,
 		Secret:     cfg.WeCom.Secret,
 	}, httpClient)
+	toParty, toTag := cfg.Auth.ToParty(), cfg.Auth.ToTag()
+	notifier := app.NotifierFor(cfg.WeCom, wecomClient, httpClient, core.NotifyCategoryStartup)
+	if len(userIDs) == 0 && toParty == "" && toTag == "" && notifier == nil {
+		return
+	}
 
 	content := buildStartupSuccessMessage(cfg, configPath, listenerAddr, startedAt, readyAt)
-	toUser := strings.Join(userIDs, "|")
 
-	if err := wecomClient.SendText(ctx, wecom.TextMessage{
-		ToUser:  toUser,
-		Content: content,
-	}); err != nil {
-		slog.Error("启动成功通知发送失败", "error", err, "users_count", len(userIDs))
-		return
+	if len(userIDs) > 0 || toParty != "" || toTag != "" {
+		if err := wecomClient.SendText(ctx, wecom.TextMessage{
+			ToUser:  strings.Join(userIDs, "|"),
+			ToParty: toParty,
+			ToTag:   toTag,
+			Content: content,
+		}); err != nil {
+			slog.Error("启动成功通知发送失败", "error", err, "users_count", len(userIDs), "to_party", toParty, "to_tag", toTag)
+		} else {
+			slog.Info("启动成功通知已发送", "users_count", len(userIDs), "to_party", toParty, "to_tag", toTag)
+		}
+	}
+	if notifier != nil {
+		if err := notifier.Notify(ctx, content); err != nil {
+			slog.Error("启动成功通知群推送失败", "error", err)
+		} else {
+			slog.Info("启动成功通知已推送到群")
+		}
 	}
-	slog.Info("启动成功通知已发送", "users_count", len(userIDs))
 }
 
 func buildStartupSuccessMessage(cfg config.Config, configPath string, listenerAddr string, startedAt, readyAt time.Time) string {
@@ -169,9 +236,8 @@ func buildStartupSuccessMessage(cfg config.Config, configPath string, listenerAd
 		b.WriteString("\nBaseURL: <未配置>\n")
 	}
 
-	unraidEnabled := strings.TrimSpace(cfg.Unraid.Endpoint) != "" && strings.TrimSpace(cfg.Unraid.APIKey) != ""
 	b.WriteString("\n后端:\n")
-	if unraidEnabled {
+	if cfg.ServiceEnabled("unraid") {
 		b.WriteString("- Unraid: 已启用\n")
 	} else {
 		b.WriteString("- Unraid: 未启用\n")
@@ -284,3 +350,38 @@ func uniqueNonEmpty(ss []string) []string {
 	}
 	return out
 }
+
+// syncWeComMenu 对比应用当前菜单（menu/get）并输出差异，有变化时覆盖（menu/create）。
+func syncWeComMenu(ctx context.Context, cfg config.Config, wecomApp config.WeComAppConfig) error {
+	wecomClient := wecom.NewClient(wecom.ClientConfig{
+		APIBaseURL: cfg.WeCom.APIBaseURL,
+		CorpID:     wecomApp.CorpID,
+		AgentID:    wecomApp.AgentID,
+		Secret:     wecomApp.Secret,
+	}, &http.Client{Timeout: cfg.Server.HTTPClientTimeout.ToDuration()})
+
+	menu, err := app.MenuFor(cfg, wecomApp)
+	if err != nil {
+		return err
+	}
+	current, err := wecomClient.GetMenu(ctx)
+	if err != nil {
+		slog.Warn("获取当前自定义菜单失败，跳过差异对比", "app", wecomApp.Name, "error", err)
+	} else {
+		diff := wecom.DiffMenu(current, menu)
+		if len(diff) == 0 {
+			slog.Info("企业微信自定义菜单无变化，跳过同步", "app", wecomApp.Name, "agentid", wecomApp.AgentID)
+			return nil
+		}
+		fmt.Printf("自定义菜单变更（agentid=%d，- 移除，+ 新增）：\n", wecomApp.AgentID)
+		for _, line := range diff {
+			fmt.Println(line)
+		}
+	}
+
+	if err := wecomClient.CreateMenu(ctx, menu); err != nil {
+		return err
+	}
+	slog.Info("企业微信自定义菜单同步成功", "app", wecomApp.Name, "agentid", wecomApp.AgentID, "top_buttons", len(menu.Buttons))
+	return nil
+}
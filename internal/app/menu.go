@@ -0,0 +1,69 @@
+package app
+
+// menu.go 生成应用自定义菜单：wecom.menu 覆盖优先，否则按配置启用的服务组装。
+import (
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/config"
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/pve"
+	"github.com/zcw199604/wecom-home-ops/internal/qinglong"
+	"github.com/zcw199604/wecom-home-ops/internal/unraid"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// serviceMenus 为各服务的静态菜单描述（key 与 config.ServiceKeys 一致）。
+var serviceMenus = map[string]func() core.MenuService{
+	"unraid":   unraid.MenuService,
+	"qinglong": qinglong.MenuService,
+	"pve":      pve.MenuService,
+}
+
+// MenuFor 返回 --wecom-sync-menu 为某个应用使用的菜单（已校验），与运行时“同步菜单”命令一致。
+// 服务启用判断与 NewServer 相同（config.ServiceEnabled），仅使用静态菜单描述，不创建 Provider 与后台任务。
+func MenuFor(cfg config.Config, wecomApp config.WeComAppConfig) (wecom.Menu, error) {
+	if len(wecomApp.Menu) > 0 {
+		return menuOverride(wecomApp.Menu)
+	}
+	var services []core.MenuService
+	for _, key := range config.ServiceKeys {
+		if cfg.ServiceEnabled(key) && wecomApp.HasProvider(key) {
+			services = append(services, serviceMenus[key]())
+		}
+	}
+	menu := core.BuildMenu(services)
+	return menu, menu.Validate()
+}
+
+// menuOverride 将配置覆盖的菜单转换为 wecom.Menu，并按企业微信限制校验（wecom.Menu.Validate 为唯一校验来源）。
+// 未配置时返回空菜单。
+func menuOverride(buttons []config.WeComMenuButtonConfig) (wecom.Menu, error) {
+	var menu wecom.Menu
+	if len(buttons) == 0 {
+		return menu, nil
+	}
+	for _, b := range buttons {
+		menu.Buttons = append(menu.Buttons, menuButton(b))
+	}
+	if err := menu.Validate(); err != nil {
+		return wecom.Menu{}, err
+	}
+	return menu, nil
+}
+
+func menuButton(b config.WeComMenuButtonConfig) wecom.MenuButton {
+	out := wecom.MenuButton{Name: strings.TrimSpace(b.Name)}
+	if len(b.SubButtons) > 0 {
+		for _, sub := range b.SubButtons {
+			out.SubButtons = append(out.SubButtons, menuButton(sub))
+		}
+		return out
+	}
+	out.Type = strings.ToLower(strings.TrimSpace(b.Type))
+	if out.Type == "" {
+		out.Type = "click"
+	}
+	out.Key = strings.TrimSpace(b.Key)
+	out.URL = strings.TrimSpace(b.URL)
+	return out
+}
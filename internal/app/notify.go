@@ -0,0 +1,56 @@
+package app
+
+// notify.go 按通知类别组装广播出口：应用群聊（appchat）与群机器人（webhook）。
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/zcw199604/wecom-home-ops/internal/config"
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// NotifierFor 返回通知类别对应的广播出口；未配置任何群聊/机器人时返回 nil。
+func NotifierFor(cfg config.WeComConfig, appChat core.AppChatSender, httpClient *http.Client, category string) core.Notifier {
+	var out core.MultiNotifier
+	if chatID := cfg.AppChat.ChatFor(category); chatID != "" && appChat != nil {
+		out = append(out, core.AppChatNotifier{Sender: appChat, ChatID: chatID})
+	}
+	for _, robot := range cfg.Robots {
+		if !robot.Subscribes(category) {
+			continue
+		}
+		out = append(out, wecom.NewWebhookClient(wecom.WebhookConfig{
+			APIBaseURL: cfg.APIBaseURL,
+			Key:        robot.Key,
+		}, httpClient))
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	default:
+		return out
+	}
+}
+
+// alertRecipients 为某类告警的私聊对象（成员/部门/标签）。
+type alertRecipients struct {
+	UserIDs []string
+	ToParty string
+	ToTag   string
+}
+
+// alertRecipientsFor 返回某类告警的私聊对象：notify_users=false 且该类告警已有群聊/群机器人出口（broadcast 非 nil）时仅推送到群。
+func alertRecipientsFor(cfg config.Config, category string, broadcast core.Notifier) alertRecipients {
+	if broadcast != nil && !*cfg.WeCom.AppChat.NotifyUsers {
+		slog.Info("告警仅推送到群，不再私聊", "category", category)
+		return alertRecipients{}
+	}
+	return alertRecipients{
+		UserIDs: cfg.Auth.AllowedUserIDs,
+		ToParty: cfg.Auth.ToParty(),
+		ToTag:   cfg.Auth.ToTag(),
+	}
+}
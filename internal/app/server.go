@@ -19,11 +19,18 @@ import (
 )
 
 type Server struct {
-	cfg        config.Config
-	server     *http.Server
-	stateStore *core.StateStore
-	deduper    *wecom.Deduper
-	pveAlerts  *pve.AlertManager
+	cfg         config.Config
+	server      *http.Server
+	stateStores []*core.StateStore
+	dedupers    []*wecom.Deduper
+	pveAlerts   *pve.AlertManager
+
+	unraidAlerts *unraid.AlertManager
+	unraidUPS    *unraid.UPSWatcher
+	unraidStats  *unraid.StatsSampler
+
+	outbox    *wecom.Outbox
+	directory *wecom.Directory
 }
 
 func NewServer(cfg config.Config) (*Server, error) {
@@ -43,12 +50,47 @@ func NewServer(cfg config.Config) (*Server, error) {
 		Base:  wecomClient,
 		State: stateStore,
 		Mode:  core.TemplateCardMode(cfg.WeCom.TemplateCardMode),
+
+		MarkdownMode: core.MarkdownMode(cfg.WeCom.MarkdownMode),
 	})
-	deduper := wecom.NewDeduper(10 * time.Minute)
 
-	var providers []core.ServiceProvider
+	// 告警经出站队列发送（限流/合并/重试），避免告警风暴触发企业微信频率限制。
+	var alertSender core.WeComSender = wecomClient
+	var outbox *wecom.Outbox
+	if *cfg.WeCom.Outbox.Enabled {
+		outbox = wecom.NewOutbox(wecomClient, outboxConfig(cfg.WeCom.Outbox))
+		outbox.Start()
+		alertSender = outbox
+	}
+	notifier := func(category string) core.Notifier {
+		return NotifierFor(cfg.WeCom, wecomClient, httpClient, category)
+	}
+
+	// Provider 按应用分别构建（各自的发送端与会话状态），后端客户端与告警任务全局共享。
+	var newUnraidProvider, newQinglongProvider, newPVEProvider func(core.WeComSender, *core.StateStore) core.ServiceProvider
 
-	if cfg.Unraid.Endpoint != "" && cfg.Unraid.APIKey != "" {
+	// PVE 实例提前构建：Unraid 关机预案可引用 PVE 虚拟机。
+	var pveInstances []pve.Instance
+	for _, ins := range cfg.PVE.Instances {
+		client, err := pve.NewClient(pve.ClientConfig{
+			BaseURL:            ins.BaseURL,
+			APIToken:           ins.APIToken,
+			InsecureSkipVerify: ins.InsecureSkipVerify,
+		}, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		pveInstances = append(pveInstances, pve.Instance{
+			ID:     ins.ID,
+			Name:   ins.Name,
+			Client: client,
+		})
+	}
+
+	var unraidAlerts *unraid.AlertManager
+	var unraidUPS *unraid.UPSWatcher
+	var unraidStats *unraid.StatsSampler
+	if cfg.ServiceEnabled("unraid") {
 		unraidClient := unraid.NewClient(unraid.ClientConfig{
 			Endpoint: cfg.Unraid.Endpoint,
 			APIKey:   cfg.Unraid.APIKey,
@@ -58,6 +100,9 @@ func NewServer(cfg config.Config) (*Server, error) {
 			WebGUIEventsURL:  cfg.Unraid.WebGUIEventsURL,
 			WebGUICSRFToken:  cfg.Unraid.WebGUICSRFToken,
 			WebGUICookie:     cfg.Unraid.WebGUICookie,
+			WebGUIUsername:   cfg.Unraid.WebGUIUsername,
+			WebGUIPassword:   cfg.Unraid.WebGUIPassword,
+			WebGUILoginURL:   cfg.Unraid.WebGUILoginURL,
 
 			LogsField:        cfg.Unraid.LogsField,
 			LogsTailArg:      cfg.Unraid.LogsTailArg,
@@ -71,14 +116,68 @@ func NewServer(cfg config.Config) (*Server, error) {
 			ForceUpdateArgType:      cfg.Unraid.ForceUpdateArgType,
 			ForceUpdateReturnFields: cfg.Unraid.ForceUpdateReturnFields,
 		}, httpClient)
-		providers = append(providers, unraid.NewProvider(unraid.ProviderDeps{
-			WeCom:  wecomSender,
-			Client: unraidClient,
-			State:  stateStore,
-		}))
+		unraidBroadcast := notifier(core.NotifyCategoryUnraidAlert)
+		unraidRecipients := alertRecipientsFor(cfg, core.NotifyCategoryUnraidAlert, unraidBroadcast)
+		unraidAlerts = unraid.NewAlertManager(unraid.AlertManagerDeps{
+			WeCom:     alertSender,
+			UserIDs:   unraidRecipients.UserIDs,
+			ToParty:   unraidRecipients.ToParty,
+			ToTag:     unraidRecipients.ToTag,
+			Client:    unraidClient,
+			Config:    unraidAlertConfig(cfg.Unraid.Alert),
+			Broadcast: unraidBroadcast,
+		})
+		unraidAlerts.Start()
+
+		shutdownPlan := unraidShutdownPlan(cfg.Unraid.UPS.ShutdownPlan, pveInstances)
+		// UPS 告警可能附带“关机预案”按钮，使用 wecomSender 以支持模板卡片文本兜底。
+		unraidUPS = unraid.NewUPSWatcher(unraid.UPSWatcherDeps{
+			WeCom:   wecomSender,
+			UserIDs: cfg.Auth.AllowedUserIDs,
+			ToParty: cfg.Auth.ToParty(),
+			ToTag:   cfg.Auth.ToTag(),
+			Client:  unraidClient,
+			Config: unraid.UPSConfig{
+				Enabled:          cfg.Unraid.UPS.Enabled,
+				Interval:         cfg.Unraid.UPS.Interval.ToDuration(),
+				ChargeThreshold:  cfg.Unraid.UPS.ChargeThreshold,
+				RuntimeThreshold: cfg.Unraid.UPS.RuntimeThreshold.ToDuration(),
+			},
+			ShutdownPlanEnabled: !shutdownPlan.Empty(),
+			Broadcast:           notifier(core.NotifyCategoryUnraidUPS),
+		})
+		unraidUPS.Start()
+
+		unraidStats = unraid.NewStatsSampler(unraidClient, unraid.SamplerConfig{
+			Enabled:     cfg.Unraid.StatsSampler.Enabled,
+			Interval:    cfg.Unraid.StatsSampler.Interval.ToDuration(),
+			Retention:   cfg.Unraid.StatsSampler.Retention.ToDuration(),
+			PersistPath: cfg.Unraid.StatsSampler.PersistPath,
+		})
+		unraidStats.Start()
+
+		newUnraidProvider = func(sender core.WeComSender, state *core.StateStore) core.ServiceProvider {
+			return unraid.NewProvider(unraid.ProviderDeps{
+				WeCom:  sender,
+				Client: unraidClient,
+				State:  state,
+
+				UpdateAllStopOnFailure: cfg.Unraid.UpdateAllStopOnFailure,
+				Groups:                 unraidGroups(cfg.Unraid.Groups),
+				ShutdownPlan:           shutdownPlan,
+				Alerts:                 unraidAlerts,
+				FlashBackup: unraid.FlashBackupConfig{
+					RemoteName:      cfg.Unraid.FlashBackup.RemoteName,
+					SourcePath:      cfg.Unraid.FlashBackup.SourcePath,
+					DestinationPath: cfg.Unraid.FlashBackup.DestinationPath,
+				},
+				Sampler:     unraidStats,
+				LogDelivery: longTextMode(cfg.Unraid.LogDelivery),
+			})
+		}
 	}
 
-	if len(cfg.Qinglong.Instances) > 0 {
+	if cfg.ServiceEnabled("qinglong") {
 		var instances []qinglong.Instance
 		for _, ins := range cfg.Qinglong.Instances {
 			client, err := qinglong.NewClient(qinglong.ClientConfig{
@@ -95,32 +194,19 @@ func NewServer(cfg config.Config) (*Server, error) {
 				Client: client,
 			})
 		}
-		providers = append(providers, qinglong.NewProvider(qinglong.ProviderDeps{
-			WeCom:     wecomSender,
-			State:     stateStore,
-			Instances: instances,
-		}))
-	}
-
-	var pveAlerts *pve.AlertManager
-	if len(cfg.PVE.Instances) > 0 {
-		var instances []pve.Instance
-		for _, ins := range cfg.PVE.Instances {
-			client, err := pve.NewClient(pve.ClientConfig{
-				BaseURL:            ins.BaseURL,
-				APIToken:           ins.APIToken,
-				InsecureSkipVerify: ins.InsecureSkipVerify,
-			}, httpClient)
-			if err != nil {
-				return nil, err
-			}
-			instances = append(instances, pve.Instance{
-				ID:     ins.ID,
-				Name:   ins.Name,
-				Client: client,
+		newQinglongProvider = func(sender core.WeComSender, state *core.StateStore) core.ServiceProvider {
+			return qinglong.NewProvider(qinglong.ProviderDeps{
+				WeCom:        sender,
+				State:        state,
+				Instances:    instances,
+				AdminUserIDs: cfg.Qinglong.AdminUserIDs,
+				LogDelivery:  longTextMode(cfg.Qinglong.LogDelivery),
 			})
 		}
+	}
 
+	var pveAlerts *pve.AlertManager
+	if cfg.ServiceEnabled("pve") {
 		enabled := cfg.PVE.Alert.Enabled != nil && *cfg.PVE.Alert.Enabled
 		alertCfg := pve.AlertConfig{
 			Enabled: enabled,
@@ -134,40 +220,35 @@ func NewServer(cfg config.Config) (*Server, error) {
 			StorageUsageThreshold: cfg.PVE.Alert.StorageUsageThreshold,
 		}
 
+		pveBroadcast := notifier(core.NotifyCategoryPVEAlert)
+		pveRecipients := alertRecipientsFor(cfg, core.NotifyCategoryPVEAlert, pveBroadcast)
 		pveAlerts = pve.NewAlertManager(pve.AlertManagerDeps{
-			WeCom:     wecomClient,
-			UserIDs:   cfg.Auth.AllowedUserIDs,
-			Instances: instances,
+			WeCom:     alertSender,
+			UserIDs:   pveRecipients.UserIDs,
+			ToParty:   pveRecipients.ToParty,
+			ToTag:     pveRecipients.ToTag,
+			Instances: pveInstances,
 			Config:    alertCfg,
+			Broadcast: pveBroadcast,
 		})
 		pveAlerts.Start()
 
-		providers = append(providers, pve.NewProvider(pve.ProviderDeps{
-			WeCom:       wecomSender,
-			State:       stateStore,
-			Instances:   instances,
-			AlertConfig: alertCfg,
-			Alerts:      pveAlerts,
-		}))
-	}
-
-	router := core.NewRouter(core.RouterDeps{
-		WeCom:         wecomSender,
-		AllowedUserID: make(map[string]struct{}),
-		Providers:     providers,
-		State:         stateStore,
-	})
-	for _, id := range cfg.Auth.AllowedUserIDs {
-		router.AllowedUserID[id] = struct{}{}
+		newPVEProvider = func(sender core.WeComSender, state *core.StateStore) core.ServiceProvider {
+			return pve.NewProvider(pve.ProviderDeps{
+				WeCom:       sender,
+				State:       state,
+				Instances:   pveInstances,
+				AlertConfig: alertCfg,
+				Alerts:      pveAlerts,
+			})
+		}
 	}
 
-	crypto, err := wecom.NewCrypto(wecom.CryptoConfig{
-		Token:          cfg.WeCom.Token,
-		EncodingAESKey: cfg.WeCom.EncodingAESKey,
-		ReceiverID:     cfg.WeCom.CorpID,
-	})
-	if err != nil {
-		return nil, err
+	// 按 config.ServiceKeys 注册，与 MenuFor 的服务顺序一致；未启用的服务无工厂。
+	providerFactories := map[string]func(core.WeComSender, *core.StateStore) core.ServiceProvider{
+		"unraid":   newUnraidProvider,
+		"qinglong": newQinglongProvider,
+		"pve":      newPVEProvider,
 	}
 
 	mux := http.NewServeMux()
@@ -181,12 +262,99 @@ func NewServer(cfg config.Config) (*Server, error) {
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	mux.Handle("GET /wecom/callback", wecom.NewCallbackVerifyHandler(crypto))
-	mux.Handle("POST /wecom/callback", wecom.NewCallbackHandler(wecom.CallbackDeps{
-		Crypto:  crypto,
-		Core:    router,
-		Deduper: deduper,
-	}))
+	// 部门/标签授权：经主应用通讯录接口解析成员，定时刷新。
+	var directory *wecom.Directory
+	if cfg.Auth.HasDirectory() {
+		directory = wecom.NewDirectory(wecomClient, wecom.DirectoryConfig{
+			DepartmentIDs: cfg.Auth.AllowedPartyIDs,
+			TagIDs:        cfg.Auth.AllowedTagIDs,
+			Refresh:       cfg.Auth.DirectoryRefresh.ToDuration(),
+		})
+		directory.Start()
+	}
+
+	var stateStores []*core.StateStore
+	var dedupers []*wecom.Deduper
+	for i, wecomApp := range cfg.WeComApps() {
+		// 主应用复用上方的客户端与发送端（UPS 告警按钮依赖其会话状态）。
+		appClient, appState, appSender := wecomClient, stateStore, wecomSender
+		if i > 0 {
+			appClient = wecom.NewClient(wecom.ClientConfig{
+				APIBaseURL: cfg.WeCom.APIBaseURL,
+				CorpID:     wecomApp.CorpID,
+				AgentID:    wecomApp.AgentID,
+				Secret:     wecomApp.Secret,
+			}, httpClient)
+			appState = core.NewStateStore(cfg.Core.StateTTL.ToDuration())
+			appSender = core.NewTemplateCardSender(core.TemplateCardSenderDeps{
+				Base:  appClient,
+				State: appState,
+				Mode:  core.TemplateCardMode(cfg.WeCom.TemplateCardMode),
+
+				MarkdownMode: core.MarkdownMode(cfg.WeCom.MarkdownMode),
+			})
+		}
+		stateStores = append(stateStores, appState)
+
+		var providers []core.ServiceProvider
+		var providerKeys []string
+		for _, key := range config.ServiceKeys {
+			newProvider := providerFactories[key]
+			if newProvider == nil || !wecomApp.HasProvider(key) {
+				continue
+			}
+			providers = append(providers, newProvider(appSender, appState))
+			providerKeys = append(providerKeys, key)
+		}
+
+		menu, err := menuOverride(wecomApp.Menu)
+		if err != nil {
+			return nil, fmt.Errorf("wecom app %q menu: %w", wecomApp.Name, err)
+		}
+		var members core.MemberChecker
+		if wecomApp.UseAuthDirectory && directory != nil {
+			members = directory
+		}
+		router := core.NewRouter(core.RouterDeps{
+			WeCom:         appSender,
+			AllowedUserID: make(map[string]struct{}),
+			Providers:     providers,
+			State:         appState,
+			Announcer:     notifier(core.NotifyCategoryAnnounce),
+			Menu:          menu,
+			Members:       members,
+		})
+		for _, id := range wecomApp.AllowedUserIDs {
+			router.AllowedUserID[id] = struct{}{}
+		}
+
+		crypto, err := wecom.NewCrypto(wecom.CryptoConfig{
+			Token:          wecomApp.Token,
+			EncodingAESKey: wecomApp.EncodingAESKey,
+			ReceiverID:     wecomApp.CorpID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("wecom app %q: %w", wecomApp.Name, err)
+		}
+		deduper := wecom.NewDeduper(10 * time.Minute)
+		dedupers = append(dedupers, deduper)
+
+		path := wecomApp.CallbackPath()
+		mux.Handle("GET "+path, wecom.NewCallbackVerifyHandler(crypto))
+		mux.Handle("POST "+path, wecom.NewCallbackHandler(wecom.CallbackDeps{
+			Crypto:  crypto,
+			Core:    router,
+			Deduper: deduper,
+			Replay:  wecom.NewReplayGuard(cfg.WeCom.CallbackMaxSkew.ToDuration()),
+		}))
+		slog.Info("企业微信应用已装配",
+			"app", wecomApp.Name,
+			"agentid", wecomApp.AgentID,
+			"callback_path", path,
+			"providers", providerKeys,
+			"allowed_userids_count", len(wecomApp.AllowedUserIDs),
+		)
+	}
 
 	s := &http.Server{
 		Addr:              cfg.Server.ListenAddr,
@@ -195,11 +363,18 @@ func NewServer(cfg config.Config) (*Server, error) {
 	}
 
 	return &Server{
-		cfg:        cfg,
-		server:     s,
-		stateStore: stateStore,
-		deduper:    deduper,
-		pveAlerts:  pveAlerts,
+		cfg:         cfg,
+		server:      s,
+		stateStores: stateStores,
+		dedupers:    dedupers,
+		pveAlerts:   pveAlerts,
+
+		unraidAlerts: unraidAlerts,
+		unraidUPS:    unraidUPS,
+		unraidStats:  unraidStats,
+
+		outbox:    outbox,
+		directory: directory,
 	}, nil
 }
 
@@ -229,18 +404,120 @@ func (s *Server) Serve(listener net.Listener) error {
 func (s *Server) Shutdown(ctx context.Context) error {
 	slog.Info("HTTP 服务关闭中")
 	err := s.server.Shutdown(ctx)
-	if s.stateStore != nil {
-		s.stateStore.Close()
+	for _, st := range s.stateStores {
+		st.Close()
 	}
-	if s.deduper != nil {
-		s.deduper.Close()
+	for _, d := range s.dedupers {
+		d.Close()
 	}
 	if s.pveAlerts != nil {
 		s.pveAlerts.Close()
 	}
+	if s.unraidAlerts != nil {
+		s.unraidAlerts.Close()
+	}
+	if s.unraidUPS != nil {
+		s.unraidUPS.Close()
+	}
+	if s.unraidStats != nil {
+		s.unraidStats.Close()
+	}
+	if s.outbox != nil {
+		s.outbox.Close()
+	}
+	if s.directory != nil {
+		s.directory.Close()
+	}
 	return err
 }
 
+// outboxConfig 将出站队列配置转换为 wecom.OutboxConfig（零值由 wecom 包补默认）。
+func outboxConfig(cfg config.WeComOutboxConfig) wecom.OutboxConfig {
+	out := wecom.OutboxConfig{
+		PerUserPerMinute: cfg.PerUserPerMinute,
+		GlobalPerMinute:  cfg.GlobalPerMinute,
+		MaxPending:       cfg.MaxPending,
+		SpoolPath:        cfg.SpoolPath,
+	}
+	for _, d := range cfg.RetrySchedule {
+		out.RetrySchedule = append(out.RetrySchedule, d.ToDuration())
+	}
+	return out
+}
+
+// unraidShutdownPlan 将关机预案配置转换为 unraid.ShutdownPlan（PVE 虚拟机关机作为外部步骤注入）。
+func unraidShutdownPlan(cfg config.UnraidShutdownPlanConfig, pveInstances []pve.Instance) unraid.ShutdownPlan {
+	plan := unraid.ShutdownPlan{Groups: cfg.Groups}
+	for _, g := range cfg.PVEGuests {
+		for _, ins := range pveInstances {
+			if ins.ID != g.Instance {
+				continue
+			}
+			client, vmid := ins.Client, g.VMID
+			name := ins.Name
+			if name == "" {
+				name = ins.ID
+			}
+			plan.Steps = append(plan.Steps, unraid.ShutdownStep{
+				Name: fmt.Sprintf("关闭 PVE %s 虚拟机 %d", name, vmid),
+				Run: func(ctx context.Context) error {
+					_, err := client.ShutdownGuestByVMID(ctx, vmid)
+					return err
+				},
+			})
+		}
+	}
+	return plan
+}
+
+// longTextMode 将配置值映射为 wecom.LongTextMode（配置已校验，未知值按截断处理）。
+func longTextMode(s string) wecom.LongTextMode {
+	mode, ok := wecom.ParseLongTextMode(s)
+	if !ok {
+		return wecom.LongTextModeTruncate
+	}
+	return mode
+}
+
+func unraidGroups(groups []config.UnraidContainerGroup) []unraid.ContainerGroup {
+	out := make([]unraid.ContainerGroup, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, unraid.ContainerGroup{
+			ID:         g.ID,
+			Name:       g.Name,
+			Containers: g.Containers,
+			Pattern:    g.Pattern,
+			Label:      g.Label,
+			Delay:      g.Delay.ToDuration(),
+		})
+	}
+	return out
+}
+
+func unraidAlertConfig(c config.UnraidAlertConfig) unraid.AlertConfig {
+	rules := make([]unraid.StorageAlertRule, 0, len(c.StorageRules))
+	for _, r := range c.StorageRules {
+		rules = append(rules, unraid.StorageAlertRule{Name: r.Name, Threshold: r.Threshold})
+	}
+	return unraid.AlertConfig{
+		Enabled: c.Enabled,
+
+		Interval: c.Interval.ToDuration(),
+		Cooldown: c.Cooldown.ToDuration(),
+		MuteFor:  c.MuteFor.ToDuration(),
+
+		CPUUsageThreshold:     c.CPUUsageThreshold,
+		MemUsageThreshold:     c.MemUsageThreshold,
+		UPSLoadThreshold:      c.UPSLoadThreshold,
+		ContainerCPUThreshold: c.ContainerCPUThreshold,
+		ContainerMemThreshold: c.ContainerMemThreshold,
+
+		ShareUsageThreshold: c.ShareUsageThreshold,
+		PoolUsageThreshold:  c.PoolUsageThreshold,
+		StorageRules:        rules,
+	}
+}
+
 func withRequestLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
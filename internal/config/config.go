@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -101,6 +102,214 @@ type WeComConfig struct {
 	// - 文本通知/图文展示/按钮交互型：企业微信 3.1.6+ 支持
 	// - 微工作台（原企业号）不支持展示模板卡片消息
 	TemplateCardMode string `yaml:"template_card_mode"`
+
+	// MarkdownMode 控制 markdown 消息（状态概览等）的发送方式：
+	// - markdown：发送 markdown 消息（仅企业微信客户端可渲染）
+	// - text：降级为纯文本（微信插件/微工作台用户）
+	// 未配置时跟随 template_card_mode：text 模式下同样降级为纯文本，否则发送 markdown。
+	MarkdownMode string `yaml:"markdown_mode"`
+
+	// AppChat 为应用群聊投递：告警与操作播报推送到共享的运维群。
+	AppChat WeComAppChatConfig `yaml:"appchat"`
+
+	// Robots 为群机器人（webhook）投递：适用于只有机器人、无法由应用创建的既有群。
+	Robots []WeComRobotConfig `yaml:"robots"`
+
+	// Outbox 为告警出站队列：限流、合并相同消息、失败重试与可选落盘。
+	Outbox WeComOutboxConfig `yaml:"outbox"`
+
+	// Menu 覆盖应用自定义菜单；留空时按已启用的服务自动生成（“同步菜单”与 --wecom-sync-menu 使用）。
+	// 按钮数量/名称长度等限制由 wecom.Menu.Validate 在装配时统一校验。
+	Menu []WeComMenuButtonConfig `yaml:"menu"`
+
+	// CallbackMaxSkew 为回调 timestamp 与本机时间允许的最大偏差（默认 5m）；超出或 nonce 重复的请求视为重放并拒绝。
+	CallbackMaxSkew Duration `yaml:"callback_max_skew"`
+
+	// Apps 为额外的自建应用：各自独立的凭据与回调路径（/wecom/callback/{name}），
+	// 可绑定部分服务与成员。上方字段为主应用（/wecom/callback），告警/群聊/启动通知仍经主应用发送。
+	Apps []WeComAppConfig `yaml:"apps"`
+}
+
+type WeComAppConfig struct {
+	// Name 为应用标识，用于回调路径 /wecom/callback/{name} 与日志。
+	Name string `yaml:"name"`
+	// CorpID 留空时沿用 wecom.corpid（同一企业下的多个应用）。
+	CorpID         string `yaml:"corpid"`
+	AgentID        int    `yaml:"agentid"`
+	Secret         string `yaml:"secret"`
+	Token          string `yaml:"token"`
+	EncodingAESKey string `yaml:"encoding_aes_key"`
+
+	// Providers 为该应用可用的服务（unraid/qinglong/pve）；留空表示全部已启用的服务。
+	Providers []string `yaml:"providers"`
+	// AllowedUserIDs 为该应用的成员白名单；留空时沿用 auth 配置（含部门/标签授权）。
+	AllowedUserIDs []string `yaml:"allowed_userids"`
+	// UseAuthDirectory 表示该应用沿用 auth 的部门/标签授权（由 WeComApps 填充）。
+	UseAuthDirectory bool `yaml:"-"`
+	// Menu 覆盖该应用的自定义菜单；留空时按绑定的服务自动生成。
+	Menu []WeComMenuButtonConfig `yaml:"menu"`
+}
+
+// CallbackPath 返回应用的回调路径：主应用为 /wecom/callback，其余为 /wecom/callback/{name}。
+func (a WeComAppConfig) CallbackPath() string {
+	if a.Name == "" {
+		return "/wecom/callback"
+	}
+	return "/wecom/callback/" + a.Name
+}
+
+// HasProvider 判断应用是否绑定服务；Providers 为空表示全部服务。
+func (a WeComAppConfig) HasProvider(key string) bool {
+	if len(a.Providers) == 0 {
+		return true
+	}
+	for _, p := range a.Providers {
+		if strings.TrimSpace(p) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceKeys 为后端服务的注册顺序（Provider、菜单分组与 wecom.apps[].providers 均使用这些 key）。
+var ServiceKeys = []string{"unraid", "qinglong", "pve"}
+
+// ServiceEnabled 判断后端服务是否已配置；未知 key 返回 false。
+func (c Config) ServiceEnabled(key string) bool {
+	switch key {
+	case "unraid":
+		return strings.TrimSpace(c.Unraid.Endpoint) != "" && strings.TrimSpace(c.Unraid.APIKey) != ""
+	case "qinglong":
+		return len(c.Qinglong.Instances) > 0
+	case "pve":
+		return len(c.PVE.Instances) > 0
+	default:
+		return false
+	}
+}
+
+// WeComApps 返回全部自建应用：首个为主应用（Name 为空、绑定全部服务），其后为 wecom.apps；
+// 留空的 corpid/allowed_userids 已按主应用与 auth 配置补齐。
+func (c Config) WeComApps() []WeComAppConfig {
+	apps := []WeComAppConfig{{
+		CorpID:         c.WeCom.CorpID,
+		AgentID:        c.WeCom.AgentID,
+		Secret:         c.WeCom.Secret,
+		Token:          c.WeCom.Token,
+		EncodingAESKey: c.WeCom.EncodingAESKey,
+		AllowedUserIDs: c.Auth.AllowedUserIDs,
+		Menu:           c.WeCom.Menu,
+
+		UseAuthDirectory: c.Auth.HasDirectory(),
+	}}
+	for _, a := range c.WeCom.Apps {
+		a.Name = strings.TrimSpace(a.Name)
+		if strings.TrimSpace(a.CorpID) == "" {
+			a.CorpID = c.WeCom.CorpID
+		}
+		if len(a.AllowedUserIDs) == 0 {
+			a.AllowedUserIDs = c.Auth.AllowedUserIDs
+			a.UseAuthDirectory = c.Auth.HasDirectory()
+		}
+		apps = append(apps, a)
+	}
+	return apps
+}
+
+// WeComMenuButtonConfig 为自定义菜单按钮：一级最多 3 个，每个最多 5 个子按钮。
+type WeComMenuButtonConfig struct {
+	Name string `yaml:"name"`
+	// Type 为 click（默认，需 key）或 view（需 url）；含 sub_buttons 时忽略。
+	Type string `yaml:"type"`
+	Key  string `yaml:"key"`
+	URL  string `yaml:"url"`
+
+	SubButtons []WeComMenuButtonConfig `yaml:"sub_buttons"`
+}
+
+type WeComOutboxConfig struct {
+	// Enabled 控制告警是否经出站队列发送（默认 true）；关闭后告警直接调用 message/send。
+	Enabled *bool `yaml:"enabled"`
+	// PerUserPerMinute 为单个成员每分钟最多发送条数（默认 20，官方上限 30）。
+	PerUserPerMinute int `yaml:"per_user_per_minute"`
+	// GlobalPerMinute 为全部成员合计每分钟最多发送条数（默认 600）。
+	GlobalPerMinute int `yaml:"global_per_minute"`
+	// RetrySchedule 为发送失败（频率超限/系统繁忙/网络错误）后的重试间隔（默认 30s、2m、10m）。
+	RetrySchedule []Duration `yaml:"retry_schedule"`
+	// MaxPending 为待发送消息上限（默认 500）。
+	MaxPending int `yaml:"max_pending"`
+	// SpoolPath 非空时将未发送消息落盘，重启后继续投递。
+	SpoolPath string `yaml:"spool_path"`
+}
+
+type WeComRobotConfig struct {
+	// Name 为机器人标识（仅用于日志与校验提示）。
+	Name string `yaml:"name"`
+	// Key 为 webhook 地址中的 key（https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=...）。
+	Key string `yaml:"key"`
+	// Categories 为订阅的通知类别（同 appchat.chats，另支持 startup）；订阅 alert 即接收全部告警类别。
+	Categories []string `yaml:"categories"`
+}
+
+// Subscribes 判断机器人是否订阅通知类别；alert 覆盖全部告警类别（不含 announce/startup）。
+func (r WeComRobotConfig) Subscribes(category string) bool {
+	for _, c := range r.Categories {
+		c = strings.TrimSpace(c)
+		if c == category || (c == "alert" && isAlertCategory(category)) {
+			return true
+		}
+	}
+	return false
+}
+
+type WeComAppChatConfig struct {
+	// Chats 按通知类别配置群聊 chatid：
+	// alert（各类告警默认）、unraid_alert、unraid_ups、pve_alert、announce（操作播报）、startup（启动通知）。
+	Chats map[string]string `yaml:"chats"`
+
+	// NotifyUsers 控制某类告警已配置群聊（或订阅告警的群机器人）时是否仍私聊 auth 中的成员/部门/标签（默认 true）；按类别分别判断。
+	// UPS 告警始终私聊（“执行关机预案”按钮需在应用内交互）。
+	NotifyUsers *bool `yaml:"notify_users"`
+
+	// Create 为 --wecom-create-appchat 创建群聊时使用的参数。
+	Create WeComAppChatCreateConfig `yaml:"create"`
+}
+
+type WeComAppChatCreateConfig struct {
+	Name string `yaml:"name"`
+	// Owner 为群主 userid（默认 userlist 第一个成员）。
+	Owner string `yaml:"owner"`
+	// UserList 为群成员（至少 2 人，默认 auth.allowed_userids）。
+	UserList []string `yaml:"userlist"`
+	// ChatID 为指定的群聊 id（可选，便于写入 chats 配置）。
+	ChatID string `yaml:"chatid"`
+}
+
+// appChatCategories 为 wecom.appchat.chats 支持的通知类别。
+var appChatCategories = []string{"alert", "unraid_alert", "unraid_ups", "pve_alert", "announce", "startup"}
+
+// isAlertCategory 判断是否为告警类别（未单独配置时回退到 alert）。
+func isAlertCategory(category string) bool {
+	switch category {
+	case "announce", "startup":
+		return false
+	default:
+		return true
+	}
+}
+
+var appChatIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{1,32}$`)
+var weComAppNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ChatFor 返回通知类别对应的群聊 chatid；告警类未单独配置时回退到 alert。
+func (c WeComAppChatConfig) ChatFor(category string) string {
+	if id := strings.TrimSpace(c.Chats[category]); id != "" {
+		return id
+	}
+	if !isAlertCategory(category) {
+		return ""
+	}
+	return strings.TrimSpace(c.Chats["alert"])
 }
 
 type UnraidConfig struct {
@@ -116,6 +325,10 @@ type UnraidConfig struct {
 	WebGUIEventsURL  string `yaml:"webgui_events_url"`
 	WebGUICSRFToken  string `yaml:"webgui_csrf_token"`
 	WebGUICookie     string `yaml:"webgui_cookie"`
+	// 可选：配置 WebGUI 登录凭据后自动登录获取 Cookie/csrf_token，会话失效时自动重新登录（无需手动抓包）。
+	WebGUIUsername string `yaml:"webgui_username"`
+	WebGUIPassword string `yaml:"webgui_password"`
+	WebGUILoginURL string `yaml:"webgui_login_url"`
 
 	LogsField        string  `yaml:"logs_field"`
 	LogsTailArg      *string `yaml:"logs_tail_arg"`
@@ -127,10 +340,129 @@ type UnraidConfig struct {
 	ForceUpdateArgName      string   `yaml:"force_update_arg"`
 	ForceUpdateArgType      string   `yaml:"force_update_arg_type"`
 	ForceUpdateReturnFields []string `yaml:"force_update_return_fields"`
+
+	// UpdateAllStopOnFailure 控制“全部更新”遇到失败时是否停止（默认 false：失败跳过继续更新后续容器）。
+	UpdateAllStopOnFailure bool `yaml:"update_all_stop_on_failure"`
+
+	// LogDelivery 控制容器日志/系统日志超长时的下发方式：
+	// - truncate：截断为一条消息（默认，保留最新内容）
+	// - split：按行切分为多条带序号的消息（超过 5 条时改用文件）
+	// - file：上传为临时素材并发送文件消息（失败时降级为分条）
+	LogDelivery string `yaml:"log_delivery"`
+
+	// Groups 为容器组（整组按顺序批量启动/重启/停止/更新）。
+	Groups []UnraidContainerGroup `yaml:"groups"`
+
+	Alert UnraidAlertConfig `yaml:"alert"`
+	UPS   UnraidUPSConfig   `yaml:"ups"`
+
+	// FlashBackup 为闪存备份（initiateFlashBackup，基于 rclone 远端）参数。
+	FlashBackup UnraidFlashBackupConfig `yaml:"flash_backup"`
+
+	// StatsSampler 为容器资源采样（用于“资源排行/资源趋势”）。
+	StatsSampler UnraidStatsSamplerConfig `yaml:"stats_sampler"`
+}
+
+type UnraidStatsSamplerConfig struct {
+	// Enabled 为 true 时定期采集容器 CPU/内存/网络占用（默认关闭）。
+	Enabled bool `yaml:"enabled"`
+
+	// Interval 为采样间隔；Retention 为内存中保留的时长（超出后环形覆盖）。
+	Interval  Duration `yaml:"interval"`
+	Retention Duration `yaml:"retention"`
+	// PersistPath 为可选的落盘文件（JSON）；配置后每次采样写入，启动时加载，重启不丢失趋势。
+	PersistPath string `yaml:"persist_path"`
+}
+
+type UnraidFlashBackupConfig struct {
+	// RemoteName 为 Unraid 上已配置的 rclone 远端名称。
+	RemoteName string `yaml:"remote_name"`
+	// SourcePath 默认 /boot。
+	SourcePath      string `yaml:"source_path"`
+	DestinationPath string `yaml:"destination_path"`
+}
+
+type UnraidContainerGroup struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+
+	// Containers 为按顺序执行的容器名；Pattern（容器名正则）/Label（key 或 key=value）匹配到的容器按名称排序追加在后。
+	Containers []string `yaml:"containers"`
+	Pattern    string   `yaml:"pattern"`
+	Label      string   `yaml:"label"`
+
+	// Delay 为相邻两个容器操作之间的等待时间（例如 5s）。
+	Delay Duration `yaml:"delay"`
+}
+
+type UnraidAlertConfig struct {
+	// Enabled 为 true 时启用 Unraid 告警轮询（默认关闭）。
+	Enabled bool `yaml:"enabled"`
+
+	// Interval 为告警轮询间隔（建议 5m 左右）。
+	Interval Duration `yaml:"interval"`
+	// Cooldown 为同类告警的冷却时间（避免重复刷屏）。
+	Cooldown Duration `yaml:"cooldown"`
+	// MuteFor 为通过企业微信菜单“静默告警”时的持续时间。
+	MuteFor Duration `yaml:"mute_for"`
+
+	// 以下阈值均为百分比（1~100）：未配置（0）时使用各字段的默认值，负数表示不检查该项。
+
+	// CPUUsageThreshold/MemUsageThreshold 为主机 CPU/有效内存使用率阈值（默认 90）。
+	CPUUsageThreshold float64 `yaml:"cpu_usage_threshold"`
+	MemUsageThreshold float64 `yaml:"mem_usage_threshold"`
+	// UPSLoadThreshold 为 UPS 负载阈值（默认 80）。
+	UPSLoadThreshold float64 `yaml:"ups_load_threshold"`
+	// ContainerCPUThreshold/ContainerMemThreshold 为单容器 CPU/内存（相对限制）阈值（默认不检查）。
+	ContainerCPUThreshold float64 `yaml:"container_cpu_threshold"`
+	ContainerMemThreshold float64 `yaml:"container_mem_threshold"`
+
+	// ShareUsageThreshold/PoolUsageThreshold 为共享目录/缓存池使用率阈值（默认 90）。
+	ShareUsageThreshold float64 `yaml:"share_usage_threshold"`
+	PoolUsageThreshold  float64 `yaml:"pool_usage_threshold"`
+	// StorageRules 按名称覆盖单个共享目录/缓存池的阈值（负数表示不检查该项）。
+	StorageRules []UnraidStorageRule `yaml:"storage_rules"`
+}
+
+type UnraidUPSConfig struct {
+	// Enabled 为 true 时启用 UPS 电源监控（默认关闭）。
+	Enabled bool `yaml:"enabled"`
+
+	// Interval 为 UPS 状态轮询间隔（断电事件需及时感知，建议 30s）。
+	Interval Duration `yaml:"interval"`
+	// ChargeThreshold/RuntimeThreshold 为电池供电时的电量（百分比）/剩余续航告警阈值。
+	ChargeThreshold  float64  `yaml:"charge_threshold"`
+	RuntimeThreshold Duration `yaml:"runtime_threshold"`
+
+	// ShutdownPlan 为可选的关机预案；配置后断电告警附带“执行关机预案”按钮（仍需确认）。
+	ShutdownPlan UnraidShutdownPlanConfig `yaml:"shutdown_plan"`
+}
+
+type UnraidShutdownPlanConfig struct {
+	// Groups 为按顺序停止的容器组 ID（引用 unraid.groups）。
+	Groups []string `yaml:"groups"`
+	// PVEGuests 为需要关机的 PVE 虚拟机/容器（引用 pve.instances 的 id）。
+	PVEGuests []UnraidShutdownPVEGuest `yaml:"pve_guests"`
+}
+
+type UnraidShutdownPVEGuest struct {
+	Instance string `yaml:"instance"`
+	VMID     int    `yaml:"vmid"`
+}
+
+type UnraidStorageRule struct {
+	Name      string  `yaml:"name"`
+	Threshold float64 `yaml:"threshold"`
 }
 
 type QinglongConfig struct {
 	Instances []QinglongInstance `yaml:"instances"`
+
+	// AdminUserIDs 为可安装/重装依赖的管理员；为空表示已授权用户均可操作。
+	AdminUserIDs []string `yaml:"admin_userids"`
+
+	// LogDelivery 控制任务日志的下发方式（truncate/split/file，含义同 unraid.log_delivery）。
+	LogDelivery string `yaml:"log_delivery"`
 }
 
 type QinglongInstance struct {
@@ -171,10 +503,36 @@ type PVEAlertConfig struct {
 
 type AuthConfig struct {
 	AllowedUserIDs []string `yaml:"allowed_userids"`
+	// AllowedPartyIDs 为授权的部门 ID（含子部门成员），经 user/list 解析为成员。
+	AllowedPartyIDs []int `yaml:"allowed_partyids"`
+	// AllowedTagIDs 为授权的标签 ID（含标签下的部门），经 tag/get 解析为成员。
+	AllowedTagIDs []int `yaml:"allowed_tagids"`
+	// DirectoryRefresh 为部门/标签成员的刷新间隔（默认 10m）。
+	DirectoryRefresh Duration `yaml:"directory_refresh"`
+}
+
+// HasDirectory 判断是否按部门/标签授权（需定时解析通讯录）。
+func (a AuthConfig) HasDirectory() bool {
+	return len(a.AllowedPartyIDs) > 0 || len(a.AllowedTagIDs) > 0
+}
+
+// ToParty 返回 message/send 的 toparty（“|”分隔），供告警直接发送到授权部门。
+func (a AuthConfig) ToParty() string { return joinIDs(a.AllowedPartyIDs) }
+
+// ToTag 返回 message/send 的 totag（“|”分隔），供告警直接发送到授权标签。
+func (a AuthConfig) ToTag() string { return joinIDs(a.AllowedTagIDs) }
+
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprint(id))
+	}
+	return strings.Join(parts, "|")
 }
 
 var qinglongInstanceIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,31}$`)
 var pveInstanceIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,31}$`)
+var unraidGroupIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,31}$`)
 var graphqlIdentifierPattern = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
 
 func Load(path string) (Config, error) {
@@ -228,17 +586,29 @@ func Load(path string) (Config, error) {
 		"wecom.agentid", cfg.WeCom.AgentID,
 		"wecom.api_base_url", cfg.WeCom.APIBaseURL,
 		"wecom.template_card_mode", cfg.WeCom.TemplateCardMode,
+		"wecom.markdown_mode", cfg.WeCom.MarkdownMode,
+		"wecom.appchat_chats_count", len(cfg.WeCom.AppChat.Chats),
+		"wecom.robots_count", len(cfg.WeCom.Robots),
+		"wecom.outbox_enabled", *cfg.WeCom.Outbox.Enabled,
+		"wecom.menu_override", len(cfg.WeCom.Menu) > 0,
+		"wecom.apps_count", len(cfg.WeCom.Apps),
+		"wecom.callback_max_skew", cfg.WeCom.CallbackMaxSkew.ToDuration().String(),
 		"wecom.token_len", len(cfg.WeCom.Token),
 		"wecom.encoding_aes_key_len", len(cfg.WeCom.EncodingAESKey),
 		"wecom.secret_len", len(cfg.WeCom.Secret),
 
 		"auth.allowed_userids_count", len(cfg.Auth.AllowedUserIDs),
 		"auth.allowed_userids_sample", maskSensitiveSlice(cfg.Auth.AllowedUserIDs, 3),
+		"auth.allowed_partyids_count", len(cfg.Auth.AllowedPartyIDs),
+		"auth.allowed_tagids_count", len(cfg.Auth.AllowedTagIDs),
 
-		"unraid.enabled", strings.TrimSpace(cfg.Unraid.Endpoint) != "" && strings.TrimSpace(cfg.Unraid.APIKey) != "",
+		"unraid.enabled", cfg.ServiceEnabled("unraid"),
+		"unraid.alert_enabled", cfg.Unraid.Alert.Enabled,
+		"unraid.ups_enabled", cfg.Unraid.UPS.Enabled,
+		"unraid.stats_sampler_enabled", cfg.Unraid.StatsSampler.Enabled,
 		"qinglong.instances_count", len(cfg.Qinglong.Instances),
 		"pve.instances_count", len(cfg.PVE.Instances),
-		"pve.enabled", len(cfg.PVE.Instances) > 0,
+		"pve.enabled", cfg.ServiceEnabled("pve"),
 		"pve.alert_enabled", len(cfg.PVE.Instances) > 0 && cfg.PVE.Alert.Enabled != nil && *cfg.PVE.Alert.Enabled,
 	)
 
@@ -261,12 +631,32 @@ func applyDefaults(cfg *Config) {
 	if cfg.Core.StateTTL == 0 {
 		cfg.Core.StateTTL = Duration(30 * time.Minute)
 	}
+	if cfg.Auth.DirectoryRefresh == 0 {
+		cfg.Auth.DirectoryRefresh = Duration(10 * time.Minute)
+	}
 	if cfg.WeCom.APIBaseURL == "" {
 		cfg.WeCom.APIBaseURL = "https://qyapi.weixin.qq.com/cgi-bin"
 	}
 	if strings.TrimSpace(cfg.WeCom.TemplateCardMode) == "" {
 		cfg.WeCom.TemplateCardMode = "template_card"
 	}
+	if cfg.WeCom.CallbackMaxSkew == 0 {
+		cfg.WeCom.CallbackMaxSkew = Duration(5 * time.Minute)
+	}
+	if cfg.WeCom.Outbox.Enabled == nil {
+		v := true
+		cfg.WeCom.Outbox.Enabled = &v
+	}
+	if cfg.WeCom.AppChat.NotifyUsers == nil {
+		v := true
+		cfg.WeCom.AppChat.NotifyUsers = &v
+	}
+	if strings.TrimSpace(cfg.Unraid.LogDelivery) == "" {
+		cfg.Unraid.LogDelivery = "truncate"
+	}
+	if strings.TrimSpace(cfg.Qinglong.LogDelivery) == "" {
+		cfg.Qinglong.LogDelivery = "truncate"
+	}
 	if cfg.Unraid.Origin == "" {
 		cfg.Unraid.Origin = "wecom-home-ops"
 	}
@@ -296,6 +686,48 @@ func applyDefaults(cfg *Config) {
 		cfg.Unraid.ForceUpdateReturnFields = []string{"__typename"}
 	}
 
+	if cfg.Unraid.Alert.Interval == 0 {
+		cfg.Unraid.Alert.Interval = Duration(5 * time.Minute)
+	}
+	if cfg.Unraid.Alert.Cooldown == 0 {
+		cfg.Unraid.Alert.Cooldown = Duration(30 * time.Minute)
+	}
+	if cfg.Unraid.Alert.MuteFor == 0 {
+		cfg.Unraid.Alert.MuteFor = Duration(30 * time.Minute)
+	}
+	if cfg.Unraid.Alert.CPUUsageThreshold == 0 {
+		cfg.Unraid.Alert.CPUUsageThreshold = 90
+	}
+	if cfg.Unraid.Alert.MemUsageThreshold == 0 {
+		cfg.Unraid.Alert.MemUsageThreshold = 90
+	}
+	if cfg.Unraid.Alert.UPSLoadThreshold == 0 {
+		cfg.Unraid.Alert.UPSLoadThreshold = 80
+	}
+	if cfg.Unraid.Alert.ShareUsageThreshold == 0 {
+		cfg.Unraid.Alert.ShareUsageThreshold = 90
+	}
+	if cfg.Unraid.Alert.PoolUsageThreshold == 0 {
+		cfg.Unraid.Alert.PoolUsageThreshold = 90
+	}
+
+	if cfg.Unraid.UPS.Interval == 0 {
+		cfg.Unraid.UPS.Interval = Duration(30 * time.Second)
+	}
+	if cfg.Unraid.UPS.ChargeThreshold == 0 {
+		cfg.Unraid.UPS.ChargeThreshold = 50
+	}
+	if cfg.Unraid.UPS.RuntimeThreshold == 0 {
+		cfg.Unraid.UPS.RuntimeThreshold = Duration(10 * time.Minute)
+	}
+
+	if cfg.Unraid.StatsSampler.Interval == 0 {
+		cfg.Unraid.StatsSampler.Interval = Duration(time.Minute)
+	}
+	if cfg.Unraid.StatsSampler.Retention == 0 {
+		cfg.Unraid.StatsSampler.Retention = Duration(time.Hour)
+	}
+
 	if cfg.PVE.Alert.Enabled == nil {
 		v := true
 		cfg.PVE.Alert.Enabled = &v
@@ -359,6 +791,94 @@ func validate(cfg Config) error {
 	default:
 		problems = append(problems, "wecom.template_card_mode 不合法（仅支持 template_card/both/text）")
 	}
+	switch strings.ToLower(strings.TrimSpace(cfg.WeCom.MarkdownMode)) {
+	case "", "markdown", "text":
+	default:
+		problems = append(problems, "wecom.markdown_mode 不合法（仅支持 markdown/text）")
+	}
+	chatCategories := make([]string, 0, len(cfg.WeCom.AppChat.Chats))
+	for category := range cfg.WeCom.AppChat.Chats {
+		chatCategories = append(chatCategories, category)
+	}
+	sort.Strings(chatCategories)
+	for _, category := range chatCategories {
+		chatID := cfg.WeCom.AppChat.Chats[category]
+		known := false
+		for _, c := range appChatCategories {
+			if c == category {
+				known = true
+				break
+			}
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("wecom.appchat.chats 类别不支持：%s（仅支持 %s）", category, strings.Join(appChatCategories, "/")))
+		}
+		if !appChatIDPattern.MatchString(strings.TrimSpace(chatID)) {
+			problems = append(problems, fmt.Sprintf("wecom.appchat.chats.%s 不合法（仅允许字母数字，长度≤32）", category))
+		}
+	}
+	if id := strings.TrimSpace(cfg.WeCom.AppChat.Create.ChatID); id != "" && !appChatIDPattern.MatchString(id) {
+		problems = append(problems, "wecom.appchat.create.chatid 不合法（仅允许字母数字，长度≤32）")
+	}
+	if n := cfg.WeCom.Outbox.PerUserPerMinute; n < 0 || n > 30 {
+		problems = append(problems, "wecom.outbox.per_user_per_minute 不合法（0-30，0 表示默认）")
+	}
+	if cfg.WeCom.Outbox.GlobalPerMinute < 0 {
+		problems = append(problems, "wecom.outbox.global_per_minute 不能为负数")
+	}
+	if cfg.WeCom.Outbox.MaxPending < 0 {
+		problems = append(problems, "wecom.outbox.max_pending 不能为负数")
+	}
+	for i, d := range cfg.WeCom.Outbox.RetrySchedule {
+		if d <= 0 {
+			problems = append(problems, fmt.Sprintf("wecom.outbox.retry_schedule[%d] 必须为正数", i))
+		}
+	}
+	robotNames := make(map[string]bool, len(cfg.WeCom.Robots))
+	for i, robot := range cfg.WeCom.Robots {
+		name := strings.TrimSpace(robot.Name)
+		if name == "" {
+			problems = append(problems, fmt.Sprintf("wecom.robots[%d].name 不能为空", i))
+		} else if robotNames[name] {
+			problems = append(problems, fmt.Sprintf("wecom.robots[%d].name 重复：%s", i, name))
+		}
+		robotNames[name] = true
+		if strings.TrimSpace(robot.Key) == "" {
+			problems = append(problems, fmt.Sprintf("wecom.robots[%d].key 不能为空", i))
+		}
+		if len(robot.Categories) == 0 {
+			problems = append(problems, fmt.Sprintf("wecom.robots[%d].categories 不能为空", i))
+		}
+		for _, category := range robot.Categories {
+			known := false
+			for _, c := range appChatCategories {
+				if c == strings.TrimSpace(category) {
+					known = true
+					break
+				}
+			}
+			if !known {
+				problems = append(problems, fmt.Sprintf("wecom.robots[%d].categories 类别不支持：%s（仅支持 %s）", i, category, strings.Join(appChatCategories, "/")))
+			}
+		}
+	}
+	if cfg.WeCom.CallbackMaxSkew.ToDuration() < 30*time.Second {
+		problems = append(problems, "wecom.callback_max_skew 不能小于 30s")
+	}
+	problems = append(problems, validateWeComApps(cfg)...)
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"unraid.log_delivery", cfg.Unraid.LogDelivery},
+		{"qinglong.log_delivery", cfg.Qinglong.LogDelivery},
+	} {
+		switch strings.ToLower(strings.TrimSpace(f.value)) {
+		case "truncate", "split", "file":
+		default:
+			problems = append(problems, f.name+" 不合法（仅支持 truncate/split/file）")
+		}
+	}
 
 	hasUnraid := strings.TrimSpace(cfg.Unraid.Endpoint) != "" || strings.TrimSpace(cfg.Unraid.APIKey) != ""
 	if hasUnraid {
@@ -422,13 +942,29 @@ func validate(cfg Config) error {
 			}
 		}
 
+		hasWebGUILogin := strings.TrimSpace(cfg.Unraid.WebGUIUsername) != "" || cfg.Unraid.WebGUIPassword != ""
+		if hasWebGUILogin {
+			if strings.TrimSpace(cfg.Unraid.WebGUIUsername) == "" {
+				problems = append(problems, "unraid.webgui_username 不能为空（配置 webgui_password 时必填）")
+			}
+			if cfg.Unraid.WebGUIPassword == "" {
+				problems = append(problems, "unraid.webgui_password 不能为空（配置 webgui_username 时必填）")
+			}
+			if strings.TrimSpace(cfg.Unraid.WebGUILoginURL) != "" {
+				u, err := url.Parse(cfg.Unraid.WebGUILoginURL)
+				if err != nil || u.Scheme == "" || u.Host == "" {
+					problems = append(problems, "unraid.webgui_login_url 不合法（示例：http://<ip>/login）")
+				}
+			}
+		}
+
 		hasWebGUIFallback := strings.TrimSpace(cfg.Unraid.WebGUICSRFToken) != "" ||
 			strings.TrimSpace(cfg.Unraid.WebGUICookie) != "" ||
 			strings.TrimSpace(cfg.Unraid.WebGUICommandURL) != "" ||
 			strings.TrimSpace(cfg.Unraid.WebGUIEventsURL) != ""
 		if hasWebGUIFallback {
-			if strings.TrimSpace(cfg.Unraid.WebGUICSRFToken) == "" {
-				problems = append(problems, "unraid.webgui_csrf_token 不能为空（启用 WebGUI 兜底时必填）")
+			if strings.TrimSpace(cfg.Unraid.WebGUICSRFToken) == "" && !hasWebGUILogin {
+				problems = append(problems, "unraid.webgui_csrf_token 不能为空（启用 WebGUI 兜底且未配置 webgui_username/webgui_password 时必填）")
 			}
 			if strings.TrimSpace(cfg.Unraid.WebGUICommandURL) != "" {
 				u, err := url.Parse(cfg.Unraid.WebGUICommandURL)
@@ -443,6 +979,111 @@ func validate(cfg Config) error {
 				}
 			}
 		}
+
+		if (strings.TrimSpace(cfg.Unraid.FlashBackup.RemoteName) == "") != (strings.TrimSpace(cfg.Unraid.FlashBackup.DestinationPath) == "") {
+			problems = append(problems, "unraid.flash_backup.remote_name 与 destination_path 需同时配置")
+		}
+
+		seenGroups := make(map[string]struct{})
+		for i, g := range cfg.Unraid.Groups {
+			prefix := fmt.Sprintf("unraid.groups[%d].", i)
+			if !unraidGroupIDPattern.MatchString(g.ID) {
+				problems = append(problems, prefix+"id 不合法（仅允许字母数字/下划线/短横线，最长 32）")
+			} else if _, ok := seenGroups[g.ID]; ok {
+				problems = append(problems, prefix+"id 重复："+g.ID)
+			} else {
+				seenGroups[g.ID] = struct{}{}
+			}
+			if len(g.Containers) == 0 && strings.TrimSpace(g.Pattern) == "" && strings.TrimSpace(g.Label) == "" {
+				problems = append(problems, prefix+"containers/pattern/label 至少配置一项")
+			}
+			if strings.TrimSpace(g.Pattern) != "" {
+				if _, err := regexp.Compile(g.Pattern); err != nil {
+					problems = append(problems, prefix+"pattern 不是合法的正则表达式")
+				}
+			}
+			if g.Delay.ToDuration() < 0 {
+				problems = append(problems, prefix+"delay 不能为负数")
+			}
+		}
+
+		if cfg.Unraid.Alert.Enabled {
+			if cfg.Unraid.Alert.Interval.ToDuration() <= 0 {
+				problems = append(problems, "unraid.alert.interval 不能为空且必须为正数（例如 5m）")
+			}
+			if cfg.Unraid.Alert.Cooldown.ToDuration() <= 0 {
+				problems = append(problems, "unraid.alert.cooldown 不能为空且必须为正数（例如 30m）")
+			}
+			if cfg.Unraid.Alert.MuteFor.ToDuration() <= 0 {
+				problems = append(problems, "unraid.alert.mute_for 不能为空且必须为正数（例如 30m）")
+			}
+			// 主机阈值未配置（0）时已填充默认值，负数表示关闭该项检查。
+			for _, th := range []struct {
+				name  string
+				value float64
+			}{
+				{"cpu_usage_threshold", cfg.Unraid.Alert.CPUUsageThreshold},
+				{"mem_usage_threshold", cfg.Unraid.Alert.MemUsageThreshold},
+				{"ups_load_threshold", cfg.Unraid.Alert.UPSLoadThreshold},
+				{"container_cpu_threshold", cfg.Unraid.Alert.ContainerCPUThreshold},
+				{"container_mem_threshold", cfg.Unraid.Alert.ContainerMemThreshold},
+				{"share_usage_threshold", cfg.Unraid.Alert.ShareUsageThreshold},
+				{"pool_usage_threshold", cfg.Unraid.Alert.PoolUsageThreshold},
+			} {
+				if th.value > 100 {
+					problems = append(problems, fmt.Sprintf("unraid.alert.%s 不合法（范围 1~100，负数表示不检查）", th.name))
+				}
+			}
+			for i, r := range cfg.Unraid.Alert.StorageRules {
+				if strings.TrimSpace(r.Name) == "" {
+					problems = append(problems, fmt.Sprintf("unraid.alert.storage_rules[%d].name 不能为空", i))
+				}
+				if r.Threshold == 0 || r.Threshold > 100 {
+					problems = append(problems, fmt.Sprintf("unraid.alert.storage_rules[%d].threshold 不合法（范围 1~100，负数表示不检查）", i))
+				}
+			}
+		}
+
+		if cfg.Unraid.UPS.Enabled {
+			if cfg.Unraid.UPS.Interval.ToDuration() <= 0 {
+				problems = append(problems, "unraid.ups.interval 不能为空且必须为正数（例如 30s）")
+			}
+			if cfg.Unraid.UPS.ChargeThreshold < 0 || cfg.Unraid.UPS.ChargeThreshold > 100 {
+				problems = append(problems, "unraid.ups.charge_threshold 不合法（范围 1~100）")
+			}
+			if cfg.Unraid.UPS.RuntimeThreshold.ToDuration() < 0 {
+				problems = append(problems, "unraid.ups.runtime_threshold 不能为负数")
+			}
+		}
+		if cfg.Unraid.StatsSampler.Enabled {
+			if cfg.Unraid.StatsSampler.Interval.ToDuration() <= 0 {
+				problems = append(problems, "unraid.stats_sampler.interval 不能为空且必须为正数（例如 1m）")
+			}
+			if cfg.Unraid.StatsSampler.Retention.ToDuration() < cfg.Unraid.StatsSampler.Interval.ToDuration() {
+				problems = append(problems, "unraid.stats_sampler.retention 不能小于 interval")
+			}
+		}
+		for i, id := range cfg.Unraid.UPS.ShutdownPlan.Groups {
+			if _, ok := seenGroups[id]; !ok {
+				problems = append(problems, fmt.Sprintf("unraid.ups.shutdown_plan.groups[%d] 未在 unraid.groups 中定义：%s", i, id))
+			}
+		}
+		for i, g := range cfg.Unraid.UPS.ShutdownPlan.PVEGuests {
+			prefix := fmt.Sprintf("unraid.ups.shutdown_plan.pve_guests[%d].", i)
+			found := false
+			for _, ins := range cfg.PVE.Instances {
+				if ins.ID == g.Instance {
+					found = true
+					break
+				}
+			}
+			if !found {
+				problems = append(problems, prefix+"instance 未在 pve.instances 中定义："+g.Instance)
+			}
+			if g.VMID <= 0 {
+				problems = append(problems, prefix+"vmid 不合法")
+			}
+		}
 	}
 
 	if len(cfg.Qinglong.Instances) > 0 {
@@ -535,8 +1176,21 @@ func validate(cfg Config) error {
 		}
 	}
 
-	if len(cfg.Auth.AllowedUserIDs) == 0 {
-		problems = append(problems, "auth.allowed_userids 不能为空（MVP 仅支持白名单）")
+	if len(cfg.Auth.AllowedUserIDs) == 0 && !cfg.Auth.HasDirectory() {
+		problems = append(problems, "auth.allowed_userids 不能为空（或配置 allowed_partyids/allowed_tagids）")
+	}
+	for i, id := range cfg.Auth.AllowedPartyIDs {
+		if id <= 0 {
+			problems = append(problems, fmt.Sprintf("auth.allowed_partyids[%d] 必须为正整数", i))
+		}
+	}
+	for i, id := range cfg.Auth.AllowedTagIDs {
+		if id <= 0 {
+			problems = append(problems, fmt.Sprintf("auth.allowed_tagids[%d] 必须为正整数", i))
+		}
+	}
+	if cfg.Auth.HasDirectory() && cfg.Auth.DirectoryRefresh.ToDuration() < time.Minute {
+		problems = append(problems, "auth.directory_refresh 不能小于 1m")
 	}
 
 	hasQinglong := len(cfg.Qinglong.Instances) > 0
@@ -638,3 +1292,64 @@ func maskSensitiveSlice(ss []string, max int) []string {
 	}
 	return out
 }
+
+func validateWeComApps(cfg Config) []string {
+	var problems []string
+	names := make(map[string]bool, len(cfg.WeCom.Apps))
+	agents := map[string]bool{fmt.Sprintf("%s/%d", cfg.WeCom.CorpID, cfg.WeCom.AgentID): true}
+	for i, app := range cfg.WeCom.Apps {
+		path := fmt.Sprintf("wecom.apps[%d]", i)
+		name := strings.TrimSpace(app.Name)
+		if !weComAppNamePattern.MatchString(name) {
+			problems = append(problems, path+".name 不合法（需匹配 ^[a-z0-9][a-z0-9_-]{0,31}$，用于回调路径）")
+		} else if names[name] {
+			problems = append(problems, fmt.Sprintf("%s.name 重复：%s", path, name))
+		}
+		names[name] = true
+
+		if app.AgentID == 0 {
+			problems = append(problems, path+".agentid 不能为空")
+		} else {
+			corpID := strings.TrimSpace(app.CorpID)
+			if corpID == "" {
+				corpID = cfg.WeCom.CorpID
+			}
+			agent := fmt.Sprintf("%s/%d", corpID, app.AgentID)
+			if agents[agent] {
+				problems = append(problems, fmt.Sprintf("%s.agentid 与其他应用重复：%d", path, app.AgentID))
+			}
+			agents[agent] = true
+		}
+		if app.Secret == "" {
+			problems = append(problems, path+".secret 不能为空")
+		}
+		if app.Token == "" {
+			problems = append(problems, path+".token 不能为空")
+		}
+		if app.EncodingAESKey == "" {
+			problems = append(problems, path+".encoding_aes_key 不能为空")
+		}
+		for _, p := range app.Providers {
+			p = strings.TrimSpace(p)
+			known := false
+			for _, key := range ServiceKeys {
+				if key == p {
+					known = true
+					break
+				}
+			}
+			switch {
+			case !known:
+				problems = append(problems, fmt.Sprintf("%s.providers 服务不支持：%s（仅支持 %s）", path, p, strings.Join(ServiceKeys, "/")))
+			case !cfg.ServiceEnabled(p):
+				problems = append(problems, fmt.Sprintf("%s.providers 服务未配置：%s", path, p))
+			}
+		}
+		for j, id := range app.AllowedUserIDs {
+			if strings.TrimSpace(id) == "" {
+				problems = append(problems, fmt.Sprintf("%s.allowed_userids[%d] 不能为空", path, j))
+			}
+		}
+	}
+	return problems
+}
@@ -261,6 +261,80 @@ func TestValidate_UnraidGraphQLConfig(t *testing.T) {
 	}
 }
 
+func TestValidate_UnraidAlertThresholds(t *testing.T) {
+	t.Parallel()
+
+	base := func(alert UnraidAlertConfig) Config {
+		cfg := Config{
+			Server: ServerConfig{
+				ListenAddr:        ":8080",
+				HTTPClientTimeout: Duration(15 * time.Second),
+				ReadHeaderTimeout: Duration(10 * time.Second),
+			},
+			Core: CoreConfig{
+				StateTTL: Duration(30 * time.Minute),
+			},
+			WeCom: WeComConfig{
+				CorpID:         "ww",
+				AgentID:        1,
+				Secret:         "s",
+				Token:          "t",
+				EncodingAESKey: "k",
+				APIBaseURL:     "https://qyapi.weixin.qq.com/cgi-bin",
+			},
+			Auth: AuthConfig{
+				AllowedUserIDs: []string{"u"},
+			},
+			Unraid: UnraidConfig{
+				Endpoint: "http://x/graphql",
+				APIKey:   "k",
+				Alert:    alert,
+			},
+		}
+		applyDefaults(&cfg)
+		return cfg
+	}
+
+	// 未配置时使用默认值；负数表示关闭检查，且不会被默认值覆盖。
+	cfg := base(UnraidAlertConfig{Enabled: true, CPUUsageThreshold: -1, UPSLoadThreshold: -1})
+	if cfg.Unraid.Alert.CPUUsageThreshold != -1 || cfg.Unraid.Alert.MemUsageThreshold != 90 || cfg.Unraid.Alert.UPSLoadThreshold != -1 {
+		t.Fatalf("thresholds = %v/%v/%v, want -1/90/-1", cfg.Unraid.Alert.CPUUsageThreshold, cfg.Unraid.Alert.MemUsageThreshold, cfg.Unraid.Alert.UPSLoadThreshold)
+	}
+	if err := validate(cfg); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	cfg = base(UnraidAlertConfig{Enabled: true, MemUsageThreshold: 101})
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "mem_usage_threshold") {
+		t.Fatalf("validate() error = %v, want mem_usage_threshold", err)
+	}
+
+	// 共享目录/缓存池阈值设为负数时关闭存储告警，且不会被默认值覆盖。
+	cfg = base(UnraidAlertConfig{
+		Enabled:             true,
+		ShareUsageThreshold: -1,
+		PoolUsageThreshold:  -1,
+		StorageRules:        []UnraidStorageRule{{Name: "appdata", Threshold: -1}},
+	})
+	if cfg.Unraid.Alert.ShareUsageThreshold != -1 || cfg.Unraid.Alert.PoolUsageThreshold != -1 {
+		t.Fatalf("storage thresholds = %v/%v, want -1/-1", cfg.Unraid.Alert.ShareUsageThreshold, cfg.Unraid.Alert.PoolUsageThreshold)
+	}
+	if err := validate(cfg); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	// 容器阈值与主机/存储阈值规则一致：负数同样表示不检查。
+	cfg = base(UnraidAlertConfig{Enabled: true, ContainerCPUThreshold: -1, ContainerMemThreshold: 80})
+	if err := validate(cfg); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	cfg = base(UnraidAlertConfig{Enabled: true, ContainerCPUThreshold: 120})
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "container_cpu_threshold") {
+		t.Fatalf("validate() error = %v, want container_cpu_threshold", err)
+	}
+}
+
 func TestValidate_QinglongInstanceID(t *testing.T) {
 	t.Parallel()
 
@@ -524,3 +598,129 @@ func TestValidate_WeComAndAuthRequiredFields(t *testing.T) {
 		t.Fatalf("validate() error = nil, want not nil")
 	}
 }
+
+func TestValidate_WeComRobots(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		Server: ServerConfig{
+			ListenAddr:        ":8080",
+			HTTPClientTimeout: Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(10 * time.Second),
+		},
+		Core: CoreConfig{
+			StateTTL: Duration(30 * time.Minute),
+		},
+		WeCom: WeComConfig{
+			CorpID:         "ww",
+			AgentID:        1,
+			Secret:         "s",
+			Token:          "t",
+			EncodingAESKey: "k",
+			APIBaseURL:     "https://qyapi.weixin.qq.com/cgi-bin",
+			Robots: []WeComRobotConfig{
+				{Name: "family", Key: "k1", Categories: []string{"alert", "startup"}},
+			},
+		},
+		Auth: AuthConfig{
+			AllowedUserIDs: []string{"u"},
+		},
+		PVE: PVEConfig{
+			Instances: []PVEInstance{
+				{ID: "home", Name: "Home", BaseURL: "https://pve.example:8006", APIToken: "PVEAPIToken=root@pam!t=uuid"},
+			},
+		},
+	}
+	applyDefaults(&cfg)
+	if err := validate(cfg); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+
+	robot := cfg.WeCom.Robots[0]
+	for category, want := range map[string]bool{"pve_alert": true, "unraid_ups": true, "startup": true, "announce": false} {
+		if got := robot.Subscribes(category); got != want {
+			t.Fatalf("Subscribes(%q) = %v, want %v", category, got, want)
+		}
+	}
+
+	cfg.WeCom.Robots = append(cfg.WeCom.Robots, WeComRobotConfig{Name: "family", Categories: []string{"nope"}})
+	err := validate(cfg)
+	if err == nil {
+		t.Fatalf("validate() error = nil, want error")
+	}
+	for _, want := range []string{"wecom.robots[1].name 重复", "wecom.robots[1].key 不能为空", "类别不支持：nope"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("validate() error = %v, want contains %q", err, want)
+		}
+	}
+}
+
+func TestValidate_WeComApps(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		Server: ServerConfig{
+			ListenAddr:        ":8080",
+			HTTPClientTimeout: Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(10 * time.Second),
+		},
+		Core: CoreConfig{
+			StateTTL: Duration(30 * time.Minute),
+		},
+		WeCom: WeComConfig{
+			CorpID:         "ww",
+			AgentID:        1,
+			Secret:         "s",
+			Token:          "t",
+			EncodingAESKey: "k",
+			Apps: []WeComAppConfig{
+				{Name: "virt", AgentID: 2, Secret: "s2", Token: "t2", EncodingAESKey: "k2", Providers: []string{"pve"}, AllowedUserIDs: []string{"ops"}},
+				{Name: "nas", AgentID: 3, Secret: "s3", Token: "t3", EncodingAESKey: "k3"},
+			},
+		},
+		Auth: AuthConfig{
+			AllowedUserIDs: []string{"u"},
+		},
+		PVE: PVEConfig{
+			Instances: []PVEInstance{
+				{ID: "home", Name: "Home", BaseURL: "https://pve.example:8006", APIToken: "PVEAPIToken=root@pam!t=uuid"},
+			},
+		},
+	}
+	applyDefaults(&cfg)
+	if err := validate(cfg); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+
+	apps := cfg.WeComApps()
+	if len(apps) != 3 || apps[0].CallbackPath() != "/wecom/callback" || apps[1].CallbackPath() != "/wecom/callback/virt" {
+		t.Fatalf("WeComApps() = %+v", apps)
+	}
+	if apps[1].CorpID != "ww" || apps[1].HasProvider("unraid") || !apps[1].HasProvider("pve") {
+		t.Fatalf("virt app = %+v, want inherited corpid and pve only", apps[1])
+	}
+	if len(apps[2].AllowedUserIDs) != 1 || apps[2].AllowedUserIDs[0] != "u" || !apps[2].HasProvider("qinglong") {
+		t.Fatalf("nas app = %+v, want inherited allowed_userids and all providers", apps[2])
+	}
+
+	cfg.WeCom.Apps = append(cfg.WeCom.Apps,
+		WeComAppConfig{Name: "nas", AgentID: 1, Providers: []string{"unraid", "nope"}},
+		WeComAppConfig{Name: "Bad/Name", AgentID: 4, Secret: "s", Token: "t", EncodingAESKey: "k"},
+	)
+	err := validate(cfg)
+	if err == nil {
+		t.Fatalf("validate() error = nil, want error")
+	}
+	for _, want := range []string{
+		"wecom.apps[2].name 重复：nas",
+		"wecom.apps[2].agentid 与其他应用重复：1",
+		"wecom.apps[2].secret 不能为空",
+		"wecom.apps[2].providers 服务未配置：unraid",
+		"wecom.apps[2].providers 服务不支持：nope",
+		"wecom.apps[3].name 不合法",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("validate() error = %v, want contains %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+// longtext.go 提供超长文本的统一下发：截断、分条（按行带序号）或以文件形式发送。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// defaultLongTextMaxParts 为分条模式的默认最大条数，避免刷屏。
+const defaultLongTextMaxParts = 5
+
+type LongTextOptions struct {
+	Mode wecom.LongTextMode
+
+	// Filename 为文件模式的文件名（默认 output.txt）。
+	Filename string
+	// KeepTail 为 true 时截断模式保留首行标题与末尾的最新内容（适用于日志），默认保留开头。
+	KeepTail bool
+	// MaxParts 为分条模式的最大条数（默认 5）；超出时优先改用文件发送，不支持文件时仅发送最后 MaxParts 条（日志最新内容在末尾）。
+	MaxParts int
+}
+
+// SendLongText 按 opts.Mode 下发可能超长的文本；未超长时始终作为单条文本发送。
+// 文件模式依赖发送端实现 FileSender，不支持或上传失败时降级为分条发送。
+func SendLongText(ctx context.Context, sender WeComSender, msg wecom.TextMessage, opts LongTextOptions) error {
+	content := msg.Content
+	if len(content) <= wecom.TextChunkBytes {
+		return sender.SendText(ctx, msg)
+	}
+
+	maxParts := opts.MaxParts
+	if maxParts <= 0 {
+		maxParts = defaultLongTextMaxParts
+	}
+
+	switch opts.Mode {
+	case wecom.LongTextModeFile:
+		if ok, err := sendTextAsFile(ctx, sender, msg, opts.Filename); ok {
+			return err
+		}
+		return sendTextParts(ctx, sender, msg, wecom.SplitText(content, wecom.TextChunkBytes), maxParts)
+
+	case wecom.LongTextModeSplit:
+		parts := wecom.SplitText(content, wecom.TextChunkBytes)
+		if len(parts) > maxParts {
+			if ok, err := sendTextAsFile(ctx, sender, msg, opts.Filename); ok {
+				return err
+			}
+		}
+		return sendTextParts(ctx, sender, msg, parts, maxParts)
+
+	default:
+		if opts.KeepTail {
+			msg.Content = wecom.TruncateTextTail(content, wecom.TextChunkBytes)
+		} else {
+			msg.Content = wecom.TruncateText(content, wecom.TextChunkBytes)
+		}
+		return sender.SendText(ctx, msg)
+	}
+}
+
+// sendTextAsFile 尝试以文件形式发送；返回 ok=false 表示需要调用方降级为文本。
+func sendTextAsFile(ctx context.Context, sender WeComSender, msg wecom.TextMessage, filename string) (bool, error) {
+	fs, ok := sender.(FileSender)
+	if !ok {
+		return false, nil
+	}
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		filename = "output.txt"
+	}
+	mediaID, err := fs.UploadMedia(ctx, wecom.MediaTypeFile, filename, []byte(msg.Content))
+	if err != nil {
+		slog.Warn("长文本上传文件失败，降级为分条发送", "user_id", msg.ToUser, "filename", filename, "error", err)
+		return false, nil
+	}
+	if err := sender.SendText(ctx, wecom.TextMessage{
+		ToUser:  msg.ToUser,
+		Content: fmt.Sprintf("内容较长（%d 字节），已以文件发送：%s", len(msg.Content), filename),
+	}); err != nil {
+		return true, err
+	}
+	return true, fs.SendFile(ctx, wecom.FileMessage{ToUser: msg.ToUser, MediaID: mediaID})
+}
+
+// sendTextParts 逐条发送分段；超出 maxParts 时先提示省略的前段，再发送最后 maxParts 段。
+func sendTextParts(ctx context.Context, sender WeComSender, msg wecom.TextMessage, parts []string, maxParts int) error {
+	if omitted := len(parts) - maxParts; omitted > 0 {
+		if err := sender.SendText(ctx, wecom.TextMessage{
+			ToUser:  msg.ToUser,
+			Content: fmt.Sprintf("（前 %d 段已省略，仅发送最后 %d 段）", omitted, maxParts),
+		}); err != nil {
+			return err
+		}
+		parts = parts[omitted:]
+	}
+	for _, part := range parts {
+		if err := sender.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, Content: part}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
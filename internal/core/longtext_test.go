@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+type recordWeComFile struct {
+	recordWeCom
+	uploadErr error
+	uploads   []string
+	files     []wecom.FileMessage
+}
+
+func (r *recordWeComFile) UploadMedia(_ context.Context, _ string, filename string, _ []byte) (string, error) {
+	if r.uploadErr != nil {
+		return "", r.uploadErr
+	}
+	r.uploads = append(r.uploads, filename)
+	return "MID", nil
+}
+
+func (r *recordWeComFile) SendFile(_ context.Context, msg wecom.FileMessage) error {
+	r.files = append(r.files, msg)
+	return nil
+}
+
+func TestSendLongText_Modes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	long := strings.Repeat(strings.Repeat("x", 99)+"\n", 40) // 约 4000 字节，3 段
+
+	short := &recordWeCom{}
+	if err := SendLongText(ctx, short, wecom.TextMessage{ToUser: "u", Content: "hi"}, LongTextOptions{Mode: wecom.LongTextModeFile}); err != nil {
+		t.Fatalf("SendLongText(short) error: %v", err)
+	}
+	if len(short.texts) != 1 || short.texts[0].Content != "hi" {
+		t.Fatalf("short texts = %+v", short.texts)
+	}
+
+	trunc := &recordWeCom{}
+	_ = SendLongText(ctx, trunc, wecom.TextMessage{ToUser: "u", Content: long}, LongTextOptions{})
+	if len(trunc.texts) != 1 || !strings.HasSuffix(trunc.texts[0].Content, "（已截断）") {
+		t.Fatalf("truncate texts = %d", len(trunc.texts))
+	}
+	tail := &recordWeCom{}
+	_ = SendLongText(ctx, tail, wecom.TextMessage{ToUser: "u", Content: "日志：\n" + long + "end"}, LongTextOptions{KeepTail: true})
+	if len(tail.texts) != 1 || len(tail.texts[0].Content) > wecom.TextChunkBytes || !strings.HasPrefix(tail.texts[0].Content, "日志：\n…（前略）\n") || !strings.HasSuffix(tail.texts[0].Content, "\nend") {
+		t.Fatalf("keep-tail texts = %+v", tail.texts)
+	}
+
+	split := &recordWeCom{}
+	_ = SendLongText(ctx, split, wecom.TextMessage{ToUser: "u", Content: long}, LongTextOptions{Mode: wecom.LongTextModeSplit})
+	if len(split.texts) != 3 || !strings.HasPrefix(split.texts[0].Content, "（1/3）\n") {
+		t.Fatalf("split texts = %d", len(split.texts))
+	}
+
+	// 分条超过 MaxParts 时改用文件发送。
+	file := &recordWeComFile{}
+	_ = SendLongText(ctx, file, wecom.TextMessage{ToUser: "u", Content: long}, LongTextOptions{Mode: wecom.LongTextModeSplit, MaxParts: 2, Filename: "a.log"})
+	if len(file.uploads) != 1 || file.uploads[0] != "a.log" || len(file.files) != 1 || len(file.texts) != 1 {
+		t.Fatalf("file uploads=%v files=%d texts=%d", file.uploads, len(file.files), len(file.texts))
+	}
+
+	// 上传失败降级为分条，按 MaxParts 保留最后几段（最新内容），并先提示省略的前段。
+	failed := &recordWeComFile{uploadErr: errors.New("boom")}
+	_ = SendLongText(ctx, failed, wecom.TextMessage{ToUser: "u", Content: long}, LongTextOptions{Mode: wecom.LongTextModeFile, MaxParts: 2})
+	if len(failed.files) != 0 || len(failed.texts) != 3 || failed.texts[0].Content != "（前 1 段已省略，仅发送最后 2 段）" ||
+		!strings.HasPrefix(failed.texts[1].Content, "（2/3）\n") || !strings.HasPrefix(failed.texts[2].Content, "（3/3）\n") {
+		t.Fatalf("fallback texts = %+v", failed.texts)
+	}
+}
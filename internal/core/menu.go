@@ -0,0 +1,98 @@
+package core
+
+// menu.go 按已启用的 Provider 组装应用自定义菜单（一级最多 3 个、每个最多 5 个子按钮）。
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// MenuContributor 为 Provider 的可选能力：贡献一个一级菜单（含子按钮）。
+// 一级菜单名额不足时，Router 在“常用”中为其保留一个“进入服务”入口。
+type MenuContributor interface {
+	MenuButton() wecom.MenuButton
+}
+
+// MenuService 为组装菜单所需的服务描述；Button 为 nil 时仅在“常用”中提供“进入服务”入口。
+// 服务包以包级函数提供同样的描述，--wecom-sync-menu 无需创建 Provider。
+type MenuService struct {
+	Key         string
+	DisplayName string
+	Button      *wecom.MenuButton
+}
+
+// MenuServiceOf 返回 Provider 的菜单描述（实现 MenuContributor 时带一级菜单）。
+func MenuServiceOf(p ServiceProvider) MenuService {
+	s := MenuService{Key: p.Key(), DisplayName: p.DisplayName()}
+	if c, ok := p.(MenuContributor); ok {
+		b := c.MenuButton()
+		s.Button = &b
+	}
+	return s
+}
+
+// BuildMenu 生成应用自定义菜单：首个一级菜单为“常用”（操作菜单/自检/帮助），
+// 其后按服务注册顺序放置各自贡献的菜单；超出限制的部分降级为“常用”中的入口或截断。
+func BuildMenu(services []MenuService) wecom.Menu {
+	common := wecom.MenuButton{
+		Name: "常用",
+		SubButtons: []wecom.MenuButton{
+			{Type: "click", Name: "操作菜单", Key: wecom.EventKeyCoreMenu},
+		},
+	}
+	var groups []wecom.MenuButton
+	var entries []wecom.MenuButton
+	for _, s := range services {
+		entry := wecom.MenuButton{Type: "click", Name: s.DisplayName, Key: wecom.EventKeyServiceSelectPrefix + s.Key}
+		if s.Button == nil || len(groups) >= wecom.MaxMenuButtons-1 {
+			entries = append(entries, entry)
+			continue
+		}
+		b := *s.Button
+		if len(b.SubButtons) > wecom.MaxMenuSubButtons {
+			slog.Warn("服务菜单子按钮超出上限，已截断",
+				"service", s.Key,
+				"sub_buttons", len(b.SubButtons),
+				"max", wecom.MaxMenuSubButtons,
+			)
+			b.SubButtons = b.SubButtons[:wecom.MaxMenuSubButtons]
+		}
+		groups = append(groups, b)
+	}
+
+	// “常用”名额不足时优先保留服务入口：依次去掉“自检”“帮助”，仍不足再截断入口。
+	extras := []wecom.MenuButton{
+		{Type: "click", Name: "自检", Key: wecom.EventKeyCoreSelfTest},
+		{Type: "click", Name: "帮助", Key: wecom.EventKeyCoreHelp},
+	}
+	room := wecom.MaxMenuSubButtons - len(common.SubButtons) - len(entries)
+	if room < 0 {
+		var dropped []string
+		for _, b := range entries[len(entries)+room:] {
+			dropped = append(dropped, b.Name)
+		}
+		slog.Warn("“常用”菜单入口超出上限，已截断", "dropped", strings.Join(dropped, ","))
+		entries = entries[:len(entries)+room]
+		room = 0
+	}
+	if room < len(extras) {
+		extras = extras[len(extras)-room:]
+	}
+	common.SubButtons = append(common.SubButtons, entries...)
+	common.SubButtons = append(common.SubButtons, extras...)
+
+	return wecom.Menu{Buttons: append([]wecom.MenuButton{common}, groups...)}
+}
+
+// Menu 返回“同步菜单”使用的应用自定义菜单：配置覆盖优先，否则按已启用的服务生成。
+func (r *Router) Menu() wecom.Menu {
+	if len(r.menu.Buttons) > 0 {
+		return r.menu
+	}
+	services := make([]MenuService, 0, len(r.providerList))
+	for _, p := range r.providerList {
+		services = append(services, MenuServiceOf(p))
+	}
+	return BuildMenu(services)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+type menuProvider struct {
+	fakeProvider
+	button wecom.MenuButton
+}
+
+func (p *menuProvider) MenuButton() wecom.MenuButton { return p.button }
+
+func TestBuildMenu_FromProviders(t *testing.T) {
+	t.Parallel()
+
+	group := func(name string, subs int) wecom.MenuButton {
+		b := wecom.MenuButton{Name: name}
+		for i := 0; i < subs; i++ {
+			b.SubButtons = append(b.SubButtons, wecom.MenuButton{Type: "click", Name: name, Key: name})
+		}
+		return b
+	}
+
+	// 未启用任何服务：仅“常用”。
+	menu := BuildMenu(nil)
+	if len(menu.Buttons) != 1 || len(menu.Buttons[0].SubButtons) != 3 {
+		t.Fatalf("BuildMenu(nil) = %+v, want 常用 with 3 sub buttons", menu)
+	}
+
+	var services []MenuService
+	for _, p := range []ServiceProvider{
+		&menuProvider{fakeProvider: fakeProvider{key: "a", name: "A"}, button: group("A", 7)},
+		&fakeProvider{key: "plain", name: "Plain"},
+		&menuProvider{fakeProvider: fakeProvider{key: "b", name: "B"}, button: group("B", 1)},
+		&menuProvider{fakeProvider: fakeProvider{key: "c", name: "C"}, button: group("C", 1)},
+		&fakeProvider{key: "d", name: "D"},
+	} {
+		services = append(services, MenuServiceOf(p))
+	}
+	menu = BuildMenu(services)
+	if err := menu.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if len(menu.Buttons) != 3 || menu.Buttons[1].Name != "A" || menu.Buttons[2].Name != "B" {
+		t.Fatalf("top buttons = %+v, want 常用/A/B", menu.Buttons)
+	}
+	if got := len(menu.Buttons[1].SubButtons); got != wecom.MaxMenuSubButtons {
+		t.Fatalf("A sub buttons = %d, want truncated to %d", got, wecom.MaxMenuSubButtons)
+	}
+	var keys []string
+	for _, b := range menu.Buttons[0].SubButtons {
+		keys = append(keys, b.Key)
+	}
+	want := []string{wecom.EventKeyCoreMenu, "svc.select.plain", "svc.select.c", "svc.select.d", wecom.EventKeyCoreHelp}
+	if len(keys) != len(want) {
+		t.Fatalf("常用 keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("常用 keys = %v, want %v", keys, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+// notify.go 定义广播型通知出口（群聊等），用于告警与操作播报投递到共享的运维群。
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// 通知类别：用于按类别选择投递的群聊。
+const (
+	// NotifyCategoryAlert 为各类告警的默认类别（具体类别未单独配置时使用）。
+	NotifyCategoryAlert       = "alert"
+	NotifyCategoryUnraidAlert = "unraid_alert"
+	NotifyCategoryUnraidUPS   = "unraid_ups"
+	NotifyCategoryPVEAlert    = "pve_alert"
+	// NotifyCategoryAnnounce 为操作播报（例如“某人重启了容器 X”）。
+	NotifyCategoryAnnounce = "announce"
+	// NotifyCategoryStartup 为服务启动成功通知。
+	NotifyCategoryStartup = "startup"
+)
+
+// Notifier 为广播型通知出口：与面向单个成员的 WeComSender 不同，一次调用即投递给整个群。
+type Notifier interface {
+	Notify(ctx context.Context, content string) error
+}
+
+// AppChatSender 为可选能力：应用群聊推送（appchat/send）。
+type AppChatSender interface {
+	SendAppChat(ctx context.Context, msg wecom.AppChatMessage) error
+}
+
+// AppChatNotifier 将通知推送到指定的应用群聊。
+type AppChatNotifier struct {
+	Sender AppChatSender
+	ChatID string
+}
+
+func (n AppChatNotifier) Notify(ctx context.Context, content string) error {
+	if n.Sender == nil || strings.TrimSpace(n.ChatID) == "" {
+		return nil
+	}
+	return n.Sender.SendAppChat(ctx, wecom.AppChatMessage{ChatID: n.ChatID, Content: content})
+}
+
+// MultiNotifier 将同一通知投递到多个出口（群聊、群机器人等），任一失败不影响其余出口。
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(ctx context.Context, content string) error {
+	var errs []error
+	for _, n := range m {
+		if n == nil {
+			continue
+		}
+		if err := n.Notify(ctx, content); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// announceConfirm 在用户确认执行操作后向播报群发送一条操作播报（失败仅记录日志）。
+func (r *Router) announceConfirm(ctx context.Context, userID string, p ServiceProvider, state ConversationState) {
+	if r.announcer == nil || state.Action == "" {
+		return
+	}
+	content := fmt.Sprintf("📣 操作播报\n%s 确认执行：%s · %s", userID, p.DisplayName(), state.Action.DisplayName())
+	if target := describeStateTarget(state); target != "" {
+		content += " " + target
+	}
+	if err := r.announcer.Notify(ctx, content); err != nil {
+		slog.Warn("操作播报发送失败", "user_id", userID, "service", p.Key(), "action", string(state.Action), "error", err)
+	}
+}
+
+// describeStateTarget 从会话状态中提取待确认操作的目标（容器/容器组/虚拟机/任务/依赖）。
+func describeStateTarget(state ConversationState) string {
+	switch {
+	case state.ContainerName != "":
+		return state.ContainerName
+	case state.UnraidGroupID != "":
+		return "容器组 " + state.UnraidGroupID
+	case state.PVEGuestID > 0:
+		if state.PVEGuestName != "" {
+			return fmt.Sprintf("%s(%d)", state.PVEGuestName, state.PVEGuestID)
+		}
+		return fmt.Sprintf("%d", state.PVEGuestID)
+	case state.QinglongCronName != "":
+		return state.QinglongCronName
+	case state.CronID > 0:
+		return fmt.Sprintf("任务ID %d", state.CronID)
+	case state.QinglongDepName != "":
+		return state.QinglongDepName
+	default:
+		return ""
+	}
+}
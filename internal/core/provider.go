@@ -11,6 +11,14 @@ import (
 type WeComSender interface {
 	SendText(ctx context.Context, msg wecom.TextMessage) error
 	SendTemplateCard(ctx context.Context, msg wecom.TemplateCardMessage) error
+	SendMarkdown(ctx context.Context, msg wecom.MarkdownMessage) error
+}
+
+// FileSender 为可选能力：上传临时素材并以文件消息下发（用于脚本/配置等完整内容）。
+// Provider 通过类型断言探测，发送端不支持时应降级为文本提示。
+type FileSender interface {
+	UploadMedia(ctx context.Context, mediaType string, filename string, data []byte) (string, error)
+	SendFile(ctx context.Context, msg wecom.FileMessage) error
 }
 
 // ServiceProvider 定义一个可插拔服务处理器，用于承载不同后端服务的交互与执行逻辑。
@@ -18,6 +18,20 @@ type RouterDeps struct {
 	AllowedUserID map[string]struct{}
 	Providers     []ServiceProvider
 	State         *StateStore
+
+	// Announcer 为操作播报出口（可选）：用户确认执行操作后推送“谁执行了什么”。
+	Announcer Notifier
+
+	// Menu 为配置覆盖的应用自定义菜单（可选）；为空时由 BuildMenu 按 Providers 生成。
+	Menu wecom.Menu
+
+	// Members 为按部门/标签动态解析的授权成员（可选），与 AllowedUserID 取并集。
+	Members MemberChecker
+}
+
+// MemberChecker 判断成员是否已授权（例如 wecom.Directory 按部门/标签解析的成员）。
+type MemberChecker interface {
+	Contains(userID string) bool
 }
 
 type Router struct {
@@ -28,6 +42,9 @@ type Router struct {
 	providerList []ServiceProvider
 	providers    map[string]ServiceProvider
 	keywordIndex map[string]string
+	announcer    Notifier
+	menu         wecom.Menu
+	members      MemberChecker
 }
 
 type templateCardUpdater interface {
@@ -75,6 +92,9 @@ func NewRouter(deps RouterDeps) *Router {
 		providerList:  list,
 		providers:     providers,
 		keywordIndex:  keywordIndex,
+		announcer:     deps.Announcer,
+		menu:          deps.Menu,
+		members:       deps.Members,
 	}
 }
 
@@ -83,7 +103,7 @@ func (r *Router) HandleMessage(ctx context.Context, msg wecom.IncomingMessage) e
 	if userID == "" {
 		return nil
 	}
-	if _, ok := r.AllowedUserID[userID]; !ok {
+	if !r.isAllowed(userID) {
 		if err := r.WeCom.SendText(ctx, wecom.TextMessage{
 			ToUser:  userID,
 			Content: "无权限：该账号未加入白名单。",
@@ -119,26 +139,89 @@ func (r *Router) HandleMessage(ctx context.Context, msg wecom.IncomingMessage) e
 	}
 }
 
+// isAllowed 判断成员是否在白名单或已解析的部门/标签成员中。
+func (r *Router) isAllowed(userID string) bool {
+	if _, ok := r.AllowedUserID[userID]; ok {
+		return true
+	}
+	return r.members != nil && r.members.Contains(userID)
+}
+
+// PassiveReply 为自检/帮助等可立即答复的文本命令返回被动回复内容（实现 wecom.PassiveReplier），
+// 其余消息（含需要调用后端的操作）仍由 HandleMessage 主动发送。
+func (r *Router) PassiveReply(_ context.Context, msg wecom.IncomingMessage) (string, bool) {
+	userID := strings.TrimSpace(msg.FromUserName)
+	if userID == "" || msg.MsgType != "text" {
+		return "", false
+	}
+	if !r.isAllowed(userID) {
+		return "", false
+	}
+
+	keyword := normalizeCommandKeyword(msg.Content)
+	var content string
+	switch {
+	case isSelfTestKeyword(keyword):
+		content = buildSelfTestReply(msg)
+	case isHelpKeyword(keyword):
+		content = r.helpText()
+	default:
+		return "", false
+	}
+
+	// 与主动发送文本一致：新消息使之前的“回复序号”文本菜单失效。
+	if state, ok := r.state.Get(userID); ok && len(state.PendingButtons) > 0 {
+		state.PendingButtons = nil
+		r.state.Set(userID, state)
+	}
+	return content, true
+}
+
 func (r *Router) handleText(ctx context.Context, userID string, content string) error {
 	if content == "" {
 		return nil
 	}
 
-	if state, ok := r.state.Get(userID); ok && len(state.PendingButtons) > 0 {
-		// 文本兜底：用户回复“序号”触发同等 EventKey（避免模板卡片不展示导致交互中断）。
-		if idx, err := strconv.Atoi(strings.TrimSpace(content)); err == nil {
-			if idx >= 1 && idx <= len(state.PendingButtons) && (state.Step == "" || state.Step == StepAwaitingConfirm) {
-				key := strings.TrimSpace(state.PendingButtons[idx-1].Key)
-				state.PendingButtons = nil
-				r.state.Set(userID, state)
-				if key != "" {
-					return r.handleEvent(ctx, userID, wecom.IncomingMessage{
-						FromUserName: userID,
-						MsgType:      "event",
-						Event:        "click",
-						EventKey:     key,
+	if state, ok := r.state.Get(userID); ok && len(state.PendingButtons) > 0 && (state.Step == "" || state.Step == StepAwaitingConfirm) {
+		if isOptionMenu(state.PendingButtons) {
+			// 投票/多项选择卡片的文本兜底：回复“1,3,5”等同于勾选后点击提交。
+			if indexes, ok := parseIndexList(content); ok {
+				key, items, err := wecom.SelectedItemsFromButtons(state.PendingButtons, indexes)
+				if err != nil {
+					buttons := state.PendingButtons
+					sendErr := r.WeCom.SendText(ctx, wecom.TextMessage{
+						ToUser:  userID,
+						Content: "选择无效：" + err.Error() + "。请重新回复序号。",
 					})
+					// 发送文本会使菜单失效，这里恢复以便用户直接重新回复。
+					if cur, ok := r.state.Get(userID); ok {
+						cur.PendingButtons = buttons
+						r.state.Set(userID, cur)
+					}
+					return sendErr
 				}
+				state.PendingButtons = nil
+				r.state.Set(userID, state)
+				return r.handleEvent(ctx, userID, wecom.IncomingMessage{
+					FromUserName:  userID,
+					MsgType:       "event",
+					Event:         "template_card_event",
+					EventKey:      key,
+					SelectedItems: items,
+				})
+			}
+		} else if idx, err := strconv.Atoi(strings.TrimSpace(content)); err == nil && idx >= 1 && idx <= len(state.PendingButtons) {
+			// 文本兜底：用户回复“序号”触发同等 EventKey（避免模板卡片不展示导致交互中断）。
+			key := strings.TrimSpace(state.PendingButtons[idx-1].Key)
+			state.PendingButtons = nil
+			r.state.Set(userID, state)
+			if key != "" {
+				return r.handleEvent(ctx, userID, wecom.IncomingMessage{
+					FromUserName: userID,
+					MsgType:      "event",
+					Event:        "click",
+					EventKey:     key,
+				})
 			}
 		}
 	}
@@ -160,6 +243,7 @@ func (r *Router) handleText(ctx context.Context, userID string, content string)
 				return err
 			}
 			if handled {
+				r.announceConfirm(ctx, userID, p, state)
 				return nil
 			}
 		}
@@ -284,6 +368,7 @@ func (r *Router) handleEvent(ctx context.Context, userID string, msg wecom.Incom
 			return err
 		}
 		if handled {
+			r.announceConfirm(ctx, userID, p, state)
 			return nil
 		}
 		return r.WeCom.SendText(ctx, wecom.TextMessage{
@@ -433,6 +518,35 @@ func normalizeCommandKeyword(s string) string {
 	return normalizeKeyword(token)
 }
 
+// isOptionMenu 判断待选菜单是否来自投票/多项选择卡片（回复可包含多个序号）。
+func isOptionMenu(buttons []wecom.TemplateCardButton) bool {
+	return len(buttons) > 0 && buttons[0].OptionID != ""
+}
+
+// parseIndexList 解析“1,3,5”“回复 1 3”“1、3”等序号列表（去重并保持顺序）。
+func parseIndexList(content string) ([]int, bool) {
+	s := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "回复"))
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, false
+	}
+	seen := make(map[int]bool, len(fields))
+	out := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out, true
+}
+
 func isMenuKeyword(normalized string) bool {
 	switch normalized {
 	case "menu", "菜单":
@@ -501,6 +615,10 @@ func (r *Router) sendServiceUnavailable(ctx context.Context, userID string, serv
 }
 
 func (r *Router) sendHelp(ctx context.Context, userID string) error {
+	return r.WeCom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: r.helpText()})
+}
+
+func (r *Router) helpText() string {
 	services := make([]string, 0, len(r.providerList))
 	for _, p := range r.providerList {
 		if p == nil {
@@ -528,8 +646,7 @@ func (r *Router) sendHelp(ctx context.Context, userID string) error {
 		}
 	}
 	b.WriteString("\n\n提示：也可以直接点击应用底部自定义菜单触发常用操作。")
-
-	return r.WeCom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: b.String()})
+	return b.String()
 }
 
 func (r *Router) syncWeComMenu(ctx context.Context, userID string) error {
@@ -540,7 +657,7 @@ func (r *Router) syncWeComMenu(ctx context.Context, userID string) error {
 			Content: "当前发送端不支持同步菜单。",
 		})
 	}
-	if err := c.CreateMenu(ctx, wecom.DefaultMenu()); err != nil {
+	if err := c.CreateMenu(ctx, r.Menu()); err != nil {
 		return r.WeCom.SendText(ctx, wecom.TextMessage{
 			ToUser:  userID,
 			Content: "同步菜单失败：" + err.Error(),
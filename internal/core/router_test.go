@@ -13,6 +13,8 @@ import (
 type recordWeCom struct {
 	texts []wecom.TextMessage
 	cards []wecom.TemplateCardMessage
+
+	markdowns []wecom.MarkdownMessage
 }
 
 type recordWeComUpdater struct {
@@ -40,6 +42,11 @@ func (r *recordWeCom) SendTemplateCard(_ context.Context, msg wecom.TemplateCard
 	return nil
 }
 
+func (r *recordWeCom) SendMarkdown(_ context.Context, msg wecom.MarkdownMessage) error {
+	r.markdowns = append(r.markdowns, msg)
+	return nil
+}
+
 func (r *recordWeComUpdater) UpdateTemplateCardButton(_ context.Context, responseCode string, replaceName string) error {
 	r.updates = append(r.updates, templateCardUpdate{ResponseCode: responseCode, ReplaceName: replaceName})
 	return nil
@@ -63,6 +70,8 @@ type fakeProvider struct {
 	textHandled    bool
 	eventHandled   bool
 	confirmHandled bool
+
+	lastEvent wecom.IncomingMessage
 }
 
 func (p *fakeProvider) Key() string             { return p.key }
@@ -79,8 +88,9 @@ func (p *fakeProvider) HandleText(_ context.Context, _ string, _ string) (bool,
 	return p.textHandled, nil
 }
 
-func (p *fakeProvider) HandleEvent(_ context.Context, _ string, _ wecom.IncomingMessage) (bool, error) {
+func (p *fakeProvider) HandleEvent(_ context.Context, _ string, msg wecom.IncomingMessage) (bool, error) {
 	p.onEvent++
+	p.lastEvent = msg
 	return p.eventHandled, nil
 }
 
@@ -555,3 +565,180 @@ func TestRouter_SelfTestPing_AutoReplies(t *testing.T) {
 		t.Fatalf("template card count = %d, want 0", got)
 	}
 }
+
+type recordNotifier struct {
+	contents []string
+}
+
+func (n *recordNotifier) Notify(_ context.Context, content string) error {
+	n.contents = append(n.contents, content)
+	return nil
+}
+
+func TestRouter_Confirm_AnnouncesToNotifier(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordWeCom{}
+	announcer := &recordNotifier{}
+	userID := "u"
+	state := NewStateStore(1 * time.Minute)
+
+	unraid := &fakeProvider{key: "unraid", name: "Unraid 容器", confirmHandled: true}
+	r := NewRouter(RouterDeps{
+		WeCom:         rec,
+		AllowedUserID: map[string]struct{}{userID: {}},
+		Providers:     []ServiceProvider{unraid},
+		State:         state,
+		Announcer:     announcer,
+	})
+
+	state.Set(userID, ConversationState{
+		ServiceKey:    "unraid",
+		Step:          StepAwaitingConfirm,
+		Action:        ActionUnraidRestart,
+		ContainerName: "nginx",
+	})
+	if err := r.HandleMessage(context.Background(), wecom.IncomingMessage{
+		FromUserName: userID,
+		MsgType:      "text",
+		Content:      "确认",
+	}); err != nil {
+		t.Fatalf("HandleMessage() error: %v", err)
+	}
+	if len(announcer.contents) != 1 || !strings.Contains(announcer.contents[0], "u 确认执行：Unraid 容器 · 重启 nginx") {
+		t.Fatalf("announcements = %q", announcer.contents)
+	}
+
+	// 未处理的确认不播报。
+	unraid.confirmHandled = false
+	state.Set(userID, ConversationState{ServiceKey: "unraid", Step: StepAwaitingConfirm, Action: ActionUnraidStop})
+	_ = r.HandleMessage(context.Background(), wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "确认"})
+	if len(announcer.contents) != 1 {
+		t.Fatalf("announcements = %d, want 1", len(announcer.contents))
+	}
+}
+
+func TestRouter_PassiveReply_PingAndHelpOnly(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordWeCom{}
+	userID := "u"
+	state := NewStateStore(1 * time.Minute)
+	r := NewRouter(RouterDeps{
+		WeCom:         rec,
+		AllowedUserID: map[string]struct{}{userID: {}},
+		State:         state,
+	})
+	state.Set(userID, ConversationState{PendingButtons: []wecom.TemplateCardButton{{Text: "重启", Key: "k"}}})
+
+	ctx := context.Background()
+	content, ok := r.PassiveReply(ctx, wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "/ping", MsgID: "9"})
+	if !ok || !strings.HasPrefix(content, "pong\n") || !strings.Contains(content, "msg_id: 9") {
+		t.Fatalf("PassiveReply(ping) = %q, %v", content, ok)
+	}
+	if s, _ := state.Get(userID); len(s.PendingButtons) != 0 {
+		t.Fatalf("PendingButtons = %v, want cleared", s.PendingButtons)
+	}
+	if content, ok := r.PassiveReply(ctx, wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "帮助"}); !ok || !strings.Contains(content, "可用命令") {
+		t.Fatalf("PassiveReply(help) = %q, %v", content, ok)
+	}
+
+	for _, msg := range []wecom.IncomingMessage{
+		{FromUserName: userID, MsgType: "text", Content: "菜单"},
+		{FromUserName: userID, MsgType: "event", Event: "click", EventKey: wecom.EventKeyCoreHelp},
+		{FromUserName: "stranger", MsgType: "text", Content: "ping"},
+	} {
+		if content, ok := r.PassiveReply(ctx, msg); ok {
+			t.Fatalf("PassiveReply(%+v) = %q, want not handled", msg, content)
+		}
+	}
+	if len(rec.texts) != 0 || len(rec.cards) != 0 {
+		t.Fatalf("PassiveReply should not send actively: texts=%d cards=%d", len(rec.texts), len(rec.cards))
+	}
+}
+
+func TestRouter_VoteCardTextFallback_MultiSelectReply(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordWeCom{}
+	userID := "u"
+	state := NewStateStore(1 * time.Minute)
+	sender := NewTemplateCardSender(TemplateCardSenderDeps{Base: rec, State: state, Mode: TemplateCardModeText})
+	unraid := &fakeProvider{key: "unraid", name: "Unraid 容器", eventHandled: true}
+	r := NewRouter(RouterDeps{
+		WeCom:         sender,
+		AllowedUserID: map[string]struct{}{userID: {}},
+		Providers:     []ServiceProvider{unraid},
+		State:         state,
+	})
+
+	ctx := context.Background()
+	card := wecom.NewVoteInteractionCard(wecom.VoteCardOptions{
+		Title:       "批量重启",
+		QuestionKey: "containers",
+		Options:     []wecom.CardOption{{ID: "a", Text: "nginx"}, {ID: "b", Text: "redis"}, {ID: "c", Text: "db"}},
+		Multiple:    true,
+		SubmitKey:   "unraid.batch.restart",
+	})
+	if err := sender.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: card}); err != nil {
+		t.Fatalf("SendTemplateCard() error: %v", err)
+	}
+	if len(rec.texts) != 1 || !strings.Contains(rec.texts[0].Content, "回复 1,3,5") {
+		t.Fatalf("fallback texts = %v, want multi-select hint", rec.texts)
+	}
+
+	if err := r.HandleMessage(ctx, wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "9"}); err != nil {
+		t.Fatalf("HandleMessage(9) error: %v", err)
+	}
+	if unraid.onEvent != 0 || !strings.Contains(rec.texts[len(rec.texts)-1].Content, "序号超出范围") {
+		t.Fatalf("out of range reply = %q, events=%d", rec.texts[len(rec.texts)-1].Content, unraid.onEvent)
+	}
+
+	if err := r.HandleMessage(ctx, wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "1，3"}); err != nil {
+		t.Fatalf("HandleMessage(1,3) error: %v", err)
+	}
+	if unraid.onEvent != 1 {
+		t.Fatalf("unraid HandleEvent hits = %d, want 1", unraid.onEvent)
+	}
+	ev := unraid.lastEvent
+	if ev.Event != "template_card_event" || ev.EventKey != "unraid.batch.restart" {
+		t.Fatalf("event = %+v", ev)
+	}
+	if got := ev.SelectedOptions("containers"); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("SelectedOptions = %v, want [a c]", got)
+	}
+}
+
+type staticMembers map[string]bool
+
+func (m staticMembers) Contains(userID string) bool { return m[userID] }
+
+func TestRouter_MembersFromDirectoryAreAllowed(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordWeCom{}
+	r := NewRouter(RouterDeps{
+		WeCom:         rec,
+		AllowedUserID: map[string]struct{}{"listed": {}},
+		State:         NewStateStore(1 * time.Minute),
+		Members:       staticMembers{"dept-member": true},
+	})
+
+	ctx := context.Background()
+	for _, userID := range []string{"listed", "dept-member", "stranger"} {
+		if err := r.HandleMessage(ctx, wecom.IncomingMessage{FromUserName: userID, MsgType: "text", Content: "帮助"}); err != nil {
+			t.Fatalf("HandleMessage(%s) error: %v", userID, err)
+		}
+	}
+	if len(rec.texts) != 3 {
+		t.Fatalf("texts = %d, want 3", len(rec.texts))
+	}
+	for i, wantDenied := range []bool{false, false, true} {
+		if denied := strings.Contains(rec.texts[i].Content, "无权限"); denied != wantDenied {
+			t.Fatalf("reply[%d] to %s = %q, denied=%v want %v", i, rec.texts[i].ToUser, rec.texts[i].Content, denied, wantDenied)
+		}
+	}
+	if _, ok := r.PassiveReply(ctx, wecom.IncomingMessage{FromUserName: "dept-member", MsgType: "text", Content: "ping"}); !ok {
+		t.Fatalf("PassiveReply(dept-member) ok = false, want true")
+	}
+}
@@ -15,6 +15,12 @@ const (
 	StepAwaitingConfirm               Step = "awaiting_confirm"
 	StepAwaitingQinglongSearchKeyword Step = "awaiting_qinglong_search_keyword"
 	StepAwaitingQinglongCronID        Step = "awaiting_qinglong_cron_id"
+	// StepAwaitingQinglongScriptKeyword 表示等待输入青龙脚本文件名关键词（文件查看）。
+	StepAwaitingQinglongScriptKeyword Step = "awaiting_qinglong_script_keyword"
+	// StepAwaitingQinglongDepName 表示等待输入要安装的青龙依赖名称。
+	StepAwaitingQinglongDepName Step = "awaiting_qinglong_dep_name"
+	// StepAwaitingQinglongGlobalKeyword 表示等待输入跨实例搜索关键词（青龙多实例）。
+	StepAwaitingQinglongGlobalKeyword Step = "awaiting_qinglong_global_keyword"
 	StepAwaitingPVEGuestQuery         Step = "awaiting_pve_guest_query"
 
 	// StepAwaitingUnraidOpsAction 表示处于 Unraid “容器操作”菜单选择阶段（文本模式）。
@@ -23,24 +29,41 @@ const (
 	StepAwaitingUnraidViewAction Step = "awaiting_unraid_view_action"
 	// StepAwaitingUnraidSystemAction 表示处于 Unraid “系统监控”菜单选择阶段（文本模式）。
 	StepAwaitingUnraidSystemAction Step = "awaiting_unraid_system_action"
+	// StepAwaitingUnraidLogKeyword 表示等待输入 Unraid 系统日志的过滤关键词。
+	StepAwaitingUnraidLogKeyword Step = "awaiting_unraid_log_keyword"
 )
 
 type Action string
 
 const (
-	ActionUnraidRestart     Action = "restart"
-	ActionUnraidStop        Action = "stop"
-	ActionUnraidForceUpdate Action = "force_update"
+	ActionUnraidRestart      Action = "restart"
+	ActionUnraidStop         Action = "stop"
+	ActionUnraidForceUpdate  Action = "force_update"
+	ActionUnraidStart        Action = "start"
+	ActionUnraidPause        Action = "pause"
+	ActionUnraidUnpause      Action = "unpause"
+	ActionUnraidUpdateAll    Action = "update_all"
+	ActionUnraidShutdownPlan Action = "shutdown_plan"
+	ActionUnraidFlashBackup  Action = "flash_backup"
 
 	ActionUnraidViewStatus            Action = "view_status"
 	ActionUnraidViewSystemStats       Action = "view_system_stats"
 	ActionUnraidViewSystemStatsDetail Action = "view_system_stats_detail"
 	ActionUnraidViewLogs              Action = "view_logs"
+	ActionUnraidViewSyslog            Action = "view_syslog"
+	ActionUnraidViewStorage           Action = "view_storage"
+	ActionUnraidViewPlugins           Action = "view_plugins"
+	ActionUnraidViewTrend             Action = "view_trend"
+	ActionUnraidViewTop               Action = "view_top"
 
 	ActionQinglongRun     Action = "run"
 	ActionQinglongEnable  Action = "enable"
 	ActionQinglongDisable Action = "disable"
 
+	ActionQinglongDepInstall   Action = "dep_install"
+	ActionQinglongDepReinstall Action = "dep_reinstall"
+	ActionQinglongRunAll       Action = "run_all"
+
 	ActionPVEStart    Action = "pve_start"
 	ActionPVEShutdown Action = "pve_shutdown"
 	ActionPVEReboot   Action = "pve_reboot"
@@ -55,6 +78,12 @@ func ActionFromEventKey(key string) Action {
 		return ActionUnraidStop
 	case wecom.EventKeyUnraidForceUpdate:
 		return ActionUnraidForceUpdate
+	case wecom.EventKeyUnraidStart:
+		return ActionUnraidStart
+	case wecom.EventKeyUnraidPause:
+		return ActionUnraidPause
+	case wecom.EventKeyUnraidUnpause:
+		return ActionUnraidUnpause
 	case wecom.EventKeyUnraidViewStatus:
 		return ActionUnraidViewStatus
 	case wecom.EventKeyUnraidViewSystemStats:
@@ -63,6 +92,16 @@ func ActionFromEventKey(key string) Action {
 		return ActionUnraidViewSystemStatsDetail
 	case wecom.EventKeyUnraidViewLogs:
 		return ActionUnraidViewLogs
+	case wecom.EventKeyUnraidViewSyslog:
+		return ActionUnraidViewSyslog
+	case wecom.EventKeyUnraidViewStorage:
+		return ActionUnraidViewStorage
+	case wecom.EventKeyUnraidViewPlugins:
+		return ActionUnraidViewPlugins
+	case wecom.EventKeyUnraidViewTrend:
+		return ActionUnraidViewTrend
+	case wecom.EventKeyUnraidViewTop:
+		return ActionUnraidViewTop
 	default:
 		return ""
 	}
@@ -76,6 +115,18 @@ func (a Action) DisplayName() string {
 		return "停止"
 	case ActionUnraidForceUpdate:
 		return "强制更新"
+	case ActionUnraidStart:
+		return "启动"
+	case ActionUnraidPause:
+		return "暂停"
+	case ActionUnraidUnpause:
+		return "恢复"
+	case ActionUnraidUpdateAll:
+		return "全部更新"
+	case ActionUnraidShutdownPlan:
+		return "执行关机预案"
+	case ActionUnraidFlashBackup:
+		return "闪存备份"
 	case ActionUnraidViewStatus:
 		return "查看状态"
 	case ActionUnraidViewSystemStats:
@@ -84,12 +135,28 @@ func (a Action) DisplayName() string {
 		return "系统资源详情"
 	case ActionUnraidViewLogs:
 		return "查看日志"
+	case ActionUnraidViewSyslog:
+		return "系统日志"
+	case ActionUnraidViewPlugins:
+		return "插件与备份"
+	case ActionUnraidViewTrend:
+		return "资源趋势"
+	case ActionUnraidViewTop:
+		return "资源排行"
+	case ActionUnraidViewStorage:
+		return "存储空间"
 	case ActionQinglongRun:
 		return "运行"
 	case ActionQinglongEnable:
 		return "启用"
 	case ActionQinglongDisable:
 		return "禁用"
+	case ActionQinglongDepInstall:
+		return "安装依赖"
+	case ActionQinglongDepReinstall:
+		return "重装依赖"
+	case ActionQinglongRunAll:
+		return "全部实例运行"
 	case ActionPVEStart:
 		return "启动"
 	case ActionPVEShutdown:
@@ -106,7 +173,9 @@ func (a Action) DisplayName() string {
 func (a Action) RequiresConfirm() bool {
 	switch a {
 	case ActionUnraidRestart, ActionUnraidStop, ActionUnraidForceUpdate,
+		ActionUnraidStart, ActionUnraidPause, ActionUnraidUnpause, ActionUnraidUpdateAll, ActionUnraidShutdownPlan, ActionUnraidFlashBackup,
 		ActionQinglongRun, ActionQinglongEnable, ActionQinglongDisable,
+		ActionQinglongDepInstall, ActionQinglongDepReinstall, ActionQinglongRunAll,
 		ActionPVEStart, ActionPVEShutdown, ActionPVEReboot, ActionPVEStop:
 		return true
 	default:
@@ -127,6 +196,25 @@ type ConversationState struct {
 	PVEGuestName  string
 	PVENode       string
 
+	// UnraidLogPath/UnraidLogStartLine 记录 Unraid 系统日志当前查看的文件与窗口起始行（用于向前翻页）。
+	UnraidLogPath      string
+	UnraidLogStartLine int
+
+	// UnraidGroupID 记录当前选中的 Unraid 容器组（批量操作确认时使用）。
+	UnraidGroupID string
+
+	// QinglongFileKind/QinglongFilePath 记录青龙“文件查看”当前选中的文件（script/config + 相对路径）。
+	QinglongFileKind string
+	QinglongFilePath string
+
+	// QinglongDepType/QinglongDepID/QinglongDepName 记录青龙依赖管理的待确认目标（类型 + 重装ID 或 安装名称）。
+	QinglongDepType string
+	QinglongDepID   int
+	QinglongDepName string
+
+	// QinglongCronName 记录跨实例运行的目标任务名（按名称精确匹配各实例任务）。
+	QinglongCronName string
+
 	// PendingButtons 用于模板卡片(button_interaction)的文本兜底：当用户回复“序号”时，映射到对应的 EventKey。
 	PendingButtons []wecom.TemplateCardButton
 
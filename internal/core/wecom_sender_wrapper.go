@@ -16,25 +16,39 @@ const (
 	TemplateCardModeText         TemplateCardMode = "text"
 )
 
+// MarkdownMode 控制 markdown 消息的发送方式。
+type MarkdownMode string
+
+const (
+	MarkdownModeMarkdown MarkdownMode = "markdown"
+	MarkdownModeText     MarkdownMode = "text"
+)
+
 type TemplateCardSenderDeps struct {
 	Base  WeComSender
 	State *StateStore
 	Mode  TemplateCardMode
+
+	// MarkdownMode 为 text 时 markdown 消息降级为纯文本发送（例如使用微信插件/微工作台的成员无法渲染 markdown）。
+	// 未配置时跟随 Mode：Mode=text 视为客户端能力受限，同样降级为文本。
+	MarkdownMode MarkdownMode
 }
 
 // TemplateCardSender 为模板卡片提供“文本兜底 + 序号选择”能力。
 // 典型用途：企业微信客户端不支持展示模板卡片时（官方注明微工作台不支持，且存在客户端版本门槛），仍可通过文本完成交互。
 type TemplateCardSender struct {
-	base  WeComSender
-	state *StateStore
-	mode  TemplateCardMode
+	base         WeComSender
+	state        *StateStore
+	mode         TemplateCardMode
+	markdownMode MarkdownMode
 }
 
 func NewTemplateCardSender(deps TemplateCardSenderDeps) *TemplateCardSender {
 	return &TemplateCardSender{
-		base:  deps.Base,
-		state: deps.State,
-		mode:  deps.Mode,
+		base:         deps.Base,
+		state:        deps.State,
+		mode:         deps.Mode,
+		markdownMode: deps.MarkdownMode,
 	}
 }
 
@@ -73,40 +87,90 @@ func (s *TemplateCardSender) SendTemplateCard(ctx context.Context, msg wecom.Tem
 			return err
 		}
 		if ok {
-			return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, Content: text})
+			return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, ToParty: msg.ToParty, ToTag: msg.ToTag, Content: text})
 		}
 		return nil
 	case TemplateCardModeText:
 		if ok {
-			return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, Content: text})
+			return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, ToParty: msg.ToParty, ToTag: msg.ToTag, Content: text})
 		}
-		return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, Content: "（模板卡片已切换为文本模式，但当前卡片无法渲染为文本菜单）"})
+		return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, ToParty: msg.ToParty, ToTag: msg.ToTag, Content: "（模板卡片已切换为文本模式，但当前卡片无法渲染为文本菜单）"})
 	default:
 		return s.base.SendTemplateCard(ctx, msg)
 	}
 }
 
-func (s *TemplateCardSender) clearPendingButtons(userID string) {
-	userID = strings.TrimSpace(userID)
-	if userID == "" || s.state == nil {
-		return
+// SendMarkdown 发送 markdown 消息；客户端不支持时（见 MarkdownMode）降级为纯文本。
+func (s *TemplateCardSender) SendMarkdown(ctx context.Context, msg wecom.MarkdownMessage) error {
+	if s.base == nil {
+		return errors.New("wecom sender: base 为空")
+	}
+	s.clearPendingButtons(msg.ToUser)
+
+	mode := MarkdownMode(strings.ToLower(strings.TrimSpace(string(s.markdownMode))))
+	if mode == "" && TemplateCardMode(strings.ToLower(strings.TrimSpace(string(s.mode)))) == TemplateCardModeText {
+		mode = MarkdownModeText
+	}
+	if mode == MarkdownModeText {
+		return s.base.SendText(ctx, wecom.TextMessage{ToUser: msg.ToUser, Content: wecom.MarkdownToText(msg.Content)})
+	}
+	return s.base.SendMarkdown(ctx, msg)
+}
+
+func (s *TemplateCardSender) UploadMedia(ctx context.Context, mediaType string, filename string, data []byte) (string, error) {
+	uploader, ok := s.base.(interface {
+		UploadMedia(ctx context.Context, mediaType string, filename string, data []byte) (string, error)
+	})
+	if !ok {
+		return "", errors.New("UploadMedia not supported")
+	}
+	return uploader.UploadMedia(ctx, mediaType, filename, data)
+}
+
+func (s *TemplateCardSender) SendFile(ctx context.Context, msg wecom.FileMessage) error {
+	sender, ok := s.base.(interface {
+		SendFile(ctx context.Context, msg wecom.FileMessage) error
+	})
+	if !ok {
+		return errors.New("SendFile not supported")
 	}
-	state, ok := s.state.Get(userID)
-	if !ok || len(state.PendingButtons) == 0 {
+	s.clearPendingButtons(msg.ToUser)
+	return sender.SendFile(ctx, msg)
+}
+
+// clearPendingButtons/setPendingButtons 按成员维护文本兜底的待选按钮；toUser 可为“|”分隔的多个 userid。
+// 仅经 toparty/totag 收到的成员无法逐人记录，只能使用卡片按钮。
+func (s *TemplateCardSender) clearPendingButtons(toUser string) {
+	if s.state == nil {
 		return
 	}
-	state.PendingButtons = nil
-	s.state.Set(userID, state)
+	for _, userID := range strings.Split(toUser, "|") {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		state, ok := s.state.Get(userID)
+		if !ok || len(state.PendingButtons) == 0 {
+			continue
+		}
+		state.PendingButtons = nil
+		s.state.Set(userID, state)
+	}
 }
 
-func (s *TemplateCardSender) setPendingButtons(userID string, buttons []wecom.TemplateCardButton) {
-	userID = strings.TrimSpace(userID)
-	if userID == "" || s.state == nil || len(buttons) == 0 {
+func (s *TemplateCardSender) setPendingButtons(toUser string, buttons []wecom.TemplateCardButton) {
+	if s.state == nil || len(buttons) == 0 {
 		return
 	}
-	state, _ := s.state.Get(userID)
-	state.PendingButtons = buttons
-	s.state.Set(userID, state)
+	for _, userID := range strings.Split(toUser, "|") {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		state, _ := s.state.Get(userID)
+		state.PendingButtons = buttons
+		s.state.Set(userID, state)
+	}
 }
 
 func (s *TemplateCardSender) UpdateTemplateCardButton(ctx context.Context, responseCode string, replaceName string) error {
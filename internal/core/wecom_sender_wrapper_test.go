@@ -94,3 +94,36 @@ func TestTemplateCardSender_SendText_ClearsPendingButtons(t *testing.T) {
 		t.Fatalf("pending buttons not cleared")
 	}
 }
+
+func TestTemplateCardSender_SendMarkdown_FallsBackToText(t *testing.T) {
+	t.Parallel()
+
+	msg := wecom.MarkdownMessage{ToUser: "u", Content: "### 概览\nCPU " + wecom.MarkdownColor(wecom.MarkdownColorWarning, "95%")}
+	cases := []struct {
+		name         string
+		mode         TemplateCardMode
+		markdownMode MarkdownMode
+		wantMarkdown bool
+	}{
+		{"default", TemplateCardModeTemplateCard, "", true},
+		{"follow text card mode", TemplateCardModeText, "", false},
+		{"explicit text", TemplateCardModeBoth, MarkdownModeText, false},
+		{"explicit markdown", TemplateCardModeText, MarkdownModeMarkdown, true},
+	}
+	for _, tc := range cases {
+		base := &recordWeCom{}
+		sender := NewTemplateCardSender(TemplateCardSenderDeps{Base: base, Mode: tc.mode, MarkdownMode: tc.markdownMode})
+		if err := sender.SendMarkdown(context.Background(), msg); err != nil {
+			t.Fatalf("%s: SendMarkdown() error: %v", tc.name, err)
+		}
+		if tc.wantMarkdown {
+			if len(base.markdowns) != 1 || len(base.texts) != 0 {
+				t.Fatalf("%s: markdowns=%d texts=%d", tc.name, len(base.markdowns), len(base.texts))
+			}
+			continue
+		}
+		if len(base.markdowns) != 0 || len(base.texts) != 1 || base.texts[0].Content != "【概览】\nCPU 95%" {
+			t.Fatalf("%s: markdowns=%d texts=%+v", tc.name, len(base.markdowns), base.texts)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package pve
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strings"
 	"sync"
@@ -26,15 +27,24 @@ type AlertConfig struct {
 }
 
 type AlertManagerDeps struct {
-	WeCom     core.WeComSender
-	UserIDs   []string
+	WeCom   core.WeComSender
+	UserIDs []string
+	// ToParty/ToTag 为按部门/标签直接投递的接收者（“|”分隔，可选），一条消息覆盖全部成员。
+	ToParty   string
+	ToTag     string
 	Instances []Instance
 	Config    AlertConfig
+
+	// Broadcast 为可选的广播出口（例如运维群聊）；配置后告警同时推送到该出口。
+	Broadcast core.Notifier
 }
 
 type AlertManager struct {
-	wecom   core.WeComSender
-	userIDs []string
+	wecom     core.WeComSender
+	userIDs   []string
+	toParty   string
+	toTag     string
+	broadcast core.Notifier
 
 	cfg       AlertConfig
 	instances map[string]Instance
@@ -69,6 +79,9 @@ func NewAlertManager(deps AlertManagerDeps) *AlertManager {
 	return &AlertManager{
 		wecom:      deps.WeCom,
 		userIDs:    userIDs,
+		toParty:    strings.TrimSpace(deps.ToParty),
+		toTag:      strings.TrimSpace(deps.ToTag),
+		broadcast:  deps.Broadcast,
 		cfg:        deps.Config,
 		instances:  instances,
 		order:      order,
@@ -88,7 +101,7 @@ func (m *AlertManager) Config() AlertConfig {
 }
 
 func (m *AlertManager) Start() {
-	if m == nil || !m.cfg.Enabled || m.wecom == nil || len(m.userIDs) == 0 || len(m.order) == 0 {
+	if m == nil || !m.cfg.Enabled || m.wecom == nil || (len(m.userIDs) == 0 && m.toParty == "" && m.toTag == "" && m.broadcast == nil) || len(m.order) == 0 {
 		return
 	}
 	m.startOnce.Do(func() {
@@ -355,12 +368,20 @@ func (m *AlertManager) sendIfNotInCooldown(ctx context.Context, ins Instance, ki
 	m.lastSent[key] = now
 	m.mu.Unlock()
 
-	for _, userID := range m.userIDs {
+	// 单条消息同时投递成员/部门/标签，企业微信按 userid 去重，避免同时命中白名单与部门的成员收到两次。
+	if len(m.userIDs) > 0 || m.toParty != "" || m.toTag != "" {
 		_ = m.wecom.SendText(ctx, wecom.TextMessage{
-			ToUser:  userID,
+			ToUser:  strings.Join(m.userIDs, "|"),
+			ToParty: m.toParty,
+			ToTag:   m.toTag,
 			Content: content,
 		})
 	}
+	if m.broadcast != nil {
+		if err := m.broadcast.Notify(ctx, content); err != nil {
+			slog.Error("pve 告警广播失败", "instance", ins.ID, "kind", kind.String(), "error", err)
+		}
+	}
 }
 
 func (k alertKind) String() string { return string(k) }
@@ -98,6 +98,24 @@ func (c *Client) GuestAction(ctx context.Context, node string, guestType GuestTy
 	return upid, nil
 }
 
+// ShutdownGuestByVMID 通过集群资源查找 vmid 所在节点与类型，并发起 shutdown（优雅关机）。
+func (c *Client) ShutdownGuestByVMID(ctx context.Context, vmid int) (string, error) {
+	resources, err := c.ListClusterResources(ctx, "vm")
+	if err != nil {
+		return "", err
+	}
+	for _, r := range resources {
+		if r.VMID != vmid {
+			continue
+		}
+		if r.Status == "stopped" {
+			return "", nil
+		}
+		return c.GuestAction(ctx, r.Node, GuestType(r.Type), vmid, GuestActionShutdown)
+	}
+	return "", fmt.Errorf("未找到虚拟机/容器：%d", vmid)
+}
+
 func (c *Client) GetTaskStatus(ctx context.Context, node string, upid string) (TaskStatus, error) {
 	node = strings.TrimSpace(node)
 	upid = strings.TrimSpace(upid)
@@ -66,14 +66,43 @@ func NewProvider(deps ProviderDeps) *Provider {
 	}
 }
 
-func (p *Provider) Key() string { return "pve" }
+// 服务 key 与显示名（Provider 与包级 MenuService 共用）。
+const (
+	serviceKey         = "pve"
+	serviceDisplayName = "PVE"
+)
+
+func (p *Provider) Key() string { return serviceKey }
 
-func (p *Provider) DisplayName() string { return "PVE" }
+func (p *Provider) DisplayName() string { return serviceDisplayName }
 
 func (p *Provider) EntryKeywords() []string {
 	return []string{"pve", "proxmox"}
 }
 
+// MenuButton 贡献应用自定义菜单中的 PVE 分组（实现 core.MenuContributor）。
+// 概览/虚拟机/LXC 作用于当前实例，未选择实例时先进入实例选择。
+func (p *Provider) MenuButton() wecom.MenuButton { return menuButton() }
+
+// MenuService 返回菜单组装所需的服务描述，无需创建 Provider（供 --wecom-sync-menu 使用）。
+func MenuService() core.MenuService {
+	b := menuButton()
+	return core.MenuService{Key: serviceKey, DisplayName: serviceDisplayName, Button: &b}
+}
+
+func menuButton() wecom.MenuButton {
+	return wecom.MenuButton{
+		Name: "PVE",
+		SubButtons: []wecom.MenuButton{
+			{Type: "click", Name: "进入PVE", Key: wecom.EventKeyServiceSelectPrefix + serviceKey},
+			{Type: "click", Name: "资源概览", Key: wecom.EventKeyPVEActionOverview},
+			{Type: "click", Name: "虚拟机", Key: wecom.EventKeyPVEActionVMMenu},
+			{Type: "click", Name: "LXC 容器", Key: wecom.EventKeyPVEActionLXCMenu},
+			{Type: "click", Name: "告警状态", Key: wecom.EventKeyPVEActionAlertStatus},
+		},
+	}
+}
+
 func (p *Provider) OnEnter(ctx context.Context, userID string) error {
 	if len(p.order) == 0 {
 		return p.wecom.SendText(ctx, wecom.TextMessage{
@@ -507,13 +536,13 @@ func (p *Provider) sendOverview(ctx context.Context, userID string, ins Instance
 	})
 
 	var b strings.Builder
-	b.WriteString("PVE 资源概览")
+	b.WriteString("### PVE 资源概览")
 	if strings.TrimSpace(ins.Name) != "" {
 		b.WriteString("（")
 		b.WriteString(strings.TrimSpace(ins.Name))
 		b.WriteString("）")
 	}
-	b.WriteString("\n\n节点：")
+	b.WriteString("\n" + wecom.MarkdownBold("节点"))
 	for i, n := range nodes {
 		if i >= 8 {
 			break
@@ -531,10 +560,15 @@ func (p *Provider) sendOverview(ctx context.Context, userID string, ins Instance
 		if status == "" {
 			status = "unknown"
 		}
-		b.WriteString(fmt.Sprintf("\n- %s [%s] CPU %.0f%% MEM %.0f%%", name, status, cpu, mem))
+		statusColor := wecom.MarkdownColorInfo
+		if status != "online" {
+			statusColor = wecom.MarkdownColorWarning
+		}
+		b.WriteString(fmt.Sprintf("\n- %s [%s] CPU %s MEM %s", name, wecom.MarkdownColor(statusColor, status),
+			markdownPercent(cpu, p.alertCfg.CPUUsageThreshold), markdownPercent(mem, p.alertCfg.MemUsageThreshold)))
 	}
 
-	b.WriteString("\n\n存储：")
+	b.WriteString("\n\n" + wecom.MarkdownBold("存储"))
 	for i, s := range storages {
 		if i >= 8 {
 			break
@@ -550,10 +584,22 @@ func (p *Provider) sendOverview(ctx context.Context, userID string, ins Instance
 		if name == "" {
 			name = "(unknown)"
 		}
-		b.WriteString(fmt.Sprintf("\n- %s %.0f%%", name, usage))
+		b.WriteString(fmt.Sprintf("\n- %s %s", name, markdownPercent(usage, p.alertCfg.StorageUsageThreshold)))
 	}
 
-	return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: b.String()})
+	return p.wecom.SendMarkdown(ctx, wecom.MarkdownMessage{ToUser: userID, Content: b.String()})
+}
+
+// markdownPercent 渲染百分比：达到告警阈值（未配置时按 90%）显示为警示色。
+func markdownPercent(v float64, threshold float64) string {
+	if threshold <= 0 {
+		threshold = 90
+	}
+	text := fmt.Sprintf("%.0f%%", v)
+	if v >= threshold {
+		return wecom.MarkdownColor(wecom.MarkdownColorWarning, text)
+	}
+	return text
 }
 
 func (p *Provider) sendAlertStatus(ctx context.Context, userID string, ins Instance) error {
@@ -18,6 +18,8 @@ type recordWeCom struct {
 	mu    sync.Mutex
 	texts []wecom.TextMessage
 	cards []wecom.TemplateCardMessage
+
+	markdowns []wecom.MarkdownMessage
 }
 
 func (r *recordWeCom) SendText(_ context.Context, msg wecom.TextMessage) error {
@@ -34,6 +36,13 @@ func (r *recordWeCom) SendTemplateCard(_ context.Context, msg wecom.TemplateCard
 	return nil
 }
 
+func (r *recordWeCom) SendMarkdown(_ context.Context, msg wecom.MarkdownMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.markdowns = append(r.markdowns, msg)
+	return nil
+}
+
 func (r *recordWeCom) Texts() []wecom.TextMessage {
 	r.mu.Lock()
 	defer r.mu.Unlock()
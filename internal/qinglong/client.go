@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -143,6 +144,235 @@ func (c *Client) GetCronLog(ctx context.Context, id int) (string, error) {
 	return out, nil
 }
 
+// ScriptNode 对应 /open/scripts 返回的脚本目录树节点（type=file/directory）。
+type ScriptNode struct {
+	Title    string       `json:"title"`
+	Key      string       `json:"key"`
+	Parent   string       `json:"parent"`
+	Type     string       `json:"type"`
+	Children []ScriptNode `json:"children"`
+}
+
+// ScriptFile 为扁平化后的脚本文件（Dir 为相对 scripts 根目录的父目录，根目录为空）。
+type ScriptFile struct {
+	Dir  string
+	Name string
+}
+
+// FullPath 返回 “目录/文件名” 形式的相对路径。
+func (f ScriptFile) FullPath() string {
+	if strings.TrimSpace(f.Dir) == "" {
+		return f.Name
+	}
+	return strings.TrimRight(f.Dir, "/") + "/" + f.Name
+}
+
+// ConfigFile 对应 /open/configs/files 返回的配置文件条目。
+type ConfigFile struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// ListScripts 获取脚本目录树（需 OpenAPI scripts 权限）。
+func (c *Client) ListScripts(ctx context.Context) ([]ScriptNode, error) {
+	var out []ScriptNode
+	if err := c.do(ctx, http.MethodGet, "/open/scripts", nil, nil, &out, true); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListScriptFiles 获取脚本文件列表（目录树扁平化，按路径排序）。
+func (c *Client) ListScriptFiles(ctx context.Context) ([]ScriptFile, error) {
+	nodes, err := c.ListScripts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := FlattenScriptFiles(nodes)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].FullPath() < files[j].FullPath() })
+	return files, nil
+}
+
+// GetScript 获取脚本文件内容。
+func (c *Client) GetScript(ctx context.Context, file ScriptFile) (string, error) {
+	if strings.TrimSpace(file.Name) == "" {
+		return "", errors.New("脚本文件名不能为空")
+	}
+
+	q := url.Values{}
+	q.Set("file", file.Name)
+	q.Set("path", file.Dir)
+
+	var out string
+	if err := c.do(ctx, http.MethodGet, "/open/scripts/detail", q, nil, &out, true); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// ListConfigFiles 获取配置文件列表（需 OpenAPI configs 权限）。
+func (c *Client) ListConfigFiles(ctx context.Context) ([]ConfigFile, error) {
+	var out []ConfigFile
+	if err := c.do(ctx, http.MethodGet, "/open/configs/files", nil, nil, &out, true); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetConfigFile 获取配置文件内容（例如 config.sh）。
+func (c *Client) GetConfigFile(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("配置文件名不能为空")
+	}
+
+	q := url.Values{}
+	q.Set("path", name)
+
+	var out string
+	if err := c.do(ctx, http.MethodGet, "/open/configs/detail", q, nil, &out, true); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// FlattenScriptFiles 将脚本目录树扁平化为文件列表（忽略目录节点本身）。
+func FlattenScriptFiles(nodes []ScriptNode) []ScriptFile {
+	var out []ScriptFile
+	var walk func(dir string, list []ScriptNode)
+	walk = func(dir string, list []ScriptNode) {
+		for _, n := range list {
+			title := strings.TrimSpace(n.Title)
+			if title == "" {
+				continue
+			}
+			if n.Type == "directory" || len(n.Children) > 0 {
+				sub := title
+				if dir != "" {
+					sub = dir + "/" + title
+				}
+				walk(sub, n.Children)
+				continue
+			}
+			out = append(out, ScriptFile{Dir: dir, Name: title})
+		}
+	}
+	walk("", nodes)
+	return out
+}
+
+// SystemInfo 对应 /open/system 返回的面板信息（仅保留展示所需字段）。
+type SystemInfo struct {
+	Version     string `json:"version"`
+	Branch      string `json:"branch"`
+	PublishTime int64  `json:"publishTime"`
+	ChangeLog   string `json:"changeLog"`
+}
+
+// SystemUpdateInfo 对应 /open/system/update-check 返回的更新检查结果。
+type SystemUpdateInfo struct {
+	HasNewVersion bool   `json:"hasNewVersion"`
+	LastVersion   string `json:"lastVersion"`
+	LastLog       string `json:"lastLog"`
+}
+
+// GetSystemInfo 获取面板版本等系统信息（需 OpenAPI system 权限）。
+func (c *Client) GetSystemInfo(ctx context.Context) (SystemInfo, error) {
+	var out SystemInfo
+	if err := c.do(ctx, http.MethodGet, "/open/system", nil, nil, &out, true); err != nil {
+		return SystemInfo{}, err
+	}
+	return out, nil
+}
+
+// CheckSystemUpdate 检查面板是否有新版本（仅检查，不执行更新）。
+func (c *Client) CheckSystemUpdate(ctx context.Context) (SystemUpdateInfo, error) {
+	var out SystemUpdateInfo
+	if err := c.do(ctx, http.MethodPut, "/open/system/update-check", nil, nil, &out, true); err != nil {
+		return SystemUpdateInfo{}, err
+	}
+	return out, nil
+}
+
+// 依赖类型（与青龙 DependenceTypes 对应：nodejs=0, python3=1, linux=2）。
+const (
+	DependencyTypeNodeJS  = "nodejs"
+	DependencyTypePython3 = "python3"
+	DependencyTypeLinux   = "linux"
+)
+
+// DependencyTypeIndex 返回依赖类型在青龙接口中的数值表示；未知类型返回 -1。
+func DependencyTypeIndex(depType string) int {
+	switch depType {
+	case DependencyTypeNodeJS:
+		return 0
+	case DependencyTypePython3:
+		return 1
+	case DependencyTypeLinux:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// 依赖状态（与青龙 DependenceStatus 对应）。
+const (
+	DependencyStatusInstalling    = 0
+	DependencyStatusInstalled     = 1
+	DependencyStatusInstallFailed = 2
+	DependencyStatusRemoving      = 3
+	DependencyStatusRemoved       = 4
+	DependencyStatusRemoveFailed  = 5
+	DependencyStatusQueued        = 6
+	DependencyStatusCancelled     = 7
+)
+
+type Dependency struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Type   int    `json:"type"`
+	Status int    `json:"status"`
+	Remark string `json:"remark"`
+}
+
+// ListDependencies 获取指定类型的依赖列表（depType: nodejs/python3/linux）。
+func (c *Client) ListDependencies(ctx context.Context, depType string) ([]Dependency, error) {
+	if DependencyTypeIndex(depType) < 0 {
+		return nil, fmt.Errorf("依赖类型不合法: %s", depType)
+	}
+
+	q := url.Values{}
+	q.Set("type", depType)
+
+	var out []Dependency
+	if err := c.do(ctx, http.MethodGet, "/open/dependencies", q, nil, &out, true); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InstallDependency 新增并安装依赖（青龙会异步排队安装）。
+func (c *Client) InstallDependency(ctx context.Context, depType string, name string) error {
+	typ := DependencyTypeIndex(depType)
+	if typ < 0 {
+		return fmt.Errorf("依赖类型不合法: %s", depType)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("依赖名称不能为空")
+	}
+
+	body := []map[string]interface{}{
+		{"name": name, "type": typ},
+	}
+	return c.do(ctx, http.MethodPost, "/open/dependencies", nil, body, nil, true)
+}
+
+// ReinstallDependencies 重新安装指定依赖。
+func (c *Client) ReinstallDependencies(ctx context.Context, ids []int) error {
+	return c.do(ctx, http.MethodPut, "/open/dependencies/reinstall", nil, ids, nil, true)
+}
+
 type apiEnvelope struct {
 	Code    int             `json:"code"`
 	Data    json.RawMessage `json:"data"`
@@ -0,0 +1,254 @@
+package qinglong
+
+// fanout.go 实现跨实例能力：并发搜索所有实例任务（按实例分组展示），以及在所有实例上运行同名任务。
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+const (
+	globalSearchPageSize    = 20
+	globalSearchLinesPerIns = 10
+	globalRunMaxNames       = 5
+
+	// 按名称运行时逐页查找同名任务（最多 runCronsMaxPages 页）。
+	runCronsPageSize = 100
+	runCronsMaxPages = 50
+)
+
+type instanceSearchResult struct {
+	ins   Instance
+	crons []Cron
+	total int
+	err   error
+}
+
+type instanceRunResult struct {
+	ins  Instance
+	ids  []int
+	cost time.Duration
+	err  error
+}
+
+// handleGlobalEvent 处理跨实例相关 EventKey（不依赖当前选中的实例）。
+func (p *Provider) handleGlobalEvent(ctx context.Context, userID string, key string) (bool, error) {
+	if key == wecom.EventKeyQinglongGlobalSearch {
+		p.state.Set(userID, core.ConversationState{
+			ServiceKey: p.Key(),
+			Step:       core.StepAwaitingQinglongGlobalKeyword,
+		})
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("请输入任务关键词（将同时搜索 %d 个青龙实例）：", len(p.order)),
+		})
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyQinglongGlobalRunPrefix) {
+		name := strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongGlobalRunPrefix))
+		if name == "" {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "任务名不合法，请返回后重试。"})
+		}
+		state, ok := p.state.Get(userID)
+		if !ok || state.ServiceKey != p.Key() {
+			state = core.ConversationState{ServiceKey: p.Key()}
+		}
+		state.Step = core.StepAwaitingConfirm
+		state.Action = core.ActionQinglongRunAll
+		state.QinglongCronName = name
+		p.state.Set(userID, state)
+
+		target := fmt.Sprintf("所有实例（%d 个）的同名任务「%s」", len(p.order), name)
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+			ToUser: userID,
+			Card:   wecom.NewConfirmCard(core.ActionQinglongRunAll.DisplayName(), target),
+		})
+	}
+
+	return false, nil
+}
+
+func (p *Provider) sendGlobalSearch(ctx context.Context, userID string, keyword string) error {
+	state, ok := p.state.Get(userID)
+	if ok && state.ServiceKey == p.Key() {
+		state.Step = ""
+		p.state.Set(userID, state)
+	}
+
+	results := make([]instanceSearchResult, len(p.order))
+	var wg sync.WaitGroup
+	for i, ins := range p.order {
+		wg.Add(1)
+		go func(i int, ins Instance) {
+			defer wg.Done()
+			page, err := ins.Client.ListCrons(ctx, ListCronsParams{
+				SearchValue: keyword,
+				Page:        1,
+				Size:        globalSearchPageSize,
+			})
+			results[i] = instanceSearchResult{ins: ins, crons: page.Data, total: page.Total, err: err}
+		}(i, ins)
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("跨实例搜索「%s」：", keyword))
+	var names []string
+	seen := make(map[string]struct{})
+	hits := 0
+	for _, r := range results {
+		b.WriteString("\n\n【" + r.ins.Name + "】")
+		if r.err != nil {
+			b.WriteString("搜索失败：" + r.err.Error())
+			continue
+		}
+		total := r.total
+		if total < len(r.crons) {
+			total = len(r.crons)
+		}
+		if total == 0 {
+			b.WriteString("无匹配任务")
+			continue
+		}
+		hits += total
+		b.WriteString(fmt.Sprintf("%d 个", total))
+		for i, c := range r.crons {
+			if i >= globalSearchLinesPerIns {
+				b.WriteString(fmt.Sprintf("\n…(其余 %d 个省略)", total-i))
+				break
+			}
+			b.WriteString("\n- " + formatGlobalCronLine(c))
+
+			name := strings.TrimSpace(c.Name)
+			if name == "" {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: b.String(),
+	}); err != nil {
+		return err
+	}
+	if hits == 0 {
+		return nil
+	}
+	if len(names) > globalRunMaxNames {
+		names = names[:globalRunMaxNames]
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongGlobalRunCard(keyword, names),
+	})
+}
+
+// confirmGlobalRun 在所有实例上并发运行同名任务（按名称精确匹配），并汇总各实例结果。
+func (p *Provider) confirmGlobalRun(ctx context.Context, userID string, state core.ConversationState) error {
+	name := strings.TrimSpace(state.QinglongCronName)
+	state.Step = ""
+	state.Action = ""
+	p.state.Set(userID, state)
+	if name == "" {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "缺少任务名，请重新搜索。"})
+	}
+
+	results := make([]instanceRunResult, len(p.order))
+	var wg sync.WaitGroup
+	for i, ins := range p.order {
+		wg.Add(1)
+		go func(i int, ins Instance) {
+			defer wg.Done()
+			start := time.Now()
+			ids, err := runCronsByName(ctx, ins.Client, name)
+			results[i] = instanceRunResult{ins: ins, ids: ids, cost: time.Since(start), err: err}
+		}(i, ins)
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("全部实例运行「%s」结果：", name))
+	okCount := 0
+	for _, r := range results {
+		b.WriteString("\n- " + r.ins.Name + "：")
+		switch {
+		case r.err != nil:
+			b.WriteString(fmt.Sprintf("失败（%dms）：%s", r.cost.Milliseconds(), r.err.Error()))
+		case len(r.ids) == 0:
+			b.WriteString("未找到同名任务")
+		default:
+			okCount++
+			b.WriteString(fmt.Sprintf("成功（%dms）任务ID %s", r.cost.Milliseconds(), joinInts(r.ids)))
+		}
+	}
+	b.WriteString(fmt.Sprintf("\n\n成功 %d/%d 个实例", okCount, len(results)))
+
+	return p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: b.String(),
+	})
+}
+
+// runCronsByName 查找名称完全一致的任务并运行；未找到时返回空 ids 且不报错。
+// 关键词搜索为模糊匹配，同名任务可能排在多页之后，需翻完全部结果再判断。
+func runCronsByName(ctx context.Context, client *Client, name string) ([]int, error) {
+	var ids []int
+	seen := 0
+	for page := 1; page <= runCronsMaxPages; page++ {
+		res, err := client.ListCrons(ctx, ListCronsParams{
+			SearchValue: name,
+			Page:        page,
+			Size:        runCronsPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range res.Data {
+			if c.ID > 0 && strings.TrimSpace(c.Name) == name {
+				ids = append(ids, c.ID)
+			}
+		}
+		seen += len(res.Data)
+		if len(res.Data) < runCronsPageSize || seen >= res.Total {
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := client.RunCrons(ctx, ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func formatGlobalCronLine(c Cron) string {
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = "任务"
+	}
+	status := "已启用"
+	if c.IsDisabled != 0 {
+		status = "已禁用"
+	}
+	return fmt.Sprintf("%d: %s（%s）", c.ID, truncateRunes(name, 40), status)
+}
+
+func joinInts(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%d", id))
+	}
+	return strings.Join(parts, ",")
+}
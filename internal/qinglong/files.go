@@ -0,0 +1,288 @@
+package qinglong
+
+// files.go 实现青龙脚本文件/配置文件的浏览与查看（截断预览 + 完整文件以企业微信文件消息下发）。
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+const (
+	fileKindScript = "script"
+	fileKindConfig = "config"
+
+	scriptListPageSize = 3
+	maxConfigButtons   = 5
+	maxFilePreviewRune = 1200
+)
+
+// handleFileEvent 处理“文件查看”相关 EventKey；返回 handled=false 表示非文件相关事件。
+func (p *Provider) handleFileEvent(ctx context.Context, userID string, ins Instance, state core.ConversationState, key string) (bool, error) {
+	switch key {
+	case wecom.EventKeyQinglongActionFiles:
+		state.Step = ""
+		state.QinglongFileKind = ""
+		state.QinglongFilePath = ""
+		p.state.Set(userID, state)
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+			ToUser: userID,
+			Card:   wecom.NewQinglongFileMenuCard(ins.Name),
+		})
+
+	case wecom.EventKeyQinglongFileScripts:
+		return true, p.sendScriptList(ctx, userID, ins, 1)
+
+	case wecom.EventKeyQinglongFileScriptSearch:
+		state.Step = core.StepAwaitingQinglongScriptKeyword
+		p.state.Set(userID, state)
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: "请输入脚本文件名关键词：",
+		})
+
+	case wecom.EventKeyQinglongFileConfigs:
+		return true, p.sendConfigList(ctx, userID, ins)
+
+	case wecom.EventKeyQinglongFileSendFull:
+		return true, p.sendFullFile(ctx, userID, ins, state)
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyQinglongScriptPagePrefix) {
+		page, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongScriptPagePrefix)))
+		if err != nil || page <= 0 {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "页码不合法，请重新选择。"})
+		}
+		return true, p.sendScriptList(ctx, userID, ins, page)
+	}
+	if strings.HasPrefix(key, wecom.EventKeyQinglongScriptSelectPrefix) {
+		filePath := strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongScriptSelectPrefix))
+		return true, p.previewFile(ctx, userID, ins, state, fileKindScript, filePath)
+	}
+	if strings.HasPrefix(key, wecom.EventKeyQinglongConfigSelectPrefix) {
+		name := strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongConfigSelectPrefix))
+		return true, p.previewFile(ctx, userID, ins, state, fileKindConfig, name)
+	}
+	return false, nil
+}
+
+func (p *Provider) sendScriptList(ctx context.Context, userID string, ins Instance, page int) error {
+	files, err := ins.Client.ListScriptFiles(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("获取脚本列表失败：%s", err.Error()),
+		})
+	}
+	if len(files) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未找到脚本文件。"})
+	}
+
+	totalPages := (len(files) + scriptListPageSize - 1) / scriptListPageSize
+	if page <= 0 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+	start := (page - 1) * scriptListPageSize
+	end := start + scriptListPageSize
+	if end > len(files) {
+		end = len(files)
+	}
+
+	prevPage, nextPage := 0, 0
+	if page > 1 {
+		prevPage = page - 1
+	}
+	if page < totalPages {
+		nextPage = page + 1
+	}
+
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongScriptListCard("脚本文件", ins.Name, page, totalPages, scriptFileOptions(files[start:end]), prevPage, nextPage),
+	})
+}
+
+func (p *Provider) sendScriptListBySearch(ctx context.Context, userID string, ins Instance, keyword string) error {
+	state, ok := p.state.Get(userID)
+	if ok && state.ServiceKey == p.Key() {
+		state.Step = ""
+		p.state.Set(userID, state)
+	}
+
+	files, err := ins.Client.ListScriptFiles(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("获取脚本列表失败：%s", err.Error()),
+		})
+	}
+
+	kw := strings.ToLower(strings.TrimSpace(keyword))
+	var hits []ScriptFile
+	for _, f := range files {
+		if strings.Contains(strings.ToLower(f.FullPath()), kw) {
+			hits = append(hits, f)
+		}
+	}
+	if len(hits) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未找到脚本，请更换关键词重试。"})
+	}
+	if len(hits) == 1 && ok {
+		return p.previewFile(ctx, userID, ins, state, fileKindScript, hits[0].FullPath())
+	}
+
+	title := "搜索结果"
+	if len(hits) > scriptListPageSize {
+		title = fmt.Sprintf("搜索结果（共 %d 个，仅展示前 %d 个）", len(hits), scriptListPageSize)
+		hits = hits[:scriptListPageSize]
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongScriptListCard(title, ins.Name, 0, 0, scriptFileOptions(hits), 0, 0),
+	})
+}
+
+func (p *Provider) sendConfigList(ctx context.Context, userID string, ins Instance) error {
+	files, err := ins.Client.ListConfigFiles(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("获取配置文件列表失败：%s", err.Error()),
+		})
+	}
+
+	var opts []wecom.QinglongFileOption
+	for _, f := range files {
+		name := strings.TrimSpace(f.Value)
+		if name == "" {
+			name = strings.TrimSpace(f.Title)
+		}
+		if name == "" {
+			continue
+		}
+		text := strings.TrimSpace(f.Title)
+		if text == "" {
+			text = name
+		}
+		opts = append(opts, wecom.QinglongFileOption{Key: name, Text: truncateRunes(text, 32)})
+		if len(opts) >= maxConfigButtons {
+			break
+		}
+	}
+	if len(opts) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未找到配置文件。"})
+	}
+
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongConfigListCard(ins.Name, opts),
+	})
+}
+
+func (p *Provider) previewFile(ctx context.Context, userID string, ins Instance, state core.ConversationState, kind string, filePath string) error {
+	if filePath == "" {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "文件名不合法，请返回后重试。"})
+	}
+
+	content, err := p.readFile(ctx, ins, kind, filePath)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("读取文件失败：%s", err.Error()),
+		})
+	}
+
+	state.Step = ""
+	state.QinglongFileKind = kind
+	state.QinglongFilePath = filePath
+	p.state.Set(userID, state)
+
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: formatFileForWeCom(filePath, content),
+	}); err != nil {
+		return err
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongFileViewCard(ins.Name, filePath),
+	})
+}
+
+func (p *Provider) sendFullFile(ctx context.Context, userID string, ins Instance, state core.ConversationState) error {
+	kind := state.QinglongFileKind
+	filePath := state.QinglongFilePath
+	if kind == "" || filePath == "" {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "请先选择文件。"})
+	}
+
+	sender, ok := p.wecom.(core.FileSender)
+	if !ok {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "当前发送端不支持文件消息。"})
+	}
+
+	content, err := p.readFile(ctx, ins, kind, filePath)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("读取文件失败：%s", err.Error()),
+		})
+	}
+	if content == "" {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: fmt.Sprintf("%s 内容为空。", filePath)})
+	}
+
+	mediaID, err := sender.UploadMedia(ctx, wecom.MediaTypeFile, path.Base(filePath), []byte(content))
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("上传文件失败：%s", err.Error()),
+		})
+	}
+	if err := sender.SendFile(ctx, wecom.FileMessage{ToUser: userID, MediaID: mediaID}); err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("发送文件失败：%s", err.Error()),
+		})
+	}
+	return nil
+}
+
+func (p *Provider) readFile(ctx context.Context, ins Instance, kind string, filePath string) (string, error) {
+	switch kind {
+	case fileKindScript:
+		dir, name := path.Split(filePath)
+		return ins.Client.GetScript(ctx, ScriptFile{Dir: strings.TrimRight(dir, "/"), Name: name})
+	case fileKindConfig:
+		return ins.Client.GetConfigFile(ctx, filePath)
+	default:
+		return "", fmt.Errorf("未知文件类型: %s", kind)
+	}
+}
+
+func scriptFileOptions(files []ScriptFile) []wecom.QinglongFileOption {
+	opts := make([]wecom.QinglongFileOption, 0, len(files))
+	for _, f := range files {
+		full := f.FullPath()
+		opts = append(opts, wecom.QinglongFileOption{Key: full, Text: truncateRunes(full, 32)})
+	}
+	return opts
+}
+
+func formatFileForWeCom(filePath string, content string) string {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Sprintf("%s 内容为空。", filePath)
+	}
+	r := []rune(content)
+	if len(r) <= maxFilePreviewRune {
+		return fmt.Sprintf("%s：\n%s", filePath, content)
+	}
+	return fmt.Sprintf("%s（共 %d 字符，仅展示开头）：\n%s\n…(后略，可点击“发送完整文件”获取全文)", filePath, len(r), string(r[:maxFilePreviewRune]))
+}
@@ -23,6 +23,12 @@ type ProviderDeps struct {
 	WeCom     core.WeComSender
 	State     *core.StateStore
 	Instances []Instance
+
+	// AdminUserIDs 为可安装/重装依赖的管理员；为空时不额外限制（白名单用户均可操作）。
+	AdminUserIDs []string
+
+	// LogDelivery 控制任务日志的下发方式（truncate/split/file，默认 truncate：仅展示末尾部分）。
+	LogDelivery wecom.LongTextMode
 }
 
 type Provider struct {
@@ -30,6 +36,9 @@ type Provider struct {
 	state     *core.StateStore
 	instances map[string]Instance
 	order     []Instance
+	admins    map[string]struct{}
+
+	logDelivery wecom.LongTextMode
 }
 
 func NewProvider(deps ProviderDeps) *Provider {
@@ -46,22 +55,58 @@ func NewProvider(deps ProviderDeps) *Provider {
 		order = append(order, ins)
 	}
 
+	admins := make(map[string]struct{})
+	for _, id := range deps.AdminUserIDs {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			admins[id] = struct{}{}
+		}
+	}
+
 	return &Provider{
 		wecom:     deps.WeCom,
 		state:     deps.State,
 		instances: instances,
 		order:     order,
+		admins:    admins,
+
+		logDelivery: deps.LogDelivery,
 	}
 }
 
-func (p *Provider) Key() string { return "qinglong" }
+// 服务 key 与显示名（Provider 与包级 MenuService 共用）。
+const (
+	serviceKey         = "qinglong"
+	serviceDisplayName = "青龙(QL)"
+)
+
+func (p *Provider) Key() string { return serviceKey }
 
-func (p *Provider) DisplayName() string { return "青龙(QL)" }
+func (p *Provider) DisplayName() string { return serviceDisplayName }
 
 func (p *Provider) EntryKeywords() []string {
 	return []string{"青龙", "ql", "qinglong"}
 }
 
+// MenuButton 贡献应用自定义菜单中的青龙分组（实现 core.MenuContributor）。
+func (p *Provider) MenuButton() wecom.MenuButton { return menuButton() }
+
+// MenuService 返回菜单组装所需的服务描述，无需创建 Provider（供 --wecom-sync-menu 使用）。
+func MenuService() core.MenuService {
+	b := menuButton()
+	return core.MenuService{Key: serviceKey, DisplayName: serviceDisplayName, Button: &b}
+}
+
+func menuButton() wecom.MenuButton {
+	return wecom.MenuButton{
+		Name: "青龙",
+		SubButtons: []wecom.MenuButton{
+			{Type: "click", Name: "进入青龙", Key: wecom.EventKeyServiceSelectPrefix + serviceKey},
+			{Type: "click", Name: "动作菜单", Key: wecom.EventKeyQinglongMenu},
+		},
+	}
+}
+
 func (p *Provider) OnEnter(ctx context.Context, userID string) error {
 	if len(p.order) == 0 {
 		return p.wecom.SendText(ctx, wecom.TextMessage{
@@ -103,6 +148,17 @@ func (p *Provider) HandleText(ctx context.Context, userID, content string) (bool
 		})
 	}
 
+	if state.Step == core.StepAwaitingQinglongGlobalKeyword {
+		kw := strings.TrimSpace(content)
+		if kw == "" {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{
+				ToUser:  userID,
+				Content: "关键词不能为空，请重新输入：",
+			})
+		}
+		return true, p.sendGlobalSearch(ctx, userID, kw)
+	}
+
 	ins, ok := p.instances[state.InstanceID]
 	if !ok {
 		if state.InstanceID != "" {
@@ -125,6 +181,19 @@ func (p *Provider) HandleText(ctx context.Context, userID, content string) (bool
 		}
 		return true, p.sendCronListBySearch(ctx, userID, ins, kw)
 
+	case core.StepAwaitingQinglongScriptKeyword:
+		kw := strings.TrimSpace(content)
+		if kw == "" {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{
+				ToUser:  userID,
+				Content: "关键词不能为空，请重新输入：",
+			})
+		}
+		return true, p.sendScriptListBySearch(ctx, userID, ins, kw)
+
+	case core.StepAwaitingQinglongDepName:
+		return true, p.handleDependencyName(ctx, userID, state, content)
+
 	case core.StepAwaitingQinglongCronID:
 		id, err := strconv.Atoi(strings.TrimSpace(content))
 		if err != nil || id <= 0 {
@@ -182,6 +251,10 @@ func (p *Provider) HandleEvent(ctx context.Context, userID string, msg wecom.Inc
 		})
 	}
 
+	if handled, err := p.handleGlobalEvent(ctx, userID, key); handled {
+		return true, err
+	}
+
 	state, ok := p.state.Get(userID)
 	if !ok || state.ServiceKey != p.Key() {
 		return false, nil
@@ -216,6 +289,13 @@ func (p *Provider) HandleEvent(ctx context.Context, userID string, msg wecom.Inc
 		})
 	}
 
+	if handled, err := p.handleFileEvent(ctx, userID, ins, state, key); handled {
+		return true, err
+	}
+	if handled, err := p.handleSystemEvent(ctx, userID, ins, state, key); handled {
+		return true, err
+	}
+
 	if strings.HasPrefix(key, wecom.EventKeyQinglongCronSelectPrefix) {
 		idStr := strings.TrimPrefix(key, wecom.EventKeyQinglongCronSelectPrefix)
 		id, err := strconv.Atoi(strings.TrimSpace(idStr))
@@ -256,10 +336,7 @@ func (p *Provider) HandleEvent(ctx context.Context, userID string, msg wecom.Inc
 				Content: fmt.Sprintf("获取日志失败：%s", err.Error()),
 			})
 		}
-		return true, p.wecom.SendText(ctx, wecom.TextMessage{
-			ToUser:  userID,
-			Content: formatLogForWeCom(state.CronID, logText),
-		})
+		return true, p.sendCronLog(ctx, userID, state.CronID, logText)
 
 	case wecom.EventKeyQinglongCronRun:
 		return p.prepareConfirm(ctx, userID, state, core.ActionQinglongRun)
@@ -277,10 +354,16 @@ func (p *Provider) HandleConfirm(ctx context.Context, userID string) (bool, erro
 	if !ok || state.ServiceKey != p.Key() || state.Step != core.StepAwaitingConfirm {
 		return false, nil
 	}
+	if state.Action == core.ActionQinglongRunAll {
+		return true, p.confirmGlobalRun(ctx, userID, state)
+	}
 	ins, ok := p.instances[state.InstanceID]
 	if !ok {
 		return true, p.OnEnter(ctx, userID)
 	}
+	if state.Action == core.ActionQinglongDepInstall || state.Action == core.ActionQinglongDepReinstall {
+		return true, p.confirmDependency(ctx, userID, ins, state)
+	}
 	if state.CronID <= 0 {
 		return true, errors.New("缺少任务ID")
 	}
@@ -414,13 +497,20 @@ func formatCronButtonText(id int, name string) string {
 	return truncateRunes(text, 32)
 }
 
-func formatLogForWeCom(cronID int, logText string) string {
+// sendCronLog 按 log_delivery 经 core.SendLongText 下发任务日志：truncate 仅展示末尾，split/file 下发完整日志。
+func (p *Provider) sendCronLog(ctx context.Context, userID string, cronID int, logText string) error {
 	content := strings.TrimSpace(logText)
 	if content == "" {
-		return fmt.Sprintf("任务ID %d 日志为空。", cronID)
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: fmt.Sprintf("任务ID %d 日志为空。", cronID)})
 	}
-	content = tailRunes(content, 1200)
-	return fmt.Sprintf("任务ID %d 最近日志：\n%s", cronID, content)
+	return core.SendLongText(ctx, p.wecom, wecom.TextMessage{
+		ToUser:  userID,
+		Content: fmt.Sprintf("任务ID %d 日志：\n%s", cronID, content),
+	}, core.LongTextOptions{
+		Mode:     p.logDelivery,
+		Filename: fmt.Sprintf("cron-%d.log", cronID),
+		KeepTail: true,
+	})
 }
 
 func truncateRunes(s string, max int) string {
@@ -434,15 +524,12 @@ func truncateRunes(s string, max int) string {
 	return string(r[:max-1]) + "…"
 }
 
-func tailRunes(s string, max int) string {
-	if max <= 0 {
-		return ""
-	}
-	r := []rune(s)
-	if len(r) <= max {
-		return s
+func (p *Provider) isAdmin(userID string) bool {
+	if len(p.admins) == 0 {
+		return true
 	}
-	return "…(前略)\n" + string(r[len(r)-max:])
+	_, ok := p.admins[userID]
+	return ok
 }
 
 func isValidInstanceID(id string) bool {
@@ -3,6 +3,7 @@ package qinglong
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -20,6 +21,8 @@ type recordWeCom struct {
 	mu    sync.Mutex
 	texts []wecom.TextMessage
 	cards []wecom.TemplateCardMessage
+
+	markdowns []wecom.MarkdownMessage
 }
 
 func (r *recordWeCom) SendText(_ context.Context, msg wecom.TextMessage) error {
@@ -36,6 +39,13 @@ func (r *recordWeCom) SendTemplateCard(_ context.Context, msg wecom.TemplateCard
 	return nil
 }
 
+func (r *recordWeCom) SendMarkdown(_ context.Context, msg wecom.MarkdownMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.markdowns = append(r.markdowns, msg)
+	return nil
+}
+
 func (r *recordWeCom) LastText() (wecom.TextMessage, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -256,7 +266,7 @@ func TestProvider_OnEnter_MultiInstance_SelectAndMenu(t *testing.T) {
 		t.Fatalf("HandleEvent(cron log) ok=%v err=%v, want ok=true err=nil", ok, err)
 	}
 	txt, ok := rec.LastText()
-	if !ok || !strings.Contains(txt.Content, "最近日志") {
+	if !ok || !strings.HasPrefix(txt.Content, "任务ID 1 日志：\n") {
 		t.Fatalf("want log text, got: %#v", txt)
 	}
 
@@ -566,7 +576,7 @@ func TestProvider_LogEmptyAndTruncated(t *testing.T) {
 				}
 				_ = json.NewEncoder(w).Encode(map[string]interface{}{
 					"code": 200,
-					"data": strings.Repeat("a", 1300),
+					"data": strings.Repeat("a", 2000),
 				})
 				return
 			}
@@ -631,7 +641,7 @@ func TestProvider_LogEmptyAndTruncated(t *testing.T) {
 		t.Fatalf("HandleEvent(cron log long) ok=%v err=%v, want ok=true err=nil", ok, err)
 	}
 	txt, ok = rec.LastText()
-	if !ok || !strings.Contains(txt.Content, "…(前略)") {
+	if !ok || !strings.Contains(txt.Content, "…（前略）") || len(txt.Content) > wecom.TextChunkBytes {
 		t.Fatalf("want truncated prefix, got: %#v", txt)
 	}
 }
@@ -835,3 +845,477 @@ func TestProvider_MenuAndSwitchInstance_Navigation(t *testing.T) {
 		t.Fatalf("card title = %q, want %q", title, "青龙(QL)")
 	}
 }
+
+type recordWeComFile struct {
+	recordWeCom
+
+	uploads []string
+	files   []wecom.FileMessage
+	data    []byte
+}
+
+func (r *recordWeComFile) UploadMedia(_ context.Context, mediaType string, filename string, data []byte) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uploads = append(r.uploads, mediaType+":"+filename)
+	r.data = append([]byte(nil), data...)
+	return "MID", nil
+}
+
+func (r *recordWeComFile) SendFile(_ context.Context, msg wecom.FileMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files = append(r.files, msg)
+	return nil
+}
+
+func TestProvider_Files_ScriptPreviewAndSendFull(t *testing.T) {
+	t.Parallel()
+
+	longScript := strings.Repeat("console.log(1);\n", 200)
+	var detailHits int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/open/auth/token":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{
+					"token":      "AT",
+					"token_type": "Bearer",
+					"expiration": time.Now().Add(1 * time.Hour).Unix(),
+				},
+			})
+			return
+		case "/open/scripts":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": []map[string]interface{}{
+					{"title": "a.js", "key": "a.js", "type": "file"},
+					{"title": "b.py", "key": "b.py", "type": "file"},
+					{"title": "c.sh", "key": "c.sh", "type": "file"},
+					{"title": "repo", "key": "repo", "type": "directory", "children": []map[string]interface{}{
+						{"title": "jd_bean.js", "key": "repo/jd_bean.js", "parent": "repo", "type": "file"},
+					}},
+				},
+			})
+			return
+		case "/open/scripts/detail":
+			atomic.AddInt32(&detailHits, 1)
+			if r.URL.Query().Get("file") != "jd_bean.js" || r.URL.Query().Get("path") != "repo" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": longScript,
+			})
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, ClientID: "id", ClientSecret: "sec"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	rec := &recordWeComFile{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:     rec,
+		State:     store,
+		Instances: []Instance{{ID: "a", Name: "A", Client: client}},
+	})
+
+	ctx := context.Background()
+	userID := "u"
+	if err := p.OnEnter(ctx, userID); err != nil {
+		t.Fatalf("OnEnter() error: %v", err)
+	}
+
+	// 脚本列表：第 1 页 3 个文件 + 下一页 + 返回
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongFileScripts}); err != nil || !ok {
+		t.Fatalf("HandleEvent(scripts) ok=%v err=%v", ok, err)
+	}
+	cardMsg, _ := rec.LastCard()
+	buttons, _ := cardMsg.Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 5 {
+		t.Fatalf("button_list len = %d, want 5 (3 files + next + back)", len(buttons))
+	}
+
+	// 搜索脚本：唯一命中时直接展示截断内容
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongFileScriptSearch}); err != nil || !ok {
+		t.Fatalf("HandleEvent(script search) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleText(ctx, userID, "bean"); err != nil || !ok {
+		t.Fatalf("HandleText(keyword) ok=%v err=%v", ok, err)
+	}
+	textMsg, _ := rec.LastText()
+	if !strings.Contains(textMsg.Content, "repo/jd_bean.js") || !strings.Contains(textMsg.Content, "后略") {
+		t.Fatalf("want truncated preview, got: %q", textMsg.Content)
+	}
+	cardMsg, _ = rec.LastCard()
+	buttons, _ = cardMsg.Card["button_list"].([]map[string]interface{})
+	if len(buttons) == 0 || buttons[0]["key"] != wecom.EventKeyQinglongFileSendFull {
+		t.Fatalf("want file view card with send_full, got: %#v", buttons)
+	}
+
+	// 发送完整文件：上传素材并下发文件消息
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongFileSendFull}); err != nil || !ok {
+		t.Fatalf("HandleEvent(send full) ok=%v err=%v", ok, err)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.uploads) != 1 || rec.uploads[0] != "file:jd_bean.js" {
+		t.Fatalf("uploads = %#v, want [file:jd_bean.js]", rec.uploads)
+	}
+	if string(rec.data) != longScript {
+		t.Fatalf("uploaded data len = %d, want %d", len(rec.data), len(longScript))
+	}
+	if len(rec.files) != 1 || rec.files[0].MediaID != "MID" || rec.files[0].ToUser != userID {
+		t.Fatalf("files = %#v", rec.files)
+	}
+	if got := atomic.LoadInt32(&detailHits); got != 2 {
+		t.Fatalf("detail hits = %d, want 2", got)
+	}
+}
+
+func TestProvider_System_DependencyReinstallAndInstall(t *testing.T) {
+	t.Parallel()
+
+	var reinstallHits int32
+	var installHits int32
+	validateErr := make(chan error, 4)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/open/auth/token":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{
+					"token":      "AT",
+					"token_type": "Bearer",
+					"expiration": time.Now().Add(1 * time.Hour).Unix(),
+				},
+			})
+			return
+		case r.URL.Path == "/open/system" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{"version": "2.17.0", "branch": "master"},
+			})
+			return
+		case r.URL.Path == "/open/system/update-check" && r.Method == http.MethodPut:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{"hasNewVersion": true, "lastVersion": "2.18.0", "lastLog": "fix"},
+			})
+			return
+		case r.URL.Path == "/open/dependencies" && r.Method == http.MethodGet:
+			if got := r.URL.Query().Get("type"); got != "nodejs" {
+				validateErr <- fmt.Errorf("type = %q, want nodejs", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": []map[string]interface{}{
+					{"id": 1, "name": "axios", "type": 0, "status": 1},
+					{"id": 2, "name": "crypto-js", "type": 0, "status": 2},
+				},
+			})
+			return
+		case r.URL.Path == "/open/dependencies" && r.Method == http.MethodPost:
+			atomic.AddInt32(&installHits, 1)
+			var body []map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if len(body) != 1 || body[0]["name"] != "got" || body[0]["type"] != float64(0) {
+				validateErr <- fmt.Errorf("install body = %#v", body)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": body})
+			return
+		case r.URL.Path == "/open/dependencies/reinstall" && r.Method == http.MethodPut:
+			atomic.AddInt32(&reinstallHits, 1)
+			var ids []int
+			_ = json.NewDecoder(r.Body).Decode(&ids)
+			if len(ids) != 1 || ids[0] != 2 {
+				validateErr <- fmt.Errorf("reinstall ids = %#v", ids)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": true})
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, ClientID: "id", ClientSecret: "sec"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:        rec,
+		State:        store,
+		Instances:    []Instance{{ID: "a", Name: "A", Client: client}},
+		AdminUserIDs: []string{"admin"},
+	})
+
+	ctx := context.Background()
+
+	// 非管理员：可查看但不可重装
+	if err := p.OnEnter(ctx, "guest"); err != nil {
+		t.Fatalf("OnEnter(guest) error: %v", err)
+	}
+	if ok, err := p.HandleEvent(ctx, "guest", wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongDepReinstallPrefix + "2"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(guest reinstall) ok=%v err=%v", ok, err)
+	}
+	if msg, _ := rec.LastText(); !strings.Contains(msg.Content, "仅管理员") {
+		t.Fatalf("want admin-only text, got: %q", msg.Content)
+	}
+
+	userID := "admin"
+	if err := p.OnEnter(ctx, userID); err != nil {
+		t.Fatalf("OnEnter() error: %v", err)
+	}
+
+	// 系统菜单：版本 + 更新检查
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongActionSystem}); err != nil || !ok {
+		t.Fatalf("HandleEvent(system) ok=%v err=%v", ok, err)
+	}
+	if msg, _ := rec.LastText(); !strings.Contains(msg.Content, "2.17.0") || !strings.Contains(msg.Content, "有新版本 2.18.0") {
+		t.Fatalf("want version/update text, got: %q", msg.Content)
+	}
+
+	// 依赖列表：失败依赖优先展示，并提供重装按钮
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongDepListPrefix + "nodejs"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(dep list) ok=%v err=%v", ok, err)
+	}
+	msg, _ := rec.LastText()
+	if !strings.Contains(msg.Content, "失败 1") || strings.Index(msg.Content, "crypto-js") > strings.Index(msg.Content, "axios") {
+		t.Fatalf("unexpected dependency list: %q", msg.Content)
+	}
+	cardMsg, _ := rec.LastCard()
+	buttons, _ := cardMsg.Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 3 || buttons[0]["key"] != wecom.EventKeyQinglongDepReinstallPrefix+"2" {
+		t.Fatalf("unexpected dependency buttons: %#v", buttons)
+	}
+
+	// 重装：确认后调用 reinstall
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongDepReinstallPrefix + "2"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(reinstall) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm(reinstall) ok=%v err=%v", ok, err)
+	}
+	if got := atomic.LoadInt32(&reinstallHits); got != 1 {
+		t.Fatalf("reinstall hits = %d, want 1", got)
+	}
+
+	// 安装：输入名称 -> 确认 -> POST
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongDepInstallPrefix + "nodejs"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(install) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleText(ctx, userID, "bad name"); err != nil || !ok {
+		t.Fatalf("HandleText(bad name) ok=%v err=%v", ok, err)
+	}
+	if msg, _ := rec.LastText(); !strings.Contains(msg.Content, "不合法") {
+		t.Fatalf("want invalid name text, got: %q", msg.Content)
+	}
+	if ok, err := p.HandleText(ctx, userID, "got"); err != nil || !ok {
+		t.Fatalf("HandleText(name) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm(install) ok=%v err=%v", ok, err)
+	}
+	if got := atomic.LoadInt32(&installHits); got != 1 {
+		t.Fatalf("install hits = %d, want 1", got)
+	}
+	if msg, _ := rec.LastText(); !strings.Contains(msg.Content, "已提交") {
+		t.Fatalf("want submitted text, got: %q", msg.Content)
+	}
+
+	select {
+	case err := <-validateErr:
+		t.Fatalf("validate request error: %v", err)
+	default:
+	}
+}
+
+func newGlobalTestServer(t *testing.T, crons []map[string]interface{}, runIDs *[]int, mu *sync.Mutex) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/open/auth/token":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{
+					"token":      "AT",
+					"token_type": "Bearer",
+					"expiration": time.Now().Add(1 * time.Hour).Unix(),
+				},
+			})
+			return
+		case r.URL.Path == "/open/crons" && r.Method == http.MethodGet:
+			kw := r.URL.Query().Get("searchValue")
+			var out []map[string]interface{}
+			for _, c := range crons {
+				if strings.Contains(c["name"].(string), kw) {
+					out = append(out, c)
+				}
+			}
+			total := len(out)
+			if size, _ := strconv.Atoi(r.URL.Query().Get("size")); size > 0 {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				start := min(max(page-1, 0)*size, total)
+				out = out[start:min(start+size, total)]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{"data": out, "total": total},
+			})
+			return
+		case r.URL.Path == "/open/crons/run" && r.Method == http.MethodPut:
+			var ids []int
+			_ = json.NewDecoder(r.Body).Decode(&ids)
+			mu.Lock()
+			*runIDs = append(*runIDs, ids...)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": true})
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProvider_GlobalSearchAndRunAll(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var runA, runB []int
+	srvA := newGlobalTestServer(t, []map[string]interface{}{
+		{"id": 3, "name": "jd_bean"},
+	}, &runA, &mu)
+	srvB := newGlobalTestServer(t, []map[string]interface{}{
+		{"id": 7, "name": "jd_bean"},
+		{"id": 8, "name": "jd_bean_plus", "isDisabled": 1},
+	}, &runB, &mu)
+	srvC := newGlobalTestServer(t, nil, new([]int), &mu)
+
+	newClient := func(srv *httptest.Server) *Client {
+		c, err := NewClient(ClientConfig{BaseURL: srv.URL, ClientID: "id", ClientSecret: "sec"}, srv.Client())
+		if err != nil {
+			t.Fatalf("NewClient() error: %v", err)
+		}
+		return c
+	}
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom: rec,
+		State: store,
+		Instances: []Instance{
+			{ID: "a", Name: "A", Client: newClient(srvA)},
+			{ID: "b", Name: "B", Client: newClient(srvB)},
+			{ID: "c", Name: "C", Client: newClient(srvC)},
+		},
+	})
+
+	ctx := context.Background()
+	userID := "u"
+	if err := p.OnEnter(ctx, userID); err != nil {
+		t.Fatalf("OnEnter() error: %v", err)
+	}
+	cardMsg, _ := rec.LastCard()
+	buttons, _ := cardMsg.Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 4 || buttons[3]["key"] != wecom.EventKeyQinglongGlobalSearch {
+		t.Fatalf("want 3 instances + global search button, got: %#v", buttons)
+	}
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongGlobalSearch}); err != nil || !ok {
+		t.Fatalf("HandleEvent(global search) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleText(ctx, userID, "bean"); err != nil || !ok {
+		t.Fatalf("HandleText(keyword) ok=%v err=%v", ok, err)
+	}
+	msg, _ := rec.LastText()
+	for _, want := range []string{"【A】1 个", "【B】2 个", "【C】无匹配任务", "8: jd_bean_plus（已禁用）"} {
+		if !strings.Contains(msg.Content, want) {
+			t.Fatalf("search text missing %q, got: %q", want, msg.Content)
+		}
+	}
+	cardMsg, _ = rec.LastCard()
+	buttons, _ = cardMsg.Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 3 || buttons[0]["key"] != wecom.EventKeyQinglongGlobalRunPrefix+"jd_bean" {
+		t.Fatalf("unexpected run-all buttons: %#v", buttons)
+	}
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyQinglongGlobalRunPrefix + "jd_bean"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(run all) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+	msg, _ = rec.LastText()
+	for _, want := range []string{"- A：成功", "任务ID 3", "任务ID 7", "- C：未找到同名任务", "成功 2/3 个实例"} {
+		if !strings.Contains(msg.Content, want) {
+			t.Fatalf("run text missing %q, got: %q", want, msg.Content)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runA) != 1 || runA[0] != 3 {
+		t.Fatalf("instance A run ids = %#v, want [3]", runA)
+	}
+	if len(runB) != 1 || runB[0] != 7 {
+		t.Fatalf("instance B run ids = %#v, want [7] (exact name match)", runB)
+	}
+}
+
+func TestRunCronsByName_PagesThroughFuzzyMatches(t *testing.T) {
+	t.Parallel()
+
+	var crons []map[string]interface{}
+	for i := 1; i <= 150; i++ {
+		crons = append(crons, map[string]interface{}{"id": i, "name": fmt.Sprintf("jd_bean_%d", i)})
+	}
+	crons = append(crons, map[string]interface{}{"id": 999, "name": "jd_bean"})
+
+	var mu sync.Mutex
+	var runIDs []int
+	srv := newGlobalTestServer(t, crons, &runIDs, &mu)
+	c, err := NewClient(ClientConfig{BaseURL: srv.URL, ClientID: "id", ClientSecret: "sec"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	ids, err := runCronsByName(context.Background(), c, "jd_bean")
+	if err != nil {
+		t.Fatalf("runCronsByName() error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != 999 {
+		t.Fatalf("ids = %#v, want [999] from the second page", ids)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runIDs) != 1 || runIDs[0] != 999 {
+		t.Fatalf("run ids = %#v, want [999]", runIDs)
+	}
+}
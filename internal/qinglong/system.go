@@ -0,0 +1,268 @@
+package qinglong
+
+// system.go 实现青龙“系统”菜单：面板版本/更新检查、依赖列表与依赖安装/重装（需确认，仅管理员）。
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+const maxDependencyListLines = 40
+
+// handleSystemEvent 处理“系统”相关 EventKey；返回 handled=false 表示非系统相关事件。
+func (p *Provider) handleSystemEvent(ctx context.Context, userID string, ins Instance, state core.ConversationState, key string) (bool, error) {
+	if key == wecom.EventKeyQinglongActionSystem {
+		state.Step = ""
+		state.Action = ""
+		p.state.Set(userID, state)
+		if err := p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: p.formatSystemInfo(ctx, ins),
+		}); err != nil {
+			return true, err
+		}
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+			ToUser: userID,
+			Card:   wecom.NewQinglongSystemCard(ins.Name),
+		})
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyQinglongDepListPrefix) {
+		depType := strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongDepListPrefix))
+		return true, p.sendDependencyList(ctx, userID, ins, depType)
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyQinglongDepInstallPrefix) {
+		depType := strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongDepInstallPrefix))
+		if DependencyTypeIndex(depType) < 0 {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "依赖类型不合法，请返回后重试。"})
+		}
+		if !p.isAdmin(userID) {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "仅管理员可安装/重装依赖。"})
+		}
+		state.Step = core.StepAwaitingQinglongDepName
+		state.QinglongDepType = depType
+		state.QinglongDepID = 0
+		state.QinglongDepName = ""
+		p.state.Set(userID, state)
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("请输入要安装的 %s 依赖名称：", depType),
+		})
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyQinglongDepReinstallPrefix) {
+		id, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(key, wecom.EventKeyQinglongDepReinstallPrefix)))
+		if err != nil || id <= 0 {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "依赖ID不合法，请返回后重试。"})
+		}
+		if !p.isAdmin(userID) {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "仅管理员可安装/重装依赖。"})
+		}
+		state.QinglongDepID = id
+		state.QinglongDepName = ""
+		return true, p.prepareDependencyConfirm(ctx, userID, state, core.ActionQinglongDepReinstall)
+	}
+
+	return false, nil
+}
+
+// handleDependencyName 处理“安装依赖”输入的依赖名称。
+func (p *Provider) handleDependencyName(ctx context.Context, userID string, state core.ConversationState, content string) error {
+	name := strings.TrimSpace(content)
+	if name == "" || strings.ContainsAny(name, " \t\r\n;|&`$") {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: "依赖名称不合法（不能为空或包含空白/特殊字符），请重新输入：",
+		})
+	}
+	state.QinglongDepID = 0
+	state.QinglongDepName = name
+	return p.prepareDependencyConfirm(ctx, userID, state, core.ActionQinglongDepInstall)
+}
+
+func (p *Provider) prepareDependencyConfirm(ctx context.Context, userID string, state core.ConversationState, action core.Action) error {
+	state.Step = core.StepAwaitingConfirm
+	state.Action = action
+	p.state.Set(userID, state)
+
+	target := fmt.Sprintf("%s 依赖 %s", state.QinglongDepType, state.QinglongDepName)
+	if action == core.ActionQinglongDepReinstall {
+		target = fmt.Sprintf("%s 依赖ID %d", state.QinglongDepType, state.QinglongDepID)
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewConfirmCard(action.DisplayName(), target),
+	})
+}
+
+// confirmDependency 执行已确认的依赖安装/重装；青龙为异步安装，此处仅提示已提交。
+func (p *Provider) confirmDependency(ctx context.Context, userID string, ins Instance, state core.ConversationState) error {
+	if !p.isAdmin(userID) {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "仅管理员可安装/重装依赖。"})
+	}
+
+	state.Step = ""
+	action := state.Action
+	state.Action = ""
+	p.state.Set(userID, state)
+
+	start := time.Now()
+	var err error
+	var target string
+	switch action {
+	case core.ActionQinglongDepInstall:
+		target = state.QinglongDepName
+		err = ins.Client.InstallDependency(ctx, state.QinglongDepType, state.QinglongDepName)
+	case core.ActionQinglongDepReinstall:
+		target = "ID " + strconv.Itoa(state.QinglongDepID)
+		err = ins.Client.ReinstallDependencies(ctx, []int{state.QinglongDepID})
+	}
+	cost := time.Since(start).Milliseconds()
+
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("执行失败（%dms）：%s", cost, err.Error()),
+		})
+	}
+	return p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser: userID,
+		Content: fmt.Sprintf("已提交（%dms）：%s %s 依赖 %s\n安装在青龙后台异步进行，可稍后在“%s依赖”中查看状态。",
+			cost, action.DisplayName(), state.QinglongDepType, target, state.QinglongDepType),
+	})
+}
+
+func (p *Provider) sendDependencyList(ctx context.Context, userID string, ins Instance, depType string) error {
+	if DependencyTypeIndex(depType) < 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "依赖类型不合法，请返回后重试。"})
+	}
+
+	deps, err := ins.Client.ListDependencies(ctx, depType)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("获取依赖列表失败：%s\n（如返回 401/403，请确认 OpenAPI 应用已授予 dependencies 权限）", err.Error()),
+		})
+	}
+
+	var failed []wecom.QinglongDependencyOption
+	for _, d := range deps {
+		if d.Status == DependencyStatusInstallFailed {
+			failed = append(failed, wecom.QinglongDependencyOption{ID: d.ID, Name: truncateRunes(d.Name, 24)})
+		}
+	}
+
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: formatDependencyList(depType, deps),
+	}); err != nil {
+		return err
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewQinglongDependencyCard(ins.Name, depType, failed),
+	})
+}
+
+func (p *Provider) formatSystemInfo(ctx context.Context, ins Instance) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("青龙面板：%s\n", ins.Name))
+
+	info, err := ins.Client.GetSystemInfo(ctx)
+	if err != nil {
+		b.WriteString(fmt.Sprintf("版本：获取失败（%s）", err.Error()))
+		return b.String()
+	}
+	version := strings.TrimSpace(info.Version)
+	if version == "" {
+		version = "未知"
+	}
+	b.WriteString("版本：" + version)
+	if info.Branch != "" {
+		b.WriteString("（" + info.Branch + "）")
+	}
+
+	update, err := ins.Client.CheckSystemUpdate(ctx)
+	switch {
+	case err != nil:
+		b.WriteString(fmt.Sprintf("\n更新检查：失败（%s）", err.Error()))
+	case update.HasNewVersion:
+		b.WriteString("\n更新检查：有新版本 " + strings.TrimSpace(update.LastVersion))
+		if log := strings.TrimSpace(update.LastLog); log != "" {
+			b.WriteString("\n更新日志：\n" + truncateRunes(log, 300))
+		}
+	default:
+		b.WriteString("\n更新检查：已是最新版本")
+	}
+	return b.String()
+}
+
+func formatDependencyList(depType string, deps []Dependency) string {
+	if len(deps) == 0 {
+		return fmt.Sprintf("%s 依赖：暂无。", depType)
+	}
+
+	counts := make(map[int]int)
+	for _, d := range deps {
+		counts[d.Status]++
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s 依赖（共 %d 个，已安装 %d，失败 %d，安装中 %d）：",
+		depType, len(deps),
+		counts[DependencyStatusInstalled],
+		counts[DependencyStatusInstallFailed],
+		counts[DependencyStatusInstalling]+counts[DependencyStatusQueued],
+	))
+
+	// 失败优先展示，便于定位脚本报错原因。
+	ordered := make([]Dependency, 0, len(deps))
+	for _, d := range deps {
+		if d.Status == DependencyStatusInstallFailed {
+			ordered = append(ordered, d)
+		}
+	}
+	for _, d := range deps {
+		if d.Status != DependencyStatusInstallFailed {
+			ordered = append(ordered, d)
+		}
+	}
+
+	for i, d := range ordered {
+		if i >= maxDependencyListLines {
+			b.WriteString(fmt.Sprintf("\n…(其余 %d 个省略)", len(ordered)-i))
+			break
+		}
+		b.WriteString(fmt.Sprintf("\n- %s：%s", d.Name, dependencyStatusText(d.Status)))
+	}
+	return b.String()
+}
+
+func dependencyStatusText(status int) string {
+	switch status {
+	case DependencyStatusInstalling:
+		return "安装中"
+	case DependencyStatusInstalled:
+		return "已安装"
+	case DependencyStatusInstallFailed:
+		return "安装失败"
+	case DependencyStatusRemoving:
+		return "删除中"
+	case DependencyStatusRemoved:
+		return "已删除"
+	case DependencyStatusRemoveFailed:
+		return "删除失败"
+	case DependencyStatusQueued:
+		return "队列中"
+	case DependencyStatusCancelled:
+		return "已取消"
+	default:
+		return "未知(" + strconv.Itoa(status) + ")"
+	}
+}
@@ -0,0 +1,496 @@
+package unraid
+
+// alert.go 实现 Unraid 指标轮询告警（主机 CPU/有效内存/UPS 负载、单容器 CPU/内存、共享目录/缓存池使用率阈值），并提供静默/冷却能力。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+type AlertConfig struct {
+	Enabled bool
+
+	Interval time.Duration
+	Cooldown time.Duration
+	MuteFor  time.Duration
+
+	// CPUUsageThreshold/MemUsageThreshold 为主机 CPU/有效内存（扣除缓存）使用率阈值（百分比，<=0 表示不检查）。
+	CPUUsageThreshold float64
+	MemUsageThreshold float64
+	// UPSLoadThreshold 为 UPS 负载百分比阈值（<=0 表示不检查）。
+	UPSLoadThreshold float64
+	// ContainerCPUThreshold/ContainerMemThreshold 为单容器 CPU/内存（相对限制）阈值（<=0 表示不检查）。
+	ContainerCPUThreshold float64
+	ContainerMemThreshold float64
+
+	// ShareUsageThreshold/PoolUsageThreshold 为共享目录/缓存池使用率阈值（百分比，<=0 表示不检查）。
+	ShareUsageThreshold float64
+	PoolUsageThreshold  float64
+	// StorageRules 按名称覆盖单个共享目录或缓存池的阈值（名称不区分大小写）。
+	StorageRules []StorageAlertRule
+}
+
+type StorageAlertRule struct {
+	Name      string
+	Threshold float64
+}
+
+type AlertManagerDeps struct {
+	WeCom   core.WeComSender
+	UserIDs []string
+	// ToParty/ToTag 为按部门/标签直接投递的接收者（“|”分隔，可选），一条消息覆盖全部成员。
+	ToParty string
+	ToTag   string
+	Client  *Client
+	Config  AlertConfig
+
+	// Broadcast 为可选的广播出口（例如运维群聊）；配置后告警同时推送到该出口。
+	Broadcast core.Notifier
+}
+
+type AlertManager struct {
+	wecom     core.WeComSender
+	userIDs   []string
+	toParty   string
+	toTag     string
+	client    *Client
+	broadcast core.Notifier
+
+	cfg   AlertConfig
+	rules map[string]float64
+
+	mu        sync.Mutex
+	lastSent  map[string]time.Time
+	muteUntil time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	startOnce sync.Once
+}
+
+func NewAlertManager(deps AlertManagerDeps) *AlertManager {
+	rules := make(map[string]float64)
+	for _, r := range deps.Config.StorageRules {
+		name := strings.ToLower(strings.TrimSpace(r.Name))
+		if name == "" {
+			continue
+		}
+		rules[name] = r.Threshold
+	}
+
+	return &AlertManager{
+		wecom:     deps.WeCom,
+		userIDs:   uniqueNonEmpty(deps.UserIDs),
+		toParty:   strings.TrimSpace(deps.ToParty),
+		toTag:     strings.TrimSpace(deps.ToTag),
+		client:    deps.Client,
+		broadcast: deps.Broadcast,
+		cfg:       deps.Config,
+		rules:     rules,
+		lastSent:  make(map[string]time.Time),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+func (m *AlertManager) Enabled() bool { return m != nil && m.cfg.Enabled }
+
+func (m *AlertManager) Config() AlertConfig {
+	if m == nil {
+		return AlertConfig{}
+	}
+	return m.cfg
+}
+
+func (m *AlertManager) Start() {
+	if m == nil || !m.cfg.Enabled || m.wecom == nil || m.client == nil || (len(m.userIDs) == 0 && m.toParty == "" && m.toTag == "" && m.broadcast == nil) {
+		return
+	}
+	m.startOnce.Do(func() {
+		interval := m.cfg.Interval
+		if interval <= 0 {
+			interval = 5 * time.Minute
+		}
+		go m.loop(interval)
+	})
+}
+
+func (m *AlertManager) Close() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+func (m *AlertManager) Mute(until time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.muteUntil = until
+}
+
+func (m *AlertManager) Unmute() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.muteUntil = time.Time{}
+}
+
+func (m *AlertManager) MuteUntil() (time.Time, bool) {
+	if m == nil {
+		return time.Time{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muteUntil, time.Now().Before(m.muteUntil)
+}
+
+func (m *AlertManager) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 启动后先做一次检查，避免需要等待一个 interval。
+	m.checkOnce()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.checkOnce()
+		}
+	}
+}
+
+func (m *AlertManager) checkOnce() {
+	if _, ok := m.MuteUntil(); ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if m.cfg.CPUUsageThreshold > 0 || m.cfg.MemUsageThreshold > 0 || m.cfg.UPSLoadThreshold > 0 {
+		m.checkHost(ctx)
+	}
+	if m.cfg.ContainerCPUThreshold > 0 || m.cfg.ContainerMemThreshold > 0 {
+		m.checkContainers(ctx)
+	}
+	if m.storageEnabled() {
+		m.checkStorage(ctx)
+	}
+}
+
+type alertKind string
+
+const (
+	alertKindCPU          alertKind = "cpu"
+	alertKindMem          alertKind = "mem"
+	alertKindUPSLoad      alertKind = "ups_load"
+	alertKindContainerCPU alertKind = "container_cpu"
+	alertKindContainerMem alertKind = "container_mem"
+	alertKindShare        alertKind = "share"
+	alertKindPool         alertKind = "pool"
+)
+
+const alertMuteHint = "提示：如需静默请进入“菜单 → Unraid 容器 → 静默告警”（不要回复序号）。"
+
+func (m *AlertManager) checkHost(ctx context.Context) {
+	metrics, err := m.client.GetSystemMetrics(ctx)
+	if err != nil {
+		slog.Warn("unraid 告警检查失败：获取系统指标失败", "kind", "host", "error", err)
+		return
+	}
+
+	if t := m.cfg.CPUUsageThreshold; t > 0 && metrics.CPUPercentTotal >= t {
+		m.sendIfNotInCooldown(ctx, alertKindCPU, fmt.Sprintf(
+			"⚠️ Unraid 告警（CPU ≥ %.0f%%）\n\n当前：%.0f%%\n\n%s",
+			t, metrics.CPUPercentTotal, alertMuteHint,
+		))
+	}
+
+	// 内存优先使用“有效内存”（total-available），避免把页缓存计入占用导致误报。
+	mem, hasMem := metrics.MemoryPercent, metrics.MemoryTotal > 0
+	if metrics.HasMemoryEffective {
+		mem, hasMem = metrics.MemoryPercentEffective, true
+	}
+	if t := m.cfg.MemUsageThreshold; t > 0 && hasMem && mem >= t {
+		m.sendIfNotInCooldown(ctx, alertKindMem, fmt.Sprintf(
+			"⚠️ Unraid 告警（内存 ≥ %.0f%%）\n\n当前：%.0f%%（已用 %s / %s）\n\n%s",
+			t, mem, formatBytesIEC(metrics.MemoryUsedEffective), formatBytesIEC(metrics.MemoryTotal), alertMuteHint,
+		))
+	}
+
+	if t := m.cfg.UPSLoadThreshold; t > 0 {
+		var lines []string
+		for _, d := range metrics.UPSDevices {
+			if d.Power == nil || d.Power.LoadPercentage == nil || *d.Power.LoadPercentage < t {
+				continue
+			}
+			lines = append(lines, "- "+formatUPSDeviceInline(d))
+		}
+		if len(lines) > 0 {
+			m.sendIfNotInCooldown(ctx, alertKindUPSLoad, fmt.Sprintf(
+				"⚠️ Unraid 告警（UPS 负载 ≥ %.0f%%）\n\n%s\n\n%s",
+				t, strings.Join(lines, "\n"), alertMuteHint,
+			))
+		}
+	}
+}
+
+func (m *AlertManager) checkContainers(ctx context.Context) {
+	stats, err := m.client.ListContainerResourceStats(ctx)
+	if err != nil {
+		slog.Warn("unraid 告警检查失败：获取容器资源统计失败", "kind", "container", "error", err)
+		return
+	}
+
+	if t := m.cfg.ContainerCPUThreshold; t > 0 {
+		var hits []ContainerResourceStats
+		for _, s := range stats {
+			if s.HasCPU && s.CPUPercent >= t {
+				hits = append(hits, s)
+			}
+		}
+		sort.SliceStable(hits, func(i, j int) bool { return hits[i].CPUPercent > hits[j].CPUPercent })
+		if lines := limitAlertLines(len(hits), func(i int) string {
+			return fmt.Sprintf("- %s: %.0f%%", hits[i].Name, hits[i].CPUPercent)
+		}); len(lines) > 0 {
+			m.sendIfNotInCooldown(ctx, alertKindContainerCPU, fmt.Sprintf(
+				"⚠️ Unraid 告警（容器 CPU ≥ %.0f%%）\n\n%s\n\n%s",
+				t, strings.Join(lines, "\n"), alertMuteHint,
+			))
+		}
+	}
+
+	if t := m.cfg.ContainerMemThreshold; t > 0 {
+		var hits []ContainerResourceStats
+		for _, s := range stats {
+			if s.HasMem && s.MemLimit > 0 && s.MemPercent() >= t {
+				hits = append(hits, s)
+			}
+		}
+		sort.SliceStable(hits, func(i, j int) bool { return hits[i].MemPercent() > hits[j].MemPercent() })
+		if lines := limitAlertLines(len(hits), func(i int) string {
+			return fmt.Sprintf("- %s: %.0f%%（%s / %s）", hits[i].Name, hits[i].MemPercent(), formatBytesIEC(hits[i].MemUsage), formatBytesIEC(hits[i].MemLimit))
+		}); len(lines) > 0 {
+			m.sendIfNotInCooldown(ctx, alertKindContainerMem, fmt.Sprintf(
+				"⚠️ Unraid 告警（容器内存 ≥ %.0f%%）\n\n%s\n\n%s",
+				t, strings.Join(lines, "\n"), alertMuteHint,
+			))
+		}
+	}
+}
+
+// limitAlertLines 生成最多 8 行告警明细，超出部分合并为一行省略提示。
+func limitAlertLines(n int, line func(i int) string) []string {
+	const maxLines = 8
+	var lines []string
+	for i := 0; i < n; i++ {
+		if i >= maxLines {
+			lines = append(lines, fmt.Sprintf("…(其余 %d 个省略)", n-i))
+			break
+		}
+		lines = append(lines, line(i))
+	}
+	return lines
+}
+
+// thresholdFor 返回指定共享目录/缓存池的阈值：优先按名称规则，否则使用该类别的默认阈值。
+func (m *AlertManager) thresholdFor(name string, fallback float64) float64 {
+	if v, ok := m.rules[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return v
+	}
+	return fallback
+}
+
+// storageEnabled 表示是否有共享目录/缓存池阈值需要检查；全部关闭时不再查询存储用量。
+func (m *AlertManager) storageEnabled() bool {
+	if m.cfg.ShareUsageThreshold > 0 || m.cfg.PoolUsageThreshold > 0 {
+		return true
+	}
+	for _, t := range m.rules {
+		if t > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *AlertManager) checkStorage(ctx context.Context) {
+	report, err := m.client.GetStorageReport(ctx)
+	if err != nil {
+		slog.Warn("unraid 告警检查失败：获取存储用量失败", "kind", "storage", "error", err)
+		return
+	}
+
+	if lines := m.storageHits(alertKindShare, report.Shares, m.cfg.ShareUsageThreshold); len(lines) > 0 {
+		m.send(ctx, alertKindShare, fmt.Sprintf(
+			"⚠️ Unraid 告警（共享目录空间）\n\n%s",
+			strings.Join(lines, "\n"),
+		))
+	}
+	if lines := m.storageHits(alertKindPool, report.Pools, m.cfg.PoolUsageThreshold); len(lines) > 0 {
+		m.send(ctx, alertKindPool, fmt.Sprintf(
+			"⚠️ Unraid 告警（缓存池空间）\n\n%s",
+			strings.Join(lines, "\n"),
+		))
+	}
+}
+
+// storageHits 返回超过阈值且不在冷却期内的共享目录/缓存池明细；冷却按名称分别计算，避免一个目录的冷却压住其他目录的告警。
+func (m *AlertManager) storageHits(kind alertKind, list []StorageUsage, fallback float64) []string {
+	type hit struct {
+		Usage     StorageUsage
+		Percent   float64
+		Threshold float64
+	}
+	var hits []hit
+	for _, u := range list {
+		threshold := m.thresholdFor(u.Name, fallback)
+		if threshold <= 0 {
+			continue
+		}
+		p := u.Percent()
+		if p < threshold || !m.acquireCooldown(string(kind)+":"+strings.ToLower(u.Name)) {
+			continue
+		}
+		hits = append(hits, hit{Usage: u, Percent: p, Threshold: threshold})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Percent > hits[j].Percent })
+
+	return limitAlertLines(len(hits), func(i int) string {
+		h := hits[i]
+		return fmt.Sprintf("- %s: %.0f%% ≥ %.0f%%，剩余 %s", h.Usage.Name, h.Percent, h.Threshold, formatBytesIEC(h.Usage.Free))
+	})
+}
+
+func (m *AlertManager) sendIfNotInCooldown(ctx context.Context, kind alertKind, content string) {
+	if m == nil || strings.TrimSpace(content) == "" {
+		return
+	}
+	if !m.acquireCooldown(string(kind)) {
+		return
+	}
+	m.send(ctx, kind, content)
+}
+
+// acquireCooldown 在 key 不处于冷却期时记录本次发送时间并返回 true。
+func (m *AlertManager) acquireCooldown(key string) bool {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cooldown := m.cfg.Cooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Minute
+	}
+	if last := m.lastSent[key]; !last.IsZero() && now.Sub(last) < cooldown {
+		return false
+	}
+	m.lastSent[key] = now
+	return true
+}
+
+func (m *AlertManager) send(ctx context.Context, kind alertKind, content string) {
+	// 单条消息同时投递成员/部门/标签，企业微信按 userid 去重，避免同时命中白名单与部门的成员收到两次。
+	if len(m.userIDs) > 0 || m.toParty != "" || m.toTag != "" {
+		_ = m.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  strings.Join(m.userIDs, "|"),
+			ToParty: m.toParty,
+			ToTag:   m.toTag,
+			Content: content,
+		})
+	}
+	if m.broadcast != nil {
+		if err := m.broadcast.Notify(ctx, content); err != nil {
+			slog.Error("unraid 告警广播失败", "kind", kind, "error", err)
+		}
+	}
+}
+
+func uniqueNonEmpty(ss []string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, s := range ss {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// entryCard 构建 Unraid 入口卡片；告警启用时附带告警状态与静默控制。
+func (p *Provider) entryCard() wecom.TemplateCard {
+	if !p.alerts.Enabled() {
+		return wecom.NewUnraidEntryCard()
+	}
+	opts := wecom.UnraidEntryCardOptions{ShowAlertActions: true, AlertDesc: "告警：已启用"}
+	if until, ok := p.alerts.MuteUntil(); ok {
+		opts.AlertMuted = true
+		opts.AlertDesc = "告警：已静默至 " + until.Format("01-02 15:04")
+	}
+	return wecom.NewUnraidEntryCardWithOptions(opts)
+}
+
+// formatAlertThreshold 将阈值格式化为“≥90%”；<=0 表示该项不检查。
+func formatAlertThreshold(t float64) string {
+	if t <= 0 {
+		return "（关闭）"
+	}
+	return fmt.Sprintf("≥%.0f%%", t)
+}
+
+func (p *Provider) sendAlertStatus(ctx context.Context, userID string) error {
+	if !p.alerts.Enabled() {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "告警未启用（unraid.alert.enabled=false）。"})
+	}
+	cfg := p.alerts.Config()
+
+	var b strings.Builder
+	b.WriteString("Unraid 告警状态")
+	b.WriteString(fmt.Sprintf("\n阈值：CPU%s MEM%s UPS负载%s", formatAlertThreshold(cfg.CPUUsageThreshold), formatAlertThreshold(cfg.MemUsageThreshold), formatAlertThreshold(cfg.UPSLoadThreshold)))
+	b.WriteString(fmt.Sprintf("\n容器阈值：CPU%s MEM%s", formatAlertThreshold(cfg.ContainerCPUThreshold), formatAlertThreshold(cfg.ContainerMemThreshold)))
+	b.WriteString(fmt.Sprintf("\n存储阈值：共享目录%s 缓存池%s", formatAlertThreshold(cfg.ShareUsageThreshold), formatAlertThreshold(cfg.PoolUsageThreshold)))
+	b.WriteString("\n轮询：")
+	b.WriteString(cfg.Interval.String())
+	b.WriteString(" | 冷却：")
+	b.WriteString(cfg.Cooldown.String())
+
+	if until, ok := p.alerts.MuteUntil(); ok {
+		b.WriteString("\n静默：是（至 ")
+		b.WriteString(until.Format("2006-01-02 15:04:05"))
+		b.WriteString("）")
+	} else {
+		b.WriteString("\n静默：否")
+	}
+
+	// 简单展示当前主机指标（不存储历史）。
+	if metrics, err := p.client.GetSystemMetrics(ctx); err == nil {
+		mem := metrics.MemoryPercent
+		if metrics.HasMemoryEffective {
+			mem = metrics.MemoryPercentEffective
+		}
+		b.WriteString(fmt.Sprintf("\n当前：CPU %.0f%% | MEM %.0f%%", metrics.CPUPercentTotal, mem))
+	}
+
+	return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: b.String()})
+}
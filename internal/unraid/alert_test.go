@@ -0,0 +1,475 @@
+package unraid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+func newStorageTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.Contains(req.Query, "shares") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"shares": []map[string]interface{}{
+					{"name": "media", "used": 700, "free": 300, "size": 0},
+					{"name": "appdata", "used": 85, "free": 15, "size": 0},
+					{"name": "isos", "used": 10, "free": 90, "size": 0},
+				},
+				"array": map[string]interface{}{
+					"state": "STARTED",
+					"capacity": map[string]interface{}{
+						"kilobytes": map[string]interface{}{"free": "1048576", "used": "3145728", "total": "4194304"},
+					},
+					"caches": []map[string]interface{}{
+						{"name": "cache", "fsSize": 1000, "fsUsed": 950, "fsFree": 50},
+						{"name": "cache2", "fsSize": nil, "fsUsed": nil, "fsFree": nil},
+					},
+				},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_GetStorageReport_SortedAndFormatted(t *testing.T) {
+	t.Parallel()
+
+	srv := newStorageTestServer(t)
+	c := NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client())
+
+	r, err := c.GetStorageReport(context.Background())
+	if err != nil {
+		t.Fatalf("GetStorageReport() error: %v", err)
+	}
+	if len(r.Shares) != 3 || r.Shares[0].Name != "appdata" || r.Shares[1].Name != "media" || r.Shares[2].Name != "isos" {
+		t.Fatalf("shares not sorted by usage: %#v", r.Shares)
+	}
+	if len(r.Pools) != 1 || r.Pools[0].Name != "cache" || r.Pools[0].Size != 1000*1024 {
+		t.Fatalf("unexpected pools: %#v", r.Pools)
+	}
+
+	got := formatStorageReport(r)
+	for _, want := range []string{
+		"阵列: 3.00GiB / 4.00GiB（75.0%），剩余 1.00GiB（STARTED）",
+		"- cache: 950.00KiB / 1000.00KiB（95.0%），剩余 50.00KiB",
+		"- appdata: 已用 85.00KiB，剩余 15.00KiB（85.0%）",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("report missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAlertManager_StorageThresholdsRulesAndCooldown(t *testing.T) {
+	t.Parallel()
+
+	srv := newStorageTestServer(t)
+	rec := &recordWeCom{}
+	m := NewAlertManager(AlertManagerDeps{
+		WeCom:   rec,
+		UserIDs: []string{"u1", "u1", "", "u2"},
+		ToParty: "2",
+		Client:  NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		Config: AlertConfig{
+			Enabled:             true,
+			Cooldown:            time.Hour,
+			ShareUsageThreshold: 90,
+			PoolUsageThreshold:  90,
+			StorageRules:        []StorageAlertRule{{Name: "AppData", Threshold: 80}, {Name: "media", Threshold: 60}},
+		},
+	})
+
+	m.checkOnce()
+	texts := rec.Texts()
+	if len(texts) != 2 {
+		t.Fatalf("want 2 alerts (share + pool), got %d: %#v", len(texts), texts)
+	}
+	// 成员与部门合并为同一条消息，避免既在白名单又在部门内的成员重复收到。
+	if texts[0].ToUser != "u1|u2" || texts[0].ToParty != "2" {
+		t.Fatalf("recipients = %q / %q, want u1|u2 / 2", texts[0].ToUser, texts[0].ToParty)
+	}
+	share, pool := texts[0].Content, texts[1].Content
+	if !strings.Contains(share, "共享目录空间") || !strings.Contains(share, "- appdata: 85% ≥ 80%") || !strings.Contains(share, "- media: 70% ≥ 60%") || strings.Contains(share, "isos") {
+		t.Fatalf("unexpected share alert: %s", share)
+	}
+	if !strings.Contains(pool, "缓存池空间") || !strings.Contains(pool, "- cache: 95% ≥ 90%") {
+		t.Fatalf("unexpected pool alert: %s", pool)
+	}
+
+	// 冷却期内不重复发送。
+	m.checkOnce()
+	if got := len(rec.Texts()); got != 2 {
+		t.Fatalf("alerts after cooldown check = %d, want 2", got)
+	}
+}
+
+func TestAlertManager_StorageCooldownPerName(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	shares := []map[string]interface{}{
+		{"name": "media", "used": 95, "free": 5, "size": 0},
+		{"name": "appdata", "used": 10, "free": 90, "size": 0},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"shares": shares,
+				"array":  map[string]interface{}{"state": "STARTED"},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	m := NewAlertManager(AlertManagerDeps{
+		WeCom:   rec,
+		UserIDs: []string{"u"},
+		Client:  NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		Config:  AlertConfig{Enabled: true, Cooldown: time.Hour, ShareUsageThreshold: 90},
+	})
+
+	m.checkOnce()
+	if texts := rec.Texts(); len(texts) != 1 || !strings.Contains(texts[0].Content, "- media: 95%") {
+		t.Fatalf("unexpected first alert: %#v", texts)
+	}
+
+	// media 仍在冷却期内，新越过阈值的 appdata 应单独告警。
+	mu.Lock()
+	shares[1] = map[string]interface{}{"name": "appdata", "used": 92, "free": 8, "size": 0}
+	mu.Unlock()
+	m.checkOnce()
+	texts := rec.Texts()
+	if len(texts) != 2 || !strings.Contains(texts[1].Content, "- appdata: 92%") || strings.Contains(texts[1].Content, "media") {
+		t.Fatalf("unexpected second alert: %#v", texts)
+	}
+
+	m.checkOnce()
+	if got := len(rec.Texts()); got != 2 {
+		t.Fatalf("alerts after cooldown check = %d, want 2", got)
+	}
+}
+
+func TestUPSWatcher_TransitionsAndThresholds(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	device := map[string]interface{}{}
+	setDevice := func(status string, charge, runtime float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		device = map[string]interface{}{
+			"id": "ups1", "name": "APC", "status": status,
+			"battery": map[string]interface{}{"chargeLevel": charge, "estimatedRuntime": runtime},
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.Contains(req.Query, "upsDevices") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"upsDevices": []map[string]interface{}{device}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	w := NewUPSWatcher(UPSWatcherDeps{
+		WeCom:   rec,
+		UserIDs: []string{"u"},
+		Client:  NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		Config: UPSConfig{
+			Enabled:          true,
+			ChargeThreshold:  50,
+			RuntimeThreshold: 10 * time.Minute,
+		},
+		ShutdownPlanEnabled: true,
+	})
+
+	steps := []struct {
+		status  string
+		charge  float64
+		runtime float64
+		want    []string
+	}{
+		{"ONLINE", 100, 3600, nil},
+		{"ONBATT", 80, 1800, []string{"市电中断"}},
+		{"ONBATT", 75, 1700, nil},
+		{"ONBATT", 40, 900, []string{"电池电量 40% 低于阈值 50%"}},
+		{"ONBATT LOWBATT", 20, 300, []string{"电池电量低", "剩余续航 5 分钟 低于阈值 10 分钟"}},
+		{"ONLINE", 20, 300, []string{"市电已恢复"}},
+		{"ONLINE", 60, 3600, nil},
+	}
+	for i, s := range steps {
+		setDevice(s.status, s.charge, s.runtime)
+		before := len(rec.Texts())
+		w.checkOnce()
+		texts := rec.Texts()[before:]
+		if len(s.want) == 0 {
+			if len(texts) != 0 {
+				t.Fatalf("step %d: want no alert, got %#v", i, texts)
+			}
+			continue
+		}
+		if len(texts) != 1 {
+			t.Fatalf("step %d: want 1 alert, got %#v", i, texts)
+		}
+		for _, want := range s.want {
+			if !strings.Contains(texts[0].Content, want) {
+				t.Fatalf("step %d: alert missing %q: %s", i, want, texts[0].Content)
+			}
+		}
+	}
+
+	// 电池供电类告警附带关机预案按钮，市电恢复不附带。
+	cards := rec.Cards()
+	if len(cards) != 3 {
+		t.Fatalf("want 3 shutdown plan cards, got %d", len(cards))
+	}
+	if cards[0].Card["button_list"].([]map[string]interface{})[0]["key"] != wecom.EventKeyUnraidUPSShutdownPlan {
+		t.Fatalf("unexpected card: %#v", cards[0].Card)
+	}
+}
+
+func TestProvider_ShutdownPlan_ConfirmAndSummary(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	var ran []string
+	var mu sync.Mutex
+	step := func(name string, err error) ShutdownStep {
+		return ShutdownStep{Name: name, Run: func(context.Context) error {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			return err
+		}}
+	}
+	p := NewProvider(ProviderDeps{
+		WeCom: rec,
+		State: store,
+		ShutdownPlan: ShutdownPlan{Steps: []ShutdownStep{
+			step("关闭 PVE home 虚拟机 101", errors.New("timeout")),
+			step("关闭 PVE home 虚拟机 102", nil),
+		}},
+	})
+
+	ctx := context.Background()
+	if ok, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidUPSShutdownPlan}); err != nil || !ok {
+		t.Fatalf("HandleEvent(shutdown_plan) ok=%v err=%v", ok, err)
+	}
+	if st, ok := store.Get("u"); !ok || st.Step != core.StepAwaitingConfirm || st.Action != core.ActionUnraidShutdownPlan {
+		t.Fatalf("state = %#v, want awaiting confirm for shutdown plan", st)
+	}
+	if ok, err := p.HandleConfirm(ctx, "u"); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+
+	var summary string
+	deadline := time.Now().Add(5 * time.Second)
+	for summary == "" && time.Now().Before(deadline) {
+		for _, m := range rec.Texts() {
+			if strings.Contains(m.Content, "关机预案执行结果") {
+				summary = m.Content
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(summary, "成功 1，失败 1") || !strings.Contains(summary, "虚拟机 101：失败") {
+		t.Fatalf("unexpected summary: %q", summary)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ran) != 2 {
+		t.Fatalf("ran = %v, want both steps executed", ran)
+	}
+}
+
+func TestAlertManager_HostAndContainerThresholdsWithMute(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data map[string]interface{}
+		switch {
+		case strings.Contains(req.Query, "metrics {"):
+			data = map[string]interface{}{"metrics": map[string]interface{}{
+				"cpu":    map[string]interface{}{"percentTotal": 95.2},
+				"memory": map[string]interface{}{"total": "1000", "used": "990", "free": "10", "available": "50", "percentTotal": 99},
+			}}
+		case strings.Contains(req.Query, "upsDevices"):
+			data = map[string]interface{}{"upsDevices": []map[string]interface{}{
+				{"id": "ups1", "name": "APC", "status": "ONLINE", "power": map[string]interface{}{"loadPercentage": 85}},
+			}}
+		case strings.Contains(req.Query, "names state stats"):
+			data = map[string]interface{}{"docker": map[string]interface{}{"containers": []map[string]interface{}{
+				{"names": []string{"/plex"}, "state": "RUNNING", "stats": map[string]interface{}{"cpuPercent": "150.5%", "memUsage": "900MiB / 1GiB"}},
+				{"names": []string{"/sonarr"}, "state": "RUNNING", "stats": map[string]interface{}{"cpuPercent": 2.0, "memUsage": 100, "memLimit": 1000}},
+				{"names": []string{"/old"}, "state": "EXITED", "stats": map[string]interface{}{"cpuPercent": 999.0}},
+			}}}
+		case strings.Contains(req.Query, "shares"):
+			// 存储阈值均未开启时不应查询存储用量。
+			t.Errorf("unexpected storage query")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	m := NewAlertManager(AlertManagerDeps{
+		WeCom:   rec,
+		UserIDs: []string{"u"},
+		Client:  NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		Config: AlertConfig{
+			Enabled:               true,
+			Cooldown:              time.Nanosecond,
+			MuteFor:               time.Hour,
+			CPUUsageThreshold:     90,
+			MemUsageThreshold:     90,
+			UPSLoadThreshold:      80,
+			ContainerCPUThreshold: 100,
+			ContainerMemThreshold: 80,
+		},
+	})
+
+	m.checkOnce()
+	var all []string
+	for _, msg := range rec.Texts() {
+		all = append(all, msg.Content)
+	}
+	joined := strings.Join(all, "\n---\n")
+	for _, want := range []string{
+		"CPU ≥ 90%）\n\n当前：95%",
+		"内存 ≥ 90%）\n\n当前：95%",
+		"UPS 负载 ≥ 80%",
+		"容器 CPU ≥ 100%）\n\n- plex: 150%",
+		"容器内存 ≥ 80%）\n\n- plex: 88%",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("alerts missing %q:\n%s", want, joined)
+		}
+	}
+	if len(all) != 5 || strings.Contains(joined, "sonarr") || strings.Contains(joined, "old") {
+		t.Fatalf("unexpected alerts:\n%s", joined)
+	}
+
+	store := core.NewStateStore(time.Minute)
+	t.Cleanup(store.Close)
+	p := NewProvider(ProviderDeps{WeCom: rec, Client: m.client, State: store, Alerts: m})
+	ctx := context.Background()
+	if ok, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidAlertMute}); err != nil || !ok {
+		t.Fatalf("HandleEvent(mute) ok=%v err=%v", ok, err)
+	}
+	before := len(rec.Texts())
+	m.checkOnce()
+	if got := len(rec.Texts()); got != before {
+		t.Fatalf("muted manager sent %d alerts", got-before)
+	}
+	if err := p.OnEnter(ctx, "u"); err != nil {
+		t.Fatalf("OnEnter() err=%v", err)
+	}
+	cards := rec.Cards()
+	buttons, _ := cards[len(cards)-1].Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 5 || buttons[4]["key"] != wecom.EventKeyUnraidAlertUnmute {
+		t.Fatalf("unexpected entry buttons: %#v", buttons)
+	}
+
+	if ok, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidAlertUnmute}); err != nil || !ok {
+		t.Fatalf("HandleEvent(unmute) ok=%v err=%v", ok, err)
+	}
+	before = len(rec.Texts())
+	m.checkOnce()
+	if got := len(rec.Texts()) - before; got != 5 {
+		t.Fatalf("after unmute want 5 alerts, got %d", got)
+	}
+
+	if ok, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidAlertStatus}); err != nil || !ok {
+		t.Fatalf("HandleEvent(status) ok=%v err=%v", ok, err)
+	}
+	texts := rec.Texts()
+	status := texts[len(texts)-1].Content
+	if !strings.Contains(status, "容器阈值：CPU≥100% MEM≥80%") || !strings.Contains(status, "存储阈值：共享目录（关闭） 缓存池（关闭）") {
+		t.Fatalf("unexpected alert status: %s", status)
+	}
+}
+
+func TestStatsSampler_TopNTrendAndRingWrap(t *testing.T) {
+	t.Parallel()
+
+	// 容量 = retention/interval + 1 = 3，写入 4 个采样后最旧的一条被覆盖。
+	s := NewStatsSampler(nil, SamplerConfig{Enabled: true, Interval: time.Minute, Retention: 2 * time.Minute})
+	now := time.Now()
+	mk := func(ago time.Duration, web, db float64, dbRx int64) statsSample {
+		return statsSample{At: now.Add(-ago), Containers: []ContainerResourceStats{
+			{Name: "web", CPUPercent: web, HasCPU: true, MemUsage: 100 << 20, HasMem: true},
+			{Name: "db", CPUPercent: db, HasCPU: true, MemUsage: 512 << 20, HasMem: true, NetRx: dbRx, NetTx: dbRx / 2, HasNet: true},
+		}}
+	}
+	s.add(mk(4*time.Minute, 99, 1, 0))
+	s.add(mk(3*time.Minute, 10, 40, 1<<20))
+	s.add(mk(2*time.Minute, 20, 50, 2<<20))
+	s.add(mk(1*time.Minute, 30, 60, 4<<20))
+
+	byCPU, byMem, samples := s.TopN(time.Hour, 10)
+	if samples != 3 {
+		t.Fatalf("samples = %d, want 3 (ring wrap)", samples)
+	}
+	if len(byCPU) != 2 || byCPU[0].Name != "db" || byCPU[0].CPU.Avg != 50 || byCPU[1].CPU.Max != 30 {
+		t.Fatalf("byCPU = %+v", byCPU)
+	}
+	if len(byMem) != 2 || byMem[0].Name != "db" {
+		t.Fatalf("byMem = %+v", byMem)
+	}
+
+	got := s.formatTrend("/db")
+	for _, want := range []string{"【资源趋势】db", "3 个采样", "最低 40.0% / 平均 50.0% / 最高 60.0%", "512.00MiB", "接收 +3.00MiB"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("trend missing %q:\n%s", want, got)
+		}
+	}
+	if got := s.formatTrend("nope"); !strings.Contains(got, "没有容器 nope") {
+		t.Fatalf("trend(nope) = %q", got)
+	}
+	if got := s.formatTopN(); !strings.Contains(got, "1. db 50.0%（60.0%）") {
+		t.Fatalf("topN = %q", got)
+	}
+
+	empty := NewStatsSampler(nil, SamplerConfig{Enabled: true})
+	if got := empty.formatTopN(); !strings.Contains(got, "暂无采样数据") {
+		t.Fatalf("empty topN = %q", got)
+	}
+}
@@ -25,10 +25,15 @@ type ClientConfig struct {
 	// - WebGUIEventsURL: 例如 http://<ip>/plugins/dynamix.docker.manager/include/Events.php（默认可从 Endpoint 推导）
 	// - WebGUICSRFToken: 抓包/页面里看到的 csrf_token（通常随登录会话变化）
 	// - WebGUICookie: 可选；如 WebGUI 需要登录，则需提供 Cookie 以通过鉴权
+	// - WebGUIUsername/WebGUIPassword: 可选；配置后自动登录获取 Cookie 与 csrf_token，会话失效时自动重新登录
+	// - WebGUILoginURL: 例如 http://<ip>/login（默认可从 Endpoint 推导）
 	WebGUICommandURL string
 	WebGUIEventsURL  string
 	WebGUICSRFToken  string
 	WebGUICookie     string
+	WebGUIUsername   string
+	WebGUIPassword   string
+	WebGUILoginURL   string
 
 	// 容器日志字段（默认 logs）。如 logs 返回对象，可通过 LogsPayloadField 指定承载日志文本的字段名。
 	LogsField        string
@@ -50,6 +55,8 @@ type ClientConfig struct {
 type Client struct {
 	cfg        ClientConfig
 	httpClient *http.Client
+
+	webgui webGUISession
 }
 
 func NewClient(cfg ClientConfig, httpClient *http.Client) *Client {
@@ -104,6 +111,9 @@ func applyClientDefaults(cfg *ClientConfig) {
 	if strings.TrimSpace(cfg.WebGUIEventsURL) == "" {
 		cfg.WebGUIEventsURL = deriveWebGUIEventsURL(cfg.Endpoint)
 	}
+	if strings.TrimSpace(cfg.WebGUILoginURL) == "" {
+		cfg.WebGUILoginURL = deriveWebGUILoginURL(cfg.Endpoint)
+	}
 }
 
 type graphQLRequest struct {
@@ -166,7 +176,67 @@ func (c *Client) ForceUpdateContainerByName(ctx context.Context, name string) er
 				}
 				return nil
 			}
-			return fmt.Errorf("%w；如目标 Unraid 未提供对应 GraphQL mutation，可在 config.yaml 配置 unraid.webgui_csrf_token / unraid.webgui_cookie / unraid.webgui_command_url 使用 WebGUI 更新", err)
+			return fmt.Errorf("%w；如目标 Unraid 未提供对应 GraphQL mutation，可在 config.yaml 配置 unraid.webgui_username/webgui_password（或 webgui_csrf_token / webgui_cookie）使用 WebGUI 更新", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// StartContainerByName 启动已停止的容器；容器已在运行时视为成功。
+func (c *Client) StartContainerByName(ctx context.Context, name string) error {
+	id, err := c.findContainerIDByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := c.startContainer(ctx, id); err != nil {
+		if errors.Is(err, ErrAlreadyStarted) {
+			return nil
+		}
+		if isMaybeUnsupportedGraphQL(err) && c.canWebGUIEvents() {
+			return c.doWebGUIEvent(ctx, "start", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// PauseContainerByName 暂停运行中的容器；容器已暂停时视为成功。
+func (c *Client) PauseContainerByName(ctx context.Context, name string) error {
+	id, err := c.findContainerIDByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := c.pauseContainer(ctx, id); err != nil {
+		if errors.Is(err, ErrAlreadyPaused) {
+			return nil
+		}
+		if isMaybeUnsupportedGraphQL(err) {
+			if c.canWebGUIEvents() {
+				return c.doWebGUIEvent(ctx, "pause", id)
+			}
+			return fmt.Errorf("%w；如目标 Unraid 未提供 pause mutation，可在 config.yaml 配置 unraid.webgui_username/webgui_password（或 webgui_csrf_token / webgui_cookie）使用 WebGUI 兜底", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// UnpauseContainerByName 恢复已暂停的容器。
+func (c *Client) UnpauseContainerByName(ctx context.Context, name string) error {
+	id, err := c.findContainerIDByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := c.unpauseContainer(ctx, id); err != nil {
+		if errors.Is(err, ErrNotPaused) {
+			return fmt.Errorf("容器未处于暂停状态：%s", name)
+		}
+		if isMaybeUnsupportedGraphQL(err) {
+			if c.canWebGUIEvents() {
+				return c.doWebGUIEvent(ctx, "unpause", id)
+			}
+			return fmt.Errorf("%w；如目标 Unraid 未提供 unpause mutation，可在 config.yaml 配置 unraid.webgui_username/webgui_password（或 webgui_csrf_token / webgui_cookie）使用 WebGUI 兜底", err)
 		}
 		return err
 	}
@@ -651,6 +721,8 @@ func (c *Client) GetContainerLogsByName(ctx context.Context, name string, tail i
 var (
 	ErrAlreadyStopped = errors.New("container already stopped")
 	ErrAlreadyStarted = errors.New("container already started")
+	ErrAlreadyPaused  = errors.New("container already paused")
+	ErrNotPaused      = errors.New("container not paused")
 )
 
 type containerInfo struct {
@@ -759,6 +831,42 @@ func (c *Client) startContainer(ctx context.Context, id string) error {
 	return nil
 }
 
+func (c *Client) pauseContainer(ctx context.Context, id string) error {
+	const q = `mutation Pause($dockerId: PrefixedID!) { docker { pause(id: $dockerId) { id state status } } }`
+	var resp struct {
+		Docker struct {
+			Pause struct {
+				State string `json:"state"`
+			} `json:"pause"`
+		} `json:"docker"`
+	}
+	if err := c.do(ctx, q, map[string]interface{}{"dockerId": id}, &resp); err != nil {
+		if strings.Contains(err.Error(), "already") && strings.Contains(err.Error(), "paused") {
+			return ErrAlreadyPaused
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Client) unpauseContainer(ctx context.Context, id string) error {
+	const q = `mutation Unpause($dockerId: PrefixedID!) { docker { unpause(id: $dockerId) { id state status } } }`
+	var resp struct {
+		Docker struct {
+			Unpause struct {
+				State string `json:"state"`
+			} `json:"unpause"`
+		} `json:"docker"`
+	}
+	if err := c.do(ctx, q, map[string]interface{}{"dockerId": id}, &resp); err != nil {
+		if strings.Contains(err.Error(), "not paused") {
+			return ErrNotPaused
+		}
+		return err
+	}
+	return nil
+}
+
 func normalizePrefixedID(id string) string {
 	if parts := strings.SplitN(id, ":", 2); len(parts) == 2 {
 		return parts[1]
@@ -877,6 +985,26 @@ func deriveWebGUIEventsURL(endpoint string) string {
 	return u.String()
 }
 
+func deriveWebGUILoginURL(endpoint string) string {
+	raw := strings.TrimSpace(endpoint)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || strings.TrimSpace(u.Scheme) == "" || strings.TrimSpace(u.Host) == "" {
+		return ""
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if strings.HasSuffix(path, "/graphql") {
+		path = strings.TrimSuffix(path, "/graphql")
+	}
+	u.Path = strings.TrimSuffix(path, "/") + "/login"
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
 func isMaybeUnsupportedGraphQL(err error) bool {
 	if err == nil {
 		return false
@@ -889,11 +1017,11 @@ func isMaybeUnsupportedGraphQL(err error) bool {
 }
 
 func (c *Client) canWebGUICommand() bool {
-	return strings.TrimSpace(c.cfg.WebGUICommandURL) != "" && strings.TrimSpace(c.cfg.WebGUICSRFToken) != ""
+	return strings.TrimSpace(c.cfg.WebGUICommandURL) != "" && c.hasWebGUIAuth()
 }
 
 func (c *Client) canWebGUIEvents() bool {
-	return strings.TrimSpace(c.cfg.WebGUIEventsURL) != "" && strings.TrimSpace(c.cfg.WebGUICSRFToken) != ""
+	return strings.TrimSpace(c.cfg.WebGUIEventsURL) != "" && c.hasWebGUIAuth()
 }
 
 func (c *Client) webGUIRestartContainer(ctx context.Context, name string) error {
@@ -914,48 +1042,14 @@ func (c *Client) doWebGUICommand(ctx context.Context, cmd string) error {
 	if cmdURL == "" {
 		cmdURL = deriveWebGUICommandURL(c.cfg.Endpoint)
 	}
-	csrf := strings.TrimSpace(c.cfg.WebGUICSRFToken)
-	if cmdURL == "" || csrf == "" {
-		return errors.New("未配置 WebGUI StartCommand 兜底（需配置 unraid.webgui_csrf_token，可选 unraid.webgui_cookie / unraid.webgui_command_url）")
+	if cmdURL == "" || !c.hasWebGUIAuth() {
+		return errors.New("未配置 WebGUI StartCommand 兜底（需配置 unraid.webgui_username/webgui_password 或 unraid.webgui_csrf_token，可选 unraid.webgui_cookie / unraid.webgui_command_url）")
 	}
 
 	form := url.Values{}
 	form.Set("cmd", cmd)
 	form.Set("start", "0")
-	form.Set("csrf_token", csrf)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cmdURL, strings.NewReader(form.Encode()))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-	if cookie := strings.TrimSpace(c.cfg.WebGUICookie); cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
-
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
-	body := strings.TrimSpace(string(b))
-
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("unraid webgui http status %d: %s", res.StatusCode, body)
-	}
-
-	contentType := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Type")))
-	bodyLower := strings.ToLower(body)
-	if strings.Contains(bodyLower, "invalid csrf") || strings.Contains(bodyLower, "csrf") && strings.Contains(bodyLower, "invalid") {
-		return errors.New("unraid webgui csrf_token 无效或已过期")
-	}
-	if strings.Contains(contentType, "text/html") && strings.Contains(bodyLower, "login") && strings.Contains(bodyLower, "password") {
-		return errors.New("unraid webgui 可能未登录（请配置 unraid.webgui_cookie）或 csrf_token 已失效")
-	}
-	return nil
+	return c.postWebGUIForm(ctx, cmdURL, form)
 }
 
 func (c *Client) doWebGUIEvent(ctx context.Context, action string, containerID string) error {
@@ -963,9 +1057,8 @@ func (c *Client) doWebGUIEvent(ctx context.Context, action string, containerID s
 	if eventsURL == "" {
 		eventsURL = deriveWebGUIEventsURL(c.cfg.Endpoint)
 	}
-	csrf := strings.TrimSpace(c.cfg.WebGUICSRFToken)
-	if eventsURL == "" || csrf == "" {
-		return errors.New("未配置 WebGUI Events 兜底（需配置 unraid.webgui_csrf_token，可选 unraid.webgui_cookie / unraid.webgui_events_url）")
+	if eventsURL == "" || !c.hasWebGUIAuth() {
+		return errors.New("未配置 WebGUI Events 兜底（需配置 unraid.webgui_username/webgui_password 或 unraid.webgui_csrf_token，可选 unraid.webgui_cookie / unraid.webgui_events_url）")
 	}
 	if strings.TrimSpace(containerID) == "" {
 		return errors.New("container id 不能为空")
@@ -974,40 +1067,7 @@ func (c *Client) doWebGUIEvent(ctx context.Context, action string, containerID s
 	form := url.Values{}
 	form.Set("action", action)
 	form.Set("container", containerID)
-	form.Set("csrf_token", csrf)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eventsURL, strings.NewReader(form.Encode()))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-	if cookie := strings.TrimSpace(c.cfg.WebGUICookie); cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
-
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
-	body := strings.TrimSpace(string(b))
-
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("unraid webgui http status %d: %s", res.StatusCode, body)
-	}
-
-	contentType := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Type")))
-	bodyLower := strings.ToLower(body)
-	if strings.Contains(bodyLower, "invalid csrf") || strings.Contains(bodyLower, "csrf") && strings.Contains(bodyLower, "invalid") {
-		return errors.New("unraid webgui csrf_token 无效或已过期")
-	}
-	if strings.Contains(contentType, "text/html") && strings.Contains(bodyLower, "login") && strings.Contains(bodyLower, "password") {
-		return errors.New("unraid webgui 可能未登录（请配置 unraid.webgui_cookie）或 csrf_token 已失效")
-	}
-	return nil
+	return c.postWebGUIForm(ctx, eventsURL, form)
 }
 
 func (c *Client) queryContainerExtraByName(ctx context.Context, name string, fieldName string, fieldExpr string) (containerInfo, interface{}, error) {
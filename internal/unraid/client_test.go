@@ -3,11 +3,14 @@ package unraid
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestClient_RestartStopForceUpdate(t *testing.T) {
@@ -764,3 +767,252 @@ func TestClient_ConfigOverride_ContainerStatsScalar(t *testing.T) {
 		t.Fatalf("GetContainerStatsByName() stats nil")
 	}
 }
+
+func TestClient_StartPauseUnpause(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var mutations []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		q := req.Query
+
+		switch {
+		case strings.Contains(q, "docker { containers"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"docker": map[string]interface{}{
+						"containers": []map[string]interface{}{
+							{"id": "docker:abc", "names": []string{"app"}, "state": "exited", "status": "Exited"},
+						},
+					},
+				},
+			})
+			return
+		case strings.Contains(q, "mutation Start"), strings.Contains(q, "mutation Unpause"):
+			name := "Start"
+			if strings.Contains(q, "mutation Unpause") {
+				name = "Unpause"
+			}
+			mu.Lock()
+			mutations = append(mutations, name)
+			mu.Unlock()
+			if got, _ := req.Variables["dockerId"].(string); got != "abc" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"docker": map[string]interface{}{}},
+			})
+			return
+		case strings.Contains(q, "mutation Pause"):
+			mu.Lock()
+			mutations = append(mutations, "Pause")
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []map[string]interface{}{
+					{"message": "Container abc is already paused"},
+				},
+			})
+			return
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []map[string]interface{}{
+					{"message": "unexpected query"},
+				},
+			})
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{
+		Endpoint: srv.URL,
+		APIKey:   "k",
+		Origin:   "o",
+	}, srv.Client())
+
+	ctx := context.Background()
+	if err := c.StartContainerByName(ctx, "app"); err != nil {
+		t.Fatalf("StartContainerByName() error: %v", err)
+	}
+	if err := c.PauseContainerByName(ctx, "app"); err != nil {
+		t.Fatalf("PauseContainerByName(already paused) error: %v", err)
+	}
+	if err := c.UnpauseContainerByName(ctx, "app"); err != nil {
+		t.Fatalf("UnpauseContainerByName() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(mutations, ","); got != "Start,Pause,Unpause" {
+		t.Fatalf("mutations = %q, want Start,Pause,Unpause", got)
+	}
+}
+
+func TestClient_WebGUI_AutoLoginAndRelogin(t *testing.T) {
+	t.Parallel()
+
+	var logins int32
+	var valid atomic.Value // 当前会话有效的 csrf_token
+	valid.Store("")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.Form.Get("username") != "root" || r.Form.Get("password") != "pw" {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte(`<form action="/login"><input name="username"><input type="password" name="password"></form>`))
+				return
+			}
+			n := atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "unraid_abc", Value: fmt.Sprintf("sess%d", n)})
+			http.Redirect(w, r, "/Main", http.StatusFound)
+		case "/Main":
+			n := atomic.LoadInt32(&logins)
+			if r.Header.Get("Cookie") != fmt.Sprintf("unraid_abc=sess%d", n) {
+				http.Redirect(w, r, "/login", http.StatusFound)
+				return
+			}
+			tok := fmt.Sprintf("TOK%d", n)
+			valid.Store(tok)
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprintf(w, `<html><script>var csrf_token = "%s";</script></html>`, tok)
+		case "/plugins/dynamix.docker.manager/include/Events.php":
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// 与 Unraid local_prepend.php 一致：token 无效时返回空的 200 响应。
+			if tok := r.Form.Get("csrf_token"); tok == "" || tok != valid.Load().(string) {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{
+		Endpoint:        srv.URL + "/graphql",
+		APIKey:          "k",
+		WebGUICSRFToken: "stale",
+		WebGUIUsername:  "root",
+		WebGUIPassword:  "pw",
+	}, srv.Client())
+	if !c.canWebGUIEvents() {
+		t.Fatalf("canWebGUIEvents() = false, want true")
+	}
+
+	ctx := context.Background()
+	// 静态 token 已失效：自动登录后重试成功。
+	if err := c.doWebGUIEvent(ctx, "restart", "abc"); err != nil {
+		t.Fatalf("doWebGUIEvent() error: %v", err)
+	}
+	// 会话已缓存：不再登录。
+	if err := c.doWebGUIEvent(ctx, "restart", "abc"); err != nil {
+		t.Fatalf("doWebGUIEvent() error: %v", err)
+	}
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Fatalf("logins = %d, want 1", got)
+	}
+
+	// 服务端会话轮换：旧 token 被拒绝后重新登录。
+	valid.Store("rotated")
+	if err := c.doWebGUIEvent(ctx, "restart", "abc"); err != nil {
+		t.Fatalf("doWebGUIEvent() after rotation error: %v", err)
+	}
+	if got := atomic.LoadInt32(&logins); got != 2 {
+		t.Fatalf("logins = %d, want 2", got)
+	}
+
+	bad := NewClient(ClientConfig{
+		Endpoint:       srv.URL + "/graphql",
+		APIKey:         "k",
+		WebGUIUsername: "root",
+		WebGUIPassword: "wrong",
+	}, srv.Client())
+	err := bad.doWebGUIEvent(ctx, "restart", "abc")
+	if err == nil || !strings.Contains(err.Error(), "用户名或密码错误") {
+		t.Fatalf("want login failure, got: %v", err)
+	}
+}
+
+func TestClient_WebGUI_LoginDoesNotHoldSessionLock(t *testing.T) {
+	t.Parallel()
+
+	var logins int32
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			atomic.AddInt32(&logins, 1)
+			entered <- struct{}{}
+			<-release
+			http.SetCookie(w, &http.Cookie{Name: "unraid_abc", Value: "sess"})
+			http.Redirect(w, r, "/Main", http.StatusFound)
+		case "/Main":
+			_, _ = w.Write([]byte(`<script>var csrf_token = "TOK";</script>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	t.Cleanup(unblock)
+
+	c := NewClient(ClientConfig{
+		Endpoint:       srv.URL + "/graphql",
+		APIKey:         "k",
+		WebGUIUsername: "root",
+		WebGUIPassword: "pw",
+	}, srv.Client())
+
+	ctx := context.Background()
+	results := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			csrf, _, err := c.webGUICredentials(ctx)
+			if err != nil {
+				csrf = err.Error()
+			}
+			results <- csrf
+		}()
+	}
+	<-entered
+
+	// 登录进行中：会话锁已释放，其他调用不会被阻塞。
+	locked := make(chan struct{})
+	go func() {
+		c.invalidateWebGUISession("other")
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatalf("session lock held during login")
+	}
+
+	unblock()
+	for i := 0; i < 2; i++ {
+		if got := <-results; got != "TOK" {
+			t.Fatalf("webGUICredentials() = %q, want TOK", got)
+		}
+	}
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Fatalf("logins = %d, want 1", got)
+	}
+}
@@ -0,0 +1,296 @@
+package unraid
+
+// group.go 实现容器组：按配置的容器列表（或名称正则/Docker label）组成一组，
+// 对整组按顺序执行启动/重启/停止/更新（容器间可设置间隔），并汇总为一条结果消息。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// groupOpTimeout 为容器组后台任务的整体超时。
+const groupOpTimeout = 60 * time.Minute
+
+// ContainerGroup 描述一个容器组。
+// 成员顺序：Containers 按配置顺序在前，Pattern/Label 匹配到的其余容器按名称排序追加在后。
+// 停止操作按逆序执行（先停依赖方，再停被依赖的基础服务）。
+type ContainerGroup struct {
+	ID   string
+	Name string
+
+	Containers []string
+	// Pattern 为容器名正则（可选）。
+	Pattern string
+	// Label 为 Docker label 匹配（可选）：key 或 key=value。
+	Label string
+
+	// Delay 为相邻两个容器操作之间的等待时间。
+	Delay time.Duration
+}
+
+type containerLabelInfo struct {
+	Name   string
+	Labels map[string]string
+}
+
+// listContainerLabels 查询所有容器名称与 labels（用于容器组的 label 匹配）。
+func (c *Client) listContainerLabels(ctx context.Context) ([]containerLabelInfo, error) {
+	const q = `query { docker { containers { names labels } } }`
+	var resp struct {
+		Docker struct {
+			Containers []struct {
+				Names  interface{}            `json:"names"`
+				Labels map[string]interface{} `json:"labels"`
+			} `json:"containers"`
+		} `json:"docker"`
+	}
+	if err := c.do(ctx, q, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var out []containerLabelInfo
+	for _, ct := range resp.Docker.Containers {
+		names := normalizeContainerNames(ct.Names)
+		if len(names) == 0 {
+			continue
+		}
+		labels := make(map[string]string, len(ct.Labels))
+		for k, v := range ct.Labels {
+			s, _ := stringifyGraphQLValue(v)
+			labels[k] = s
+		}
+		out = append(out, containerLabelInfo{Name: normalizeName(names[0]), Labels: labels})
+	}
+	return out, nil
+}
+
+// resolveGroupMembers 解析容器组成员（去重，保持顺序）。
+func (c *Client) resolveGroupMembers(ctx context.Context, g ContainerGroup) ([]string, error) {
+	seen := make(map[string]struct{})
+	var out []string
+	add := func(name string) {
+		name = normalizeName(name)
+		if name == "" {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	for _, n := range g.Containers {
+		add(n)
+	}
+
+	pattern := strings.TrimSpace(g.Pattern)
+	label := strings.TrimSpace(g.Label)
+	if pattern == "" && label == "" {
+		return out, nil
+	}
+
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("容器组 %s 的 pattern 不合法：%w", g.ID, err)
+		}
+	}
+	labelKey, labelValue, hasValue := strings.Cut(label, "=")
+
+	list, err := c.listContainerLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, ct := range list {
+		if re != nil && re.MatchString(ct.Name) {
+			matched = append(matched, ct.Name)
+			continue
+		}
+		if label != "" {
+			v, ok := ct.Labels[strings.TrimSpace(labelKey)]
+			if ok && (!hasValue || v == strings.TrimSpace(labelValue)) {
+				matched = append(matched, ct.Name)
+			}
+		}
+	}
+	sort.Strings(matched)
+	for _, n := range matched {
+		add(n)
+	}
+	return out, nil
+}
+
+func (p *Provider) findGroup(id string) (ContainerGroup, bool) {
+	for _, g := range p.groups {
+		if g.ID == id {
+			return g, true
+		}
+	}
+	return ContainerGroup{}, false
+}
+
+func groupDisplayName(g ContainerGroup) string {
+	if strings.TrimSpace(g.Name) != "" {
+		return g.Name
+	}
+	return g.ID
+}
+
+// handleGroupEvent 处理容器组相关 EventKey；返回 handled=false 表示非容器组事件。
+func (p *Provider) handleGroupEvent(ctx context.Context, userID string, key string) (bool, error) {
+	if key == wecom.EventKeyUnraidMenuGroups {
+		return true, p.sendGroupList(ctx, userID)
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyUnraidGroupSelectPrefix) {
+		id := strings.TrimPrefix(key, wecom.EventKeyUnraidGroupSelectPrefix)
+		g, ok := p.findGroup(id)
+		if !ok {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "容器组不存在，请返回后重试。"})
+		}
+		members, err := p.client.resolveGroupMembers(ctx, g)
+		if err != nil {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: fmt.Sprintf("解析容器组失败：%s", err.Error())})
+		}
+		p.state.Set(userID, core.ConversationState{ServiceKey: p.Key(), UnraidGroupID: g.ID})
+
+		content := fmt.Sprintf("【容器组】%s（%d 个容器，按顺序执行）", groupDisplayName(g), len(members))
+		for i, n := range members {
+			content += fmt.Sprintf("\n%d. %s", i+1, n)
+		}
+		if g.Delay > 0 {
+			content += fmt.Sprintf("\n容器间隔：%s", g.Delay)
+		}
+		if err := p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: truncateForWecom(content)}); err != nil {
+			return true, err
+		}
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+			ToUser: userID,
+			Card:   wecom.NewUnraidGroupActionCard(groupDisplayName(g), len(members)),
+		})
+	}
+
+	if strings.HasPrefix(key, wecom.EventKeyUnraidGroupActionPrefix) {
+		action := core.Action(strings.TrimPrefix(key, wecom.EventKeyUnraidGroupActionPrefix))
+		switch action {
+		case core.ActionUnraidStart, core.ActionUnraidRestart, core.ActionUnraidStop, core.ActionUnraidForceUpdate:
+		default:
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未知动作，请返回后重试。"})
+		}
+		state, ok := p.state.Get(userID)
+		if !ok || state.ServiceKey != p.Key() || state.UnraidGroupID == "" {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "会话已过期，请重新选择容器组。"})
+		}
+		g, ok := p.findGroup(state.UnraidGroupID)
+		if !ok {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "容器组不存在，请返回后重试。"})
+		}
+		state.Step = core.StepAwaitingConfirm
+		state.Action = action
+		p.state.Set(userID, state)
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+			ToUser: userID,
+			Card:   wecom.NewConfirmCard(action.DisplayName(), "容器组 "+groupDisplayName(g)),
+		})
+	}
+
+	return false, nil
+}
+
+func (p *Provider) sendGroupList(ctx context.Context, userID string) error {
+	if len(p.groups) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: "未配置容器组（可在 config.yaml 的 unraid.groups 中配置）。",
+		})
+	}
+	p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
+
+	var opts []wecom.UnraidGroupOption
+	for _, g := range p.groups {
+		opts = append(opts, wecom.UnraidGroupOption{ID: g.ID, Text: truncateRunes(groupDisplayName(g), 20)})
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidGroupListCard(opts),
+	})
+}
+
+// startGroupOperation 在确认后于后台按顺序对容器组执行动作，结束时发送一条汇总消息。
+func (p *Provider) startGroupOperation(ctx context.Context, userID string, groupID string, action core.Action) error {
+	g, ok := p.findGroup(groupID)
+	if !ok {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "容器组不存在，请返回后重试。"})
+	}
+	members, err := p.client.resolveGroupMembers(ctx, g)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: fmt.Sprintf("解析容器组失败：%s", err.Error())})
+	}
+	if len(members) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "容器组中没有匹配的容器。"})
+	}
+	if action == core.ActionUnraidStop {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+
+	name := groupDisplayName(g)
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: fmt.Sprintf("已开始%s容器组 %s：共 %d 个容器（按顺序执行），完成后发送汇总结果。", action.DisplayName(), name, len(members)),
+	}); err != nil {
+		return err
+	}
+
+	bg, cancel := context.WithTimeout(context.WithoutCancel(ctx), groupOpTimeout)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		results := p.runGroupSequential(bg, members, action, g.Delay)
+		title := fmt.Sprintf("容器组 %s %s结果", name, action.DisplayName())
+		if err := p.wecom.SendText(bg, wecom.TextMessage{
+			ToUser:  userID,
+			Content: truncateForWecom(formatOpResults(title, results, time.Since(start))),
+		}); err != nil {
+			slog.Error("unraid 容器组：发送汇总失败", "user_id", userID, "group", g.ID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// runGroupSequential 逐个执行动作（失败继续），相邻容器之间等待 delay。
+func (p *Provider) runGroupSequential(ctx context.Context, names []string, action core.Action, delay time.Duration) []containerOpResult {
+	results := make([]containerOpResult, 0, len(names))
+	for i, name := range names {
+		if i > 0 && delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+			case <-t.C:
+			}
+		}
+		if ctx.Err() != nil {
+			results = append(results, containerOpResult{Name: name, Skipped: true})
+			continue
+		}
+		start := time.Now()
+		err := p.execOperationAction(ctx, action, name)
+		results = append(results, containerOpResult{Name: name, Err: err, Cost: time.Since(start)})
+		if err != nil {
+			slog.Warn("unraid 容器组：容器操作失败", "container", name, "action", string(action), "error", err)
+		}
+	}
+	return results
+}
@@ -0,0 +1,267 @@
+package unraid
+
+// plugins.go 实现“插件与备份”：列出已安装插件（版本/可更新状态），并支持确认后发起闪存（/boot）备份。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+type PluginInfo struct {
+	Name    string
+	Version string
+
+	// LatestVersion/UpdateAvailable 仅在上游 API 提供对应字段时有效（HasUpdateInfo=true）。
+	LatestVersion   string
+	UpdateAvailable bool
+	HasUpdateInfo   bool
+}
+
+// ListPlugins 查询已安装插件（按名称排序）。
+// 优先请求带更新信息的字段；上游不支持时降级为仅名称与版本。
+func (c *Client) ListPlugins(ctx context.Context) ([]PluginInfo, error) {
+	type pluginItem struct {
+		Name            string `json:"name"`
+		Version         string `json:"version"`
+		LatestVersion   string `json:"latestVersion"`
+		UpdateAvailable *bool  `json:"updateAvailable"`
+	}
+	var resp struct {
+		Plugins []pluginItem `json:"plugins"`
+	}
+
+	hasUpdateInfo := true
+	if err := c.do(ctx, `query { plugins { name version latestVersion updateAvailable } }`, nil, &resp); err != nil {
+		if !isMaybeUnsupportedGraphQL(err) {
+			return nil, err
+		}
+		hasUpdateInfo = false
+		resp.Plugins = nil
+		if err := c.do(ctx, `query { plugins { name version } }`, nil, &resp); err != nil {
+			if isMaybeUnsupportedGraphQL(err) {
+				return nil, fmt.Errorf("插件查询失败：%w（目标 Unraid API 可能不支持 plugins 字段，请升级 Unraid Connect/API 插件）", err)
+			}
+			return nil, err
+		}
+	}
+
+	out := make([]PluginInfo, 0, len(resp.Plugins))
+	for _, it := range resp.Plugins {
+		name := strings.TrimSpace(it.Name)
+		if name == "" {
+			continue
+		}
+		p := PluginInfo{
+			Name:          name,
+			Version:       strings.TrimSpace(it.Version),
+			LatestVersion: strings.TrimSpace(it.LatestVersion),
+			HasUpdateInfo: hasUpdateInfo,
+		}
+		if it.UpdateAvailable != nil {
+			p.UpdateAvailable = *it.UpdateAvailable
+		} else if p.LatestVersion != "" && p.Version != "" && p.LatestVersion != p.Version {
+			p.UpdateAvailable = true
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+	return out, nil
+}
+
+// FlashBackupConfig 为闪存备份参数（对应 initiateFlashBackup 的 rclone 远端与路径）。
+type FlashBackupConfig struct {
+	RemoteName      string
+	SourcePath      string
+	DestinationPath string
+}
+
+func (c FlashBackupConfig) Configured() bool {
+	return strings.TrimSpace(c.RemoteName) != "" && strings.TrimSpace(c.DestinationPath) != ""
+}
+
+type FlashBackupStatus struct {
+	Status string
+	JobID  string
+}
+
+// InitiateFlashBackup 发起闪存备份（异步执行，返回任务状态与 jobId）。
+func (c *Client) InitiateFlashBackup(ctx context.Context, cfg FlashBackupConfig) (FlashBackupStatus, error) {
+	const q = `mutation InitiateFlashBackup($input: InitiateFlashBackupInput!) { initiateFlashBackup(input: $input) { status jobId } }`
+	source := strings.TrimSpace(cfg.SourcePath)
+	if source == "" {
+		source = "/boot"
+	}
+	vars := map[string]interface{}{
+		"input": map[string]interface{}{
+			"remoteName":      strings.TrimSpace(cfg.RemoteName),
+			"sourcePath":      source,
+			"destinationPath": strings.TrimSpace(cfg.DestinationPath),
+		},
+	}
+	var resp struct {
+		InitiateFlashBackup struct {
+			Status string      `json:"status"`
+			JobID  interface{} `json:"jobId"`
+		} `json:"initiateFlashBackup"`
+	}
+	if err := c.do(ctx, q, vars, &resp); err != nil {
+		if isMaybeUnsupportedGraphQL(err) {
+			return FlashBackupStatus{}, fmt.Errorf("闪存备份失败：%w（目标 Unraid API 可能不支持 initiateFlashBackup，请升级 Unraid Connect/API 插件）", err)
+		}
+		return FlashBackupStatus{}, err
+	}
+	jobID, _ := stringifyGraphQLValue(resp.InitiateFlashBackup.JobID)
+	return FlashBackupStatus{
+		Status: strings.TrimSpace(resp.InitiateFlashBackup.Status),
+		JobID:  strings.TrimSpace(jobID),
+	}, nil
+}
+
+// flashBackupRecord 记录最近一次闪存备份的提交结果（进程内，重启后丢失）。
+// initiateFlashBackup 仅返回提交时的状态与 jobId，备份在 Unraid 后台异步执行，这里不代表最终结果。
+type flashBackupRecord struct {
+	mu     sync.Mutex
+	at     time.Time
+	status FlashBackupStatus
+	err    error
+}
+
+func (r *flashBackupRecord) set(status FlashBackupStatus, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.at, r.status, r.err = time.Now(), status, err
+}
+
+func (r *flashBackupRecord) describe() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.at.IsZero() {
+		return "上次闪存备份：本次运行未提交"
+	}
+	if r.err != nil {
+		return fmt.Sprintf("上次闪存备份：%s 提交失败", r.at.Format("01-02 15:04"))
+	}
+	return fmt.Sprintf("上次闪存备份：%s %s", r.at.Format("01-02 15:04"), formatFlashBackupStatus(r.status))
+}
+
+// formatFlashBackupStatus 描述提交结果，例如“已提交（接口状态 STARTED，任务 job-42）”。
+func formatFlashBackupStatus(s FlashBackupStatus) string {
+	var details []string
+	if s.Status != "" {
+		details = append(details, "接口状态 "+s.Status)
+	}
+	if s.JobID != "" {
+		details = append(details, "任务 "+s.JobID)
+	}
+	if len(details) == 0 {
+		return "已提交"
+	}
+	return "已提交（" + strings.Join(details, "，") + "）"
+}
+
+func formatPluginList(plugins []PluginInfo) string {
+	var updatable int
+	var lines []string
+	for _, p := range plugins {
+		version := p.Version
+		if version == "" {
+			version = "未知版本"
+		}
+		line := fmt.Sprintf("- %s %s", p.Name, version)
+		if p.UpdateAvailable {
+			updatable++
+			if p.LatestVersion != "" {
+				line += fmt.Sprintf(" → %s（可更新）", p.LatestVersion)
+			} else {
+				line += "（可更新）"
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	header := fmt.Sprintf("【已安装插件】共 %d 个", len(plugins))
+	switch {
+	case len(plugins) > 0 && !plugins[0].HasUpdateInfo:
+		header += "（当前 API 未提供更新信息）"
+	case updatable > 0:
+		header += fmt.Sprintf("，%d 个可更新", updatable)
+	}
+	if len(lines) == 0 {
+		return header
+	}
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+func (p *Provider) sendPlugins(ctx context.Context, userID string) error {
+	p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
+
+	start := time.Now()
+	plugins, err := p.client.ListPlugins(ctx)
+	cost := time.Since(start).Milliseconds()
+	if err != nil {
+		if err := p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("查询失败（%dms）：%s", cost, err.Error()),
+		}); err != nil {
+			return err
+		}
+	} else if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: truncateForWecom(formatPluginList(plugins)),
+	}); err != nil {
+		return err
+	}
+
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidPluginsCard(p.lastFlashBackup.describe()),
+	})
+}
+
+func (p *Provider) prepareFlashBackup(ctx context.Context, userID string) error {
+	if !p.flashBackup.Configured() {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: "未配置闪存备份（请在 config.yaml 配置 unraid.flash_backup.remote_name / destination_path）。",
+		})
+	}
+	p.state.Set(userID, core.ConversationState{
+		ServiceKey: p.Key(),
+		Step:       core.StepAwaitingConfirm,
+		Action:     core.ActionUnraidFlashBackup,
+	})
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewConfirmCard(core.ActionUnraidFlashBackup.DisplayName(), p.flashBackup.RemoteName+":"+p.flashBackup.DestinationPath),
+	})
+}
+
+func (p *Provider) runFlashBackup(ctx context.Context, userID string) error {
+	if !p.flashBackup.Configured() {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未配置闪存备份。"})
+	}
+
+	start := time.Now()
+	status, err := p.client.InitiateFlashBackup(ctx, p.flashBackup)
+	cost := time.Since(start).Milliseconds()
+	p.lastFlashBackup.set(status, err)
+	if err != nil {
+		slog.Warn("unraid 闪存备份提交失败", "user_id", userID, "error", err)
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("闪存备份提交失败（%dms）：%s", cost, err.Error()),
+		})
+	}
+	return p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser: userID,
+		Content: fmt.Sprintf("闪存备份%s（%dms）\n目标：%s:%s\n备份在 Unraid 后台执行，完成情况请在 Unraid 通知中查看。",
+			formatFlashBackupStatus(status), cost, p.flashBackup.RemoteName, p.flashBackup.DestinationPath),
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/zcw199604/wecom-home-ops/internal/core"
 	"github.com/zcw199604/wecom-home-ops/internal/wecom"
@@ -19,34 +18,99 @@ type ProviderDeps struct {
 	WeCom  core.WeComSender
 	Client *Client
 	State  *core.StateStore
+
+	// UpdateAllStopOnFailure 为 true 时“全部更新”遇到失败即停止；默认失败跳过并继续后续容器。
+	UpdateAllStopOnFailure bool
+
+	// Groups 为配置的容器组（用于整组批量操作）。
+	Groups []ContainerGroup
+
+	// ShutdownPlan 为 UPS 断电时的关机预案（由 UPS 告警卡片触发，需确认）。
+	ShutdownPlan ShutdownPlan
+
+	// Alerts 为告警管理器（用于“告警状态/静默告警”）；nil 或未启用时不展示告警按钮。
+	Alerts *AlertManager
+
+	// FlashBackup 为闪存备份参数（未配置时“闪存备份”按钮提示配置）。
+	FlashBackup FlashBackupConfig
+
+	// Sampler 为容器资源采样器（用于“资源排行/资源趋势”）；nil 或未启用时提示开启。
+	Sampler *StatsSampler
+
+	// LogDelivery 控制容器日志/系统日志超长时的下发方式（truncate/split/file，默认 truncate）。
+	LogDelivery wecom.LongTextMode
 }
 
 type Provider struct {
 	wecom  core.WeComSender
 	client *Client
 	state  *core.StateStore
+
+	updateAllStopOnFailure bool
+	groups                 []ContainerGroup
+	shutdownPlan           ShutdownPlan
+	alerts                 *AlertManager
+	flashBackup            FlashBackupConfig
+	lastFlashBackup        *flashBackupRecord
+	sampler                *StatsSampler
+	logDelivery            wecom.LongTextMode
 }
 
 func NewProvider(deps ProviderDeps) *Provider {
 	return &Provider{
-		wecom:  deps.WeCom,
-		client: deps.Client,
-		state:  deps.State,
+		wecom:                  deps.WeCom,
+		client:                 deps.Client,
+		state:                  deps.State,
+		updateAllStopOnFailure: deps.UpdateAllStopOnFailure,
+		groups:                 deps.Groups,
+		shutdownPlan:           deps.ShutdownPlan,
+		alerts:                 deps.Alerts,
+		flashBackup:            deps.FlashBackup,
+		lastFlashBackup:        &flashBackupRecord{},
+		sampler:                deps.Sampler,
+		logDelivery:            deps.LogDelivery,
 	}
 }
 
-func (p *Provider) Key() string { return "unraid" }
+// 服务 key 与显示名（Provider 与包级 MenuService 共用）。
+const (
+	serviceKey         = "unraid"
+	serviceDisplayName = "Unraid 容器"
+)
+
+func (p *Provider) Key() string { return serviceKey }
 
-func (p *Provider) DisplayName() string { return "Unraid 容器" }
+func (p *Provider) DisplayName() string { return serviceDisplayName }
 
 func (p *Provider) EntryKeywords() []string {
 	return []string{"容器", "docker", "unraid"}
 }
 
+// MenuButton 贡献应用自定义菜单中的 Unraid 分组（实现 core.MenuContributor）。
+func (p *Provider) MenuButton() wecom.MenuButton { return menuButton() }
+
+// MenuService 返回菜单组装所需的服务描述，无需创建 Provider（供 --wecom-sync-menu 使用）。
+func MenuService() core.MenuService {
+	b := menuButton()
+	return core.MenuService{Key: serviceKey, DisplayName: serviceDisplayName, Button: &b}
+}
+
+func menuButton() wecom.MenuButton {
+	return wecom.MenuButton{
+		Name: "Unraid",
+		SubButtons: []wecom.MenuButton{
+			{Type: "click", Name: "进入菜单", Key: wecom.EventKeyServiceSelectPrefix + serviceKey},
+			{Type: "click", Name: "容器操作", Key: wecom.EventKeyUnraidMenuOps},
+			{Type: "click", Name: "容器查看", Key: wecom.EventKeyUnraidMenuView},
+			{Type: "click", Name: "系统监控", Key: wecom.EventKeyUnraidMenuSystem},
+		},
+	}
+}
+
 func (p *Provider) OnEnter(ctx context.Context, userID string) error {
 	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
 		ToUser: userID,
-		Card:   wecom.NewUnraidEntryCard(),
+		Card:   p.entryCard(),
 	})
 }
 
@@ -66,6 +130,10 @@ func (p *Provider) HandleText(ctx context.Context, userID, content string) (bool
 			})
 		}
 		state.Action = action
+		if action == core.ActionUnraidViewTop {
+			p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
+			return true, p.execViewAndReply(ctx, userID, action, "", 0)
+		}
 
 		state.Step = core.StepAwaitingContainerName
 		p.state.Set(userID, state)
@@ -100,12 +168,22 @@ func (p *Provider) HandleText(ctx context.Context, userID, content string) (bool
 			})
 		}
 
+		if action == core.ActionUnraidViewSyslog {
+			return true, p.sendLogFileList(ctx, userID)
+		}
+		if action == core.ActionUnraidViewPlugins {
+			return true, p.sendPlugins(ctx, userID)
+		}
+
 		state.Step = ""
 		state.Action = ""
 		state.ContainerName = ""
 		p.state.Set(userID, state)
 		return true, p.execViewAndReply(ctx, userID, action, "", 0)
 
+	case core.StepAwaitingUnraidLogKeyword:
+		return true, p.handleLogKeyword(ctx, userID, state, content)
+
 	case core.StepAwaitingContainerName:
 		if state.Action == core.ActionUnraidViewSystemStats || state.Action == core.ActionUnraidViewSystemStatsDetail {
 			action := state.Action
@@ -173,11 +251,52 @@ func (p *Provider) HandleEvent(ctx context.Context, userID string, msg wecom.Inc
 		suffix := strings.TrimPrefix(key, wecom.EventKeyUnraidContainerPagePrefix)
 		return true, p.handleContainerPage(ctx, userID, suffix)
 	}
+	if handled, err := p.handleSyslogEvent(ctx, userID, key); handled {
+		return true, err
+	}
+	if handled, err := p.handleGroupEvent(ctx, userID, key); handled {
+		return true, err
+	}
 
 	switch key {
 	case wecom.EventKeyUnraidMenuOps:
 		p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
 		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: wecom.NewUnraidOpsCard()})
+	case wecom.EventKeyUnraidMenuOpsMore:
+		p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: wecom.NewUnraidOpsMoreCard()})
+	case wecom.EventKeyUnraidUpdateCheck:
+		return true, p.sendUpdatableContainers(ctx, userID)
+	case wecom.EventKeyUnraidUpdateAll:
+		return true, p.prepareUpdateAll(ctx, userID)
+	case wecom.EventKeyUnraidUPSShutdownPlan:
+		return true, p.prepareShutdownPlan(ctx, userID)
+	case wecom.EventKeyUnraidViewPlugins:
+		return true, p.sendPlugins(ctx, userID)
+	case wecom.EventKeyUnraidFlashBackup:
+		return true, p.prepareFlashBackup(ctx, userID)
+	case wecom.EventKeyUnraidAlertStatus:
+		return true, p.sendAlertStatus(ctx, userID)
+	case wecom.EventKeyUnraidAlertMute:
+		if !p.alerts.Enabled() {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "告警未启用，请在 config.yaml 打开 unraid.alert.enabled 并重启服务。"})
+		}
+		muteFor := p.alerts.Config().MuteFor
+		if muteFor <= 0 {
+			muteFor = 30 * time.Minute
+		}
+		until := time.Now().Add(muteFor)
+		p.alerts.Mute(until)
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("已静默告警，直到 %s。", until.Format("2006-01-02 15:04:05")),
+		})
+	case wecom.EventKeyUnraidAlertUnmute:
+		if !p.alerts.Enabled() {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "告警未启用。"})
+		}
+		p.alerts.Unmute()
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "已解除静默。"})
 	case wecom.EventKeyUnraidMenuView:
 		p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
 		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: wecom.NewUnraidViewCard()})
@@ -186,14 +305,17 @@ func (p *Provider) HandleEvent(ctx context.Context, userID string, msg wecom.Inc
 		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: wecom.NewUnraidSystemCard()})
 	case wecom.EventKeyUnraidBackToMenu:
 		p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
-		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: wecom.NewUnraidEntryCard()})
+		return true, p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{ToUser: userID, Card: p.entryCard()})
 
 	case wecom.EventKeyUnraidRestart, wecom.EventKeyUnraidStop, wecom.EventKeyUnraidForceUpdate,
-		wecom.EventKeyUnraidViewStatus, wecom.EventKeyUnraidViewSystemStats, wecom.EventKeyUnraidViewSystemStatsDetail, wecom.EventKeyUnraidViewLogs:
+		wecom.EventKeyUnraidStart, wecom.EventKeyUnraidPause, wecom.EventKeyUnraidUnpause,
+		wecom.EventKeyUnraidViewStatus, wecom.EventKeyUnraidViewSystemStats, wecom.EventKeyUnraidViewSystemStatsDetail, wecom.EventKeyUnraidViewLogs,
+		wecom.EventKeyUnraidViewStorage, wecom.EventKeyUnraidViewTrend, wecom.EventKeyUnraidViewTop:
 		action := core.ActionFromEventKey(key)
 
 		switch action {
-		case core.ActionUnraidViewSystemStats, core.ActionUnraidViewSystemStatsDetail:
+		case core.ActionUnraidViewSystemStats, core.ActionUnraidViewSystemStatsDetail, core.ActionUnraidViewStorage,
+			core.ActionUnraidViewTop:
 			return true, p.execViewAndReply(ctx, userID, action, "", 0)
 		default:
 			state := core.ConversationState{
@@ -254,7 +376,8 @@ func (p *Provider) handleContainerSelect(ctx context.Context, userID string, con
 	}
 
 	switch state.Action {
-	case core.ActionUnraidRestart, core.ActionUnraidStop, core.ActionUnraidForceUpdate:
+	case core.ActionUnraidRestart, core.ActionUnraidStop, core.ActionUnraidForceUpdate,
+		core.ActionUnraidStart, core.ActionUnraidPause, core.ActionUnraidUnpause:
 		state.Step = core.StepAwaitingConfirm
 		state.ContainerName = containerName
 		p.state.Set(userID, state)
@@ -263,7 +386,7 @@ func (p *Provider) handleContainerSelect(ctx context.Context, userID string, con
 			Card:   wecom.NewConfirmCard(state.Action.DisplayName(), containerName),
 		})
 
-	case core.ActionUnraidViewStatus:
+	case core.ActionUnraidViewStatus, core.ActionUnraidViewTrend:
 		action := state.Action
 		state.Step = ""
 		state.Action = ""
@@ -388,7 +511,8 @@ func (p *Provider) listContainerNames(ctx context.Context) ([]string, error) {
 func unraidActionNeedsContainer(action core.Action) bool {
 	switch action {
 	case core.ActionUnraidRestart, core.ActionUnraidStop, core.ActionUnraidForceUpdate,
-		core.ActionUnraidViewStatus, core.ActionUnraidViewLogs:
+		core.ActionUnraidStart, core.ActionUnraidPause, core.ActionUnraidUnpause,
+		core.ActionUnraidViewStatus, core.ActionUnraidViewLogs, core.ActionUnraidViewTrend:
 		return true
 	default:
 		return false
@@ -414,6 +538,8 @@ func unraidViewTextMenu() string {
 	return "Unraid 容器查看（文本模式）\n" +
 		"1. 查看状态\n" +
 		"2. 查看日志\n" +
+		"3. 资源趋势\n" +
+		"4. 资源排行\n" +
 		"\n提示：系统资源请从“系统监控”进入。\n" +
 		"\n回复序号选择。"
 }
@@ -422,6 +548,9 @@ func unraidSystemTextMenu() string {
 	return "Unraid 系统监控（文本模式）\n" +
 		"1. 系统资源概览\n" +
 		"2. 系统资源详情\n" +
+		"3. 系统日志\n" +
+		"4. 存储空间\n" +
+		"5. 插件与备份\n" +
 		"\n回复序号选择。"
 }
 
@@ -430,6 +559,9 @@ func unraidOpsTextMenu() string {
 		"1. 重启容器\n" +
 		"2. 停止容器\n" +
 		"3. 强制更新\n" +
+		"4. 启动容器\n" +
+		"5. 暂停容器\n" +
+		"6. 恢复容器\n" +
 		"\n回复序号选择。"
 }
 
@@ -440,6 +572,10 @@ func parseUnraidViewAction(input string) (core.Action, bool) {
 		return core.ActionUnraidViewStatus, true
 	case "2", "日志", "查看日志", "logs":
 		return core.ActionUnraidViewLogs, true
+	case "3", "趋势", "资源趋势", "trend":
+		return core.ActionUnraidViewTrend, true
+	case "4", "排行", "资源排行", "top":
+		return core.ActionUnraidViewTop, true
 	default:
 		return "", false
 	}
@@ -452,6 +588,12 @@ func parseUnraidSystemAction(input string) (core.Action, bool) {
 		return core.ActionUnraidViewSystemStats, true
 	case "2", "详情", "系统资源详情", "系统详情", "detail":
 		return core.ActionUnraidViewSystemStatsDetail, true
+	case "3", "系统日志", "syslog":
+		return core.ActionUnraidViewSyslog, true
+	case "4", "存储", "存储空间", "storage":
+		return core.ActionUnraidViewStorage, true
+	case "5", "插件", "插件与备份", "plugins":
+		return core.ActionUnraidViewPlugins, true
 	default:
 		return "", false
 	}
@@ -466,6 +608,12 @@ func parseUnraidOpsAction(input string) (core.Action, bool) {
 		return core.ActionUnraidStop, true
 	case "3", "强制更新", "更新", "update":
 		return core.ActionUnraidForceUpdate, true
+	case "4", "启动", "启动容器", "start":
+		return core.ActionUnraidStart, true
+	case "5", "暂停", "暂停容器", "pause":
+		return core.ActionUnraidPause, true
+	case "6", "恢复", "恢复容器", "取消暂停", "unpause":
+		return core.ActionUnraidUnpause, true
 	default:
 		return "", false
 	}
@@ -478,6 +626,19 @@ func (p *Provider) HandleConfirm(ctx context.Context, userID string) (bool, erro
 	}
 	p.state.Clear(userID)
 
+	if state.Action == core.ActionUnraidUpdateAll {
+		return true, p.startUpdateAll(ctx, userID)
+	}
+	if state.Action == core.ActionUnraidFlashBackup {
+		return true, p.runFlashBackup(ctx, userID)
+	}
+	if state.Action == core.ActionUnraidShutdownPlan {
+		return true, p.startShutdownPlan(ctx, userID)
+	}
+	if state.UnraidGroupID != "" {
+		return true, p.startGroupOperation(ctx, userID, state.UnraidGroupID, state.Action)
+	}
+
 	start := time.Now()
 	err := p.execOperationAction(ctx, state.Action, state.ContainerName)
 	cost := time.Since(start).Milliseconds()
@@ -504,6 +665,12 @@ func (p *Provider) execOperationAction(ctx context.Context, action core.Action,
 		return p.client.StopContainerByName(ctx, containerName)
 	case core.ActionUnraidForceUpdate:
 		return p.client.ForceUpdateContainerByName(ctx, containerName)
+	case core.ActionUnraidStart:
+		return p.client.StartContainerByName(ctx, containerName)
+	case core.ActionUnraidPause:
+		return p.client.PauseContainerByName(ctx, containerName)
+	case core.ActionUnraidUnpause:
+		return p.client.UnpauseContainerByName(ctx, containerName)
 	default:
 		return fmt.Errorf("未知动作: %s", action)
 	}
@@ -519,9 +686,29 @@ func (p *Provider) execViewAndReply(ctx context.Context, userID string, action c
 			Content: fmt.Sprintf("查询失败（%dms）：%s", cost, err.Error()),
 		})
 	}
+	if action == core.ActionUnraidViewSystemStatsDetail {
+		return p.wecom.SendMarkdown(ctx, wecom.MarkdownMessage{ToUser: userID, Content: truncateLinesForWecom(content)})
+	}
+	if action == core.ActionUnraidViewLogs {
+		return p.sendLogText(ctx, userID, containerName+".log", content)
+	}
 	return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: truncateForWecom(content)})
 }
 
+// sendLogText 按 log_delivery 经 core.SendLongText 下发日志：truncate 保留标题与最新内容，split/file 完整下发。
+func (p *Provider) sendLogText(ctx context.Context, userID string, filename string, content string) error {
+	return core.SendLongText(ctx, p.wecom, wecom.TextMessage{ToUser: userID, Content: content}, core.LongTextOptions{
+		Mode:     p.logDelivery,
+		Filename: filename,
+		KeepTail: true,
+	})
+}
+
+// truncatesLogs 表示日志输出是否截断为单条消息（未配置时默认截断）。
+func (p *Provider) truncatesLogs() bool {
+	return p.logDelivery == "" || p.logDelivery == wecom.LongTextModeTruncate
+}
+
 func (p *Provider) execViewAction(ctx context.Context, action core.Action, containerName string, logTail int) (string, error) {
 	switch action {
 	case core.ActionUnraidViewStatus:
@@ -543,7 +730,14 @@ func (p *Provider) execViewAction(ctx context.Context, action core.Action, conta
 		if err != nil {
 			return "", err
 		}
-		return formatSystemMetricsDetail(m), nil
+		return formatSystemMetricsDetailMarkdown(m, p.alerts.Config()), nil
+
+	case core.ActionUnraidViewStorage:
+		r, err := p.client.GetStorageReport(ctx)
+		if err != nil {
+			return "", err
+		}
+		return formatStorageReport(r), nil
 
 	case core.ActionUnraidViewLogs:
 		logs, err := p.client.GetContainerLogsByName(ctx, containerName, logTail)
@@ -552,17 +746,23 @@ func (p *Provider) execViewAction(ctx context.Context, action core.Action, conta
 		}
 		return formatContainerLogs(logs), nil
 
+	case core.ActionUnraidViewTrend, core.ActionUnraidViewTop:
+		if !p.sampler.Enabled() {
+			return "", errors.New("未启用资源采样（请在 config.yaml 打开 unraid.stats_sampler.enabled）")
+		}
+		if action == core.ActionUnraidViewTop {
+			return p.sampler.formatTopN(), nil
+		}
+		return p.sampler.formatTrend(containerName), nil
+
 	default:
 		return "", fmt.Errorf("未知动作: %s", action)
 	}
 }
 
 const (
-	defaultLogTail     = 50
-	maxLogTail         = 200
-	maxWecomTextBytes  = 1800
-	wecomTruncSuffix   = "\n…（已截断）"
-	wecomTruncMinBytes = 64
+	defaultLogTail = 50
+	maxLogTail     = 200
 )
 
 func parseContainerAndOptionalTail(input string, action core.Action) (container string, tail int, err error) {
@@ -641,10 +841,25 @@ func formatSystemMetricsOverview(m SystemMetrics) string {
 	return strings.Join(lines, "\n")
 }
 
-func formatSystemMetricsDetail(m SystemMetrics) string {
+// formatSystemMetricsDetailMarkdown 渲染 markdown 版系统资源详情：CPU/内存使用率达到告警阈值时显示为警示色
+// （阈值未配置或已关闭时按 90%）。
+func formatSystemMetricsDetailMarkdown(m SystemMetrics, cfg AlertConfig) string {
+	colored := func(v, threshold float64) string {
+		if threshold <= 0 {
+			threshold = 90
+		}
+		text := fmt.Sprintf("%.2f%%", v)
+		if v >= threshold {
+			return wecom.MarkdownColor(wecom.MarkdownColorWarning, text)
+		}
+		return text
+	}
+	pct := func(v float64) string { return colored(v, cfg.CPUUsageThreshold) }
+	memPct := func(v float64) string { return colored(v, cfg.MemUsageThreshold) }
+
 	var lines []string
-	lines = append(lines, "【系统资源详情】")
-	lines = append(lines, fmt.Sprintf("CPU 总使用率: %.2f%%", m.CPUPercentTotal))
+	lines = append(lines, "### Unraid 系统资源详情")
+	lines = append(lines, fmt.Sprintf("CPU 总使用率: %s", pct(m.CPUPercentTotal)))
 	if len(m.PerCPU) > 0 {
 		maxCores := len(m.PerCPU)
 		if maxCores > 8 {
@@ -672,7 +887,7 @@ func formatSystemMetricsDetail(m SystemMetrics) string {
 
 		for i := 0; i < maxCores; i++ {
 			c := m.PerCPU[i]
-			lines = append(lines, fmt.Sprintf("CPU%d: 总 %.2f%%（用户 %.2f%%，系统 %.2f%%，空闲 %.2f%%）", i, c.PercentTotal, c.PercentUser, c.PercentSystem, c.PercentIdle))
+			lines = append(lines, fmt.Sprintf("CPU%d: 总 %s（用户 %.2f%%，系统 %.2f%%，空闲 %.2f%%）", i, pct(c.PercentTotal), c.PercentUser, c.PercentSystem, c.PercentIdle))
 		}
 		if len(m.PerCPU) > maxCores {
 			lines = append(lines, fmt.Sprintf("…（仅展示前 %d 个 CPU 核）", maxCores))
@@ -687,7 +902,7 @@ func formatSystemMetricsDetail(m SystemMetrics) string {
 
 	lines = append(lines, fmt.Sprintf("内存总量: %s", formatBytesIEC(m.MemoryTotal)))
 	if m.HasMemoryEffective {
-		lines = append(lines, fmt.Sprintf("内存实际占用: %s（%.2f%%）", formatBytesIEC(m.MemoryUsedEffective), m.MemoryPercentEffective))
+		lines = append(lines, fmt.Sprintf("内存实际占用: %s（%s）", formatBytesIEC(m.MemoryUsedEffective), memPct(m.MemoryPercentEffective)))
 		if m.MemoryAvailable > 0 {
 			lines = append(lines, fmt.Sprintf("内存可用: %s", formatBytesIEC(m.MemoryAvailable)))
 		}
@@ -699,7 +914,7 @@ func formatSystemMetricsDetail(m SystemMetrics) string {
 		}
 		lines = append(lines, "提示：判断内存压力优先看“内存可用/内存实际占用”。")
 	} else {
-		lines = append(lines, fmt.Sprintf("内存已用: %s（%.2f%%）", formatBytesIEC(m.MemoryUsed), m.MemoryPercent))
+		lines = append(lines, fmt.Sprintf("内存已用: %s（%s）", formatBytesIEC(m.MemoryUsed), memPct(m.MemoryPercent)))
 		lines = append(lines, fmt.Sprintf("内存可用: %s；空闲: %s", formatBytesIEC(m.MemoryAvailable), formatBytesIEC(m.MemoryFree)))
 	}
 	if m.HasNetworkTotals {
@@ -830,36 +1045,33 @@ func formatContainerLogs(lg ContainerLogs) string {
 	return strings.Join(lines, "\n")
 }
 
+// truncateForWecom 按企业微信单条文本上限截断（保留开头）。
 func truncateForWecom(s string) string {
-	if len(s) <= maxWecomTextBytes {
-		return s
-	}
-	suffix := wecomTruncSuffix
-	maxBytes := maxWecomTextBytes
-	if maxBytes <= wecomTruncMinBytes {
-		maxBytes = wecomTruncMinBytes
-	}
-	if len(suffix) >= maxBytes {
-		return safeTruncateUTF8(s, maxBytes)
-	}
-	cut := safeTruncateUTF8(s, maxBytes-len(suffix))
-	return cut + suffix
+	return wecom.TruncateText(s, wecom.TextChunkBytes)
 }
 
-func safeTruncateUTF8(s string, maxBytes int) string {
-	if maxBytes <= 0 {
-		return ""
-	}
-	if len(s) <= maxBytes {
+// truncateLinesForWecom 按整行截断（用于 markdown，避免切断 <font> 等标签）；首行超长时退回按字节截断。
+func truncateLinesForWecom(s string) string {
+	if len(s) <= wecom.TextChunkBytes {
 		return s
 	}
-	b := []byte(s)
-	if maxBytes >= len(b) {
-		return s
+	budget := wecom.TextChunkBytes - len(wecom.TruncateSuffix)
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		need := len(line)
+		if b.Len() > 0 {
+			need++
+		}
+		if b.Len()+need > budget {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line)
 	}
-	b = b[:maxBytes]
-	for len(b) > 0 && !utf8.Valid(b) {
-		b = b[:len(b)-1]
+	if b.Len() == 0 {
+		return truncateForWecom(s)
 	}
-	return string(b)
+	return b.String() + wecom.TruncateSuffix
 }
@@ -3,6 +3,7 @@ package unraid
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,6 +19,8 @@ type recordWeCom struct {
 	mu    sync.Mutex
 	texts []wecom.TextMessage
 	cards []wecom.TemplateCardMessage
+
+	markdowns []wecom.MarkdownMessage
 }
 
 func (r *recordWeCom) SendText(_ context.Context, msg wecom.TextMessage) error {
@@ -34,6 +37,13 @@ func (r *recordWeCom) SendTemplateCard(_ context.Context, msg wecom.TemplateCard
 	return nil
 }
 
+func (r *recordWeCom) SendMarkdown(_ context.Context, msg wecom.MarkdownMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.markdowns = append(r.markdowns, msg)
+	return nil
+}
+
 func (r *recordWeCom) Texts() []wecom.TextMessage {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -317,3 +327,609 @@ func TestProvider_ClickMenuView_SendsTemplateCard(t *testing.T) {
 		t.Fatalf("view title = %q, want %q", title, "Unraid 容器查看")
 	}
 }
+
+func TestProvider_StartConfirmFlow(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var startHits int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(req.Query, "docker { containers"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"docker": map[string]interface{}{
+						"containers": []map[string]interface{}{
+							{"id": "docker:abc", "names": []string{"app"}, "state": "exited", "status": "Exited"},
+						},
+					},
+				},
+			})
+		case strings.Contains(req.Query, "mutation Start"):
+			mu.Lock()
+			startHits++
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"docker": map[string]interface{}{}},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:  rec,
+		Client: NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:  store,
+	})
+
+	ctx := context.Background()
+	userID := "u"
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidStart}); err != nil || !ok {
+		t.Fatalf("HandleEvent(start) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidContainerSelectPrefix + "app"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(select) ok=%v err=%v", ok, err)
+	}
+	if st, ok := store.Get(userID); !ok || st.Step != core.StepAwaitingConfirm || st.Action != core.ActionUnraidStart {
+		t.Fatalf("state = %#v, want awaiting confirm for start", st)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+
+	texts := rec.Texts()
+	if len(texts) == 0 || !strings.Contains(texts[len(texts)-1].Content, "执行成功") || !strings.Contains(texts[len(texts)-1].Content, "启动 app") {
+		t.Fatalf("want start success text, got: %#v", texts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if startHits != 1 {
+		t.Fatalf("start hits = %d, want 1", startHits)
+	}
+}
+
+func TestProvider_UpdatableListAndUpdateAll_SkipOnFailure(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var updated []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(req.Query, "containerUpdateStatuses"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"docker": map[string]interface{}{
+						"containerUpdateStatuses": []map[string]interface{}{
+							{"name": "gamma", "updateStatus": "UP_TO_DATE"},
+							{"name": "beta", "updateStatus": "REBUILD_READY"},
+							{"name": "/alpha", "updateStatus": "UPDATE_AVAILABLE"},
+						},
+					},
+				},
+			})
+		case strings.Contains(req.Query, "docker { containers"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"docker": map[string]interface{}{
+						"containers": []map[string]interface{}{
+							{"id": "docker:alpha", "names": []string{"alpha"}, "state": "running"},
+							{"id": "docker:beta", "names": []string{"beta"}, "state": "running"},
+							{"id": "docker:gamma", "names": []string{"gamma"}, "state": "running"},
+						},
+					},
+				},
+			})
+		case strings.Contains(req.Query, "mutation ForceUpdate"):
+			id, _ := req.Variables["v"].(string)
+			mu.Lock()
+			updated = append(updated, id)
+			mu.Unlock()
+			if id == "alpha" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"errors": []map[string]interface{}{{"message": "image pull failed"}},
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"docker": map[string]interface{}{}},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:  rec,
+		Client: NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:  store,
+	})
+
+	ctx := context.Background()
+	userID := "u"
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidUpdateCheck}); err != nil || !ok {
+		t.Fatalf("HandleEvent(update_check) ok=%v err=%v", ok, err)
+	}
+	texts := rec.Texts()
+	if len(texts) != 1 || !strings.Contains(texts[0].Content, "共 2 个\n- alpha\n- beta") {
+		t.Fatalf("want updatable list, got: %#v", texts)
+	}
+	cards := rec.Cards()
+	if len(cards) != 1 {
+		t.Fatalf("want 1 card, got %d", len(cards))
+	}
+	buttons, _ := cards[0].Card["button_list"].([]map[string]interface{})
+	if len(buttons) != 4 || buttons[0]["key"] != wecom.EventKeyUnraidContainerSelectPrefix+"alpha" || buttons[2]["key"] != wecom.EventKeyUnraidUpdateAll {
+		t.Fatalf("unexpected buttons: %#v", buttons)
+	}
+	if st, ok := store.Get(userID); !ok || st.Action != core.ActionUnraidForceUpdate {
+		t.Fatalf("state = %#v, want force_update action for per-container buttons", st)
+	}
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidUpdateAll}); err != nil || !ok {
+		t.Fatalf("HandleEvent(update_all) ok=%v err=%v", ok, err)
+	}
+	if st, ok := store.Get(userID); !ok || st.Step != core.StepAwaitingConfirm || st.Action != core.ActionUnraidUpdateAll {
+		t.Fatalf("state = %#v, want awaiting confirm for update_all", st)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+
+	var summary string
+	deadline := time.Now().Add(5 * time.Second)
+	for summary == "" && time.Now().Before(deadline) {
+		for _, m := range rec.Texts() {
+			if strings.Contains(m.Content, "全部更新结果") {
+				summary = m.Content
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if summary == "" {
+		t.Fatalf("summary not sent, texts: %#v", rec.Texts())
+	}
+	if !strings.Contains(summary, "成功 1，失败 1") || !strings.Contains(summary, "- alpha：失败") || !strings.Contains(summary, "- beta：成功") {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(updated, ",") != "alpha,beta" {
+		t.Fatalf("updated = %v, want sequential alpha,beta", updated)
+	}
+}
+
+func TestProvider_GroupStop_ReverseOrderSummary(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var stopped []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(req.Query, "docker { containers"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"docker": map[string]interface{}{
+						"containers": []map[string]interface{}{
+							{"id": "docker:db", "names": []string{"/db"}, "state": "running", "labels": map[string]interface{}{}},
+							{"id": "docker:sonarr", "names": []string{"/sonarr"}, "state": "running", "labels": map[string]interface{}{"stack": "media"}},
+							{"id": "docker:jellyfin", "names": []string{"/jellyfin"}, "state": "running", "labels": map[string]interface{}{"stack": "media"}},
+							{"id": "docker:other", "names": []string{"/other"}, "state": "running", "labels": map[string]interface{}{"stack": "misc"}},
+						},
+					},
+				},
+			})
+		case strings.Contains(req.Query, "mutation Stop"):
+			id, _ := req.Variables["dockerId"].(string)
+			mu.Lock()
+			stopped = append(stopped, id)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"docker": map[string]interface{}{"stop": map[string]interface{}{"id": id, "state": "EXITED"}}},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:  rec,
+		Client: NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:  store,
+		Groups: []ContainerGroup{{ID: "media", Name: "媒体栈", Containers: []string{"db"}, Label: "stack=media", Delay: 5 * time.Millisecond}},
+	})
+
+	ctx := context.Background()
+	userID := "u"
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidMenuGroups}); err != nil || !ok {
+		t.Fatalf("HandleEvent(groups) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidGroupSelectPrefix + "media"}); err != nil || !ok {
+		t.Fatalf("HandleEvent(group select) ok=%v err=%v", ok, err)
+	}
+	texts := rec.Texts()
+	if len(texts) != 1 || !strings.Contains(texts[0].Content, "1. db\n2. jellyfin\n3. sonarr") {
+		t.Fatalf("want ordered members, got: %#v", texts)
+	}
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidGroupActionPrefix + string(core.ActionUnraidStop)}); err != nil || !ok {
+		t.Fatalf("HandleEvent(group stop) ok=%v err=%v", ok, err)
+	}
+	if st, ok := store.Get(userID); !ok || st.Step != core.StepAwaitingConfirm || st.Action != core.ActionUnraidStop || st.UnraidGroupID != "media" {
+		t.Fatalf("state = %#v, want awaiting confirm for group stop", st)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+
+	var summary string
+	deadline := time.Now().Add(5 * time.Second)
+	for summary == "" && time.Now().Before(deadline) {
+		for _, m := range rec.Texts() {
+			if strings.Contains(m.Content, "容器组 媒体栈 停止结果") {
+				summary = m.Content
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if summary == "" {
+		t.Fatalf("summary not sent, texts: %#v", rec.Texts())
+	}
+	if !strings.Contains(summary, "成功 3，失败 0") {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(stopped, ",") != "sonarr,jellyfin,db" {
+		t.Fatalf("stopped = %v, want reverse order sonarr,jellyfin,db", stopped)
+	}
+}
+
+func TestProvider_PluginsFallbackAndFlashBackup(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var backupInput map[string]interface{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(req.Query, "latestVersion"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []map[string]interface{}{{"message": `Cannot query field "latestVersion" on type "Plugin".`}},
+			})
+		case strings.Contains(req.Query, "plugins"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"plugins": []map[string]interface{}{
+					{"name": "unassigned.devices", "version": "2024.10.01"},
+					{"name": "community.applications", "version": "2024.09.15"},
+				}},
+			})
+		case strings.Contains(req.Query, "initiateFlashBackup"):
+			mu.Lock()
+			backupInput, _ = req.Variables["input"].(map[string]interface{})
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"initiateFlashBackup": map[string]interface{}{"status": "STARTED", "jobId": "job-42"}},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:       rec,
+		Client:      NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:       store,
+		FlashBackup: FlashBackupConfig{RemoteName: "gdrive", DestinationPath: "unraid/flash"},
+	})
+
+	ctx := context.Background()
+	userID := "u"
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidViewPlugins}); err != nil || !ok {
+		t.Fatalf("HandleEvent(plugins) ok=%v err=%v", ok, err)
+	}
+	texts := rec.Texts()
+	if len(texts) != 1 || !strings.Contains(texts[0].Content, "共 2 个（当前 API 未提供更新信息）\n- community.applications 2024.09.15\n- unassigned.devices") {
+		t.Fatalf("unexpected plugin list: %#v", texts)
+	}
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidFlashBackup}); err != nil || !ok {
+		t.Fatalf("HandleEvent(flash_backup) ok=%v err=%v", ok, err)
+	}
+	if ok, err := p.HandleConfirm(ctx, userID); err != nil || !ok {
+		t.Fatalf("HandleConfirm() ok=%v err=%v", ok, err)
+	}
+	texts = rec.Texts()
+	if last := texts[len(texts)-1].Content; !strings.Contains(last, "闪存备份已提交（接口状态 STARTED，任务 job-42）") {
+		t.Fatalf("unexpected backup reply: %s", last)
+	}
+	mu.Lock()
+	if backupInput["sourcePath"] != "/boot" || backupInput["remoteName"] != "gdrive" {
+		t.Fatalf("unexpected backup input: %#v", backupInput)
+	}
+	mu.Unlock()
+
+	if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidViewPlugins}); err != nil || !ok {
+		t.Fatalf("HandleEvent(plugins) ok=%v err=%v", ok, err)
+	}
+	cards := rec.Cards()
+	title, _ := cards[len(cards)-1].Card["main_title"].(map[string]interface{})
+	if desc, _ := title["desc"].(string); !strings.Contains(desc, "已提交（接口状态 STARTED，任务 job-42）") {
+		t.Fatalf("plugins card should show last backup status, got %q", desc)
+	}
+}
+
+func TestProvider_SyslogTailPagingAndFilter(t *testing.T) {
+	t.Parallel()
+
+	var fileLines []string
+	for i := 1; i <= 120; i++ {
+		line := fmt.Sprintf("line %d", i)
+		if i%40 == 0 {
+			line += " kernel: ERROR disk"
+		}
+		fileLines = append(fileLines, line)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case strings.Contains(req.Query, "logFiles"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"logFiles": []map[string]interface{}{
+						{"name": "docker.log", "path": "/var/log/docker.log", "size": 10, "modifiedAt": "2026-01-01T00:00:00Z"},
+						{"name": "syslog", "path": "/var/log/syslog", "size": 2048, "modifiedAt": "2026-01-01T00:00:00Z"},
+					},
+				},
+			})
+		case strings.Contains(req.Query, "logFile("):
+			if req.Variables["path"] != "/var/log/syslog" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			lines := int(req.Variables["lines"].(float64))
+			start := len(fileLines) - lines + 1
+			if v, ok := req.Variables["startLine"].(float64); ok {
+				start = int(v)
+			}
+			if start < 1 {
+				start = 1
+			}
+			end := start + lines - 1
+			if end > len(fileLines) {
+				end = len(fileLines)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"logFile": map[string]interface{}{
+						"path":       "/var/log/syslog",
+						"content":    strings.Join(fileLines[start-1:end], "\n") + "\n",
+						"totalLines": len(fileLines),
+						"startLine":  start,
+					},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:  rec,
+		Client: NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:  store,
+	})
+
+	ctx := context.Background()
+	userID := "u"
+	lastText := func() string {
+		texts := rec.Texts()
+		if len(texts) == 0 {
+			return ""
+		}
+		return texts[len(texts)-1].Content
+	}
+	click := func(key string) {
+		t.Helper()
+		if ok, err := p.HandleEvent(ctx, userID, wecom.IncomingMessage{EventKey: key}); err != nil || !ok {
+			t.Fatalf("HandleEvent(%s) ok=%v err=%v", key, ok, err)
+		}
+	}
+
+	click(wecom.EventKeyUnraidViewSyslog)
+	if got := lastText(); !strings.Contains(got, "共 2 个文件\n- syslog（2.00KiB）\n- docker.log（10B）") {
+		t.Fatalf("unexpected log file list: %q", got)
+	}
+	cards := rec.Cards()
+	buttons, _ := cards[len(cards)-1].Card["button_list"].([]map[string]interface{})
+	if len(buttons) == 0 || buttons[0]["key"] != wecom.EventKeyUnraidLogFileSelectPrefix+"/var/log/syslog" {
+		t.Fatalf("unexpected file buttons: %#v", buttons)
+	}
+
+	click(wecom.EventKeyUnraidLogFileSelectPrefix + "/var/log/syslog")
+	if got := lastText(); !strings.HasPrefix(got, "【syslog】第 71-120 行 / 共 120 行\nline 71\n") || !strings.HasSuffix(got, "line 120 kernel: ERROR disk") {
+		t.Fatalf("unexpected tail: %q", got)
+	}
+
+	click(wecom.EventKeyUnraidLogFileOlder)
+	if got := lastText(); !strings.HasPrefix(got, "【syslog】第 21-70 行") {
+		t.Fatalf("unexpected older page: %q", got)
+	}
+	click(wecom.EventKeyUnraidLogFileOlder)
+	if got := lastText(); !strings.HasPrefix(got, "【syslog】第 1-20 行") || !strings.HasSuffix(got, "line 20") {
+		t.Fatalf("unexpected first page: %q", got)
+	}
+	click(wecom.EventKeyUnraidLogFileOlder)
+	if got := lastText(); got != "已到文件开头。" {
+		t.Fatalf("want start-of-file notice, got: %q", got)
+	}
+
+	click(wecom.EventKeyUnraidLogFileFilter)
+	if ok, err := p.HandleText(ctx, userID, "error"); err != nil || !ok {
+		t.Fatalf("HandleText(keyword) ok=%v err=%v", ok, err)
+	}
+	want := "【syslog】关键词「error」：最近 120 行中匹配 3 行\n40: line 40 kernel: ERROR disk\n80: line 80 kernel: ERROR disk\n120: line 120 kernel: ERROR disk"
+	if got := lastText(); got != want {
+		t.Fatalf("filter result = %q, want %q", got, want)
+	}
+}
+
+func TestProvider_SyslogTrimmedPageKeepsPagingContiguous(t *testing.T) {
+	t.Parallel()
+
+	var fileLines []string
+	for i := 1; i <= 120; i++ {
+		fileLines = append(fileLines, fmt.Sprintf("line %03d %s", i, strings.Repeat("x", 60)))
+	}
+
+	var starts []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.Contains(req.Query, "logFile(") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lines := int(req.Variables["lines"].(float64))
+		start := len(fileLines) - lines + 1
+		if v, ok := req.Variables["startLine"].(float64); ok {
+			start = int(v)
+		}
+		starts = append(starts, start)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"logFile": map[string]interface{}{
+					"path":       "/var/log/syslog",
+					"content":    strings.Join(fileLines[start-1:start+lines-1], "\n"),
+					"totalLines": len(fileLines),
+					"startLine":  start,
+				},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := &recordWeCom{}
+	store := core.NewStateStore(1 * time.Minute)
+	t.Cleanup(store.Close)
+
+	p := NewProvider(ProviderDeps{
+		WeCom:  rec,
+		Client: NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "k"}, srv.Client()),
+		State:  store,
+	})
+
+	ctx := context.Background()
+	if _, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidLogFileSelectPrefix + "/var/log/syslog"}); err != nil {
+		t.Fatalf("HandleEvent(select) err=%v", err)
+	}
+	texts := rec.Texts()
+	got := texts[len(texts)-1].Content
+	if len(got) > wecom.TextChunkBytes || !strings.HasSuffix(got, fileLines[119]) {
+		t.Fatalf("unexpected trimmed tail (len=%d): %q", len(got), got)
+	}
+	body := strings.SplitN(got, tailOmittedNote+"\n", 2)
+	if len(body) != 2 {
+		t.Fatalf("missing omitted note: %q", got)
+	}
+	shown := strings.Split(body[1], "\n")
+	first := 121 - len(shown)
+	if want := fmt.Sprintf("【syslog】第 %d-120 行 / 共 120 行", first); body[0] != want || shown[0] != fileLines[first-1] {
+		t.Fatalf("header = %q, first line = %q; want %q starting at line %d", body[0], shown[0], want, first)
+	}
+
+	if _, err := p.HandleEvent(ctx, "u", wecom.IncomingMessage{EventKey: wecom.EventKeyUnraidLogFileOlder}); err != nil {
+		t.Fatalf("HandleEvent(older) err=%v", err)
+	}
+	if want := first - syslogPageLines; starts[len(starts)-1] != want {
+		t.Fatalf("older page start = %d, want %d", starts[len(starts)-1], want)
+	}
+}
+
+func TestFormatSystemMetricsDetailMarkdown_ThresholdColorsAndLineTruncation(t *testing.T) {
+	t.Parallel()
+
+	warn := func(text string) string { return wecom.MarkdownColor(wecom.MarkdownColorWarning, text) }
+	m := SystemMetrics{CPUPercentTotal: 60, MemoryTotal: 100, MemoryUsed: 70, MemoryPercent: 70}
+
+	got := formatSystemMetricsDetailMarkdown(m, AlertConfig{CPUUsageThreshold: 50, MemUsageThreshold: 80})
+	if !strings.Contains(got, "CPU 总使用率: "+warn("60.00%")) || strings.Contains(got, warn("70.00%")) {
+		t.Fatalf("colors should follow configured thresholds, got: %s", got)
+	}
+	// 未配置阈值时按 90%。
+	if got := formatSystemMetricsDetailMarkdown(m, AlertConfig{}); strings.Contains(got, "<font") {
+		t.Fatalf("no value reaches default 90%%, got: %s", got)
+	}
+
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("CPU%d: 总 %s", i, warn("95.00%")))
+	}
+	out := truncateLinesForWecom(strings.Join(lines, "\n"))
+	if len(out) > wecom.TextChunkBytes || !strings.HasSuffix(out, wecom.TruncateSuffix) {
+		t.Fatalf("truncated len=%d, want <= %d with suffix", len(out), wecom.TextChunkBytes)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, wecom.TruncateSuffix), "\n") {
+		if !strings.HasSuffix(line, "</font>") {
+			t.Fatalf("line cut in the middle of a tag: %q", line)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package unraid
+
+// sampler.go 实现容器资源采样：定期采集所有运行中容器的 CPU/内存/网络到内存环形缓冲（可选落盘），
+// 并提供“资源排行”（最近一段时间按平均 CPU/内存排序）与单容器趋势（最低/平均/最高）。
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSamplerCapacity 限制环形缓冲的最大采样数，避免配置过长的保留时间占用过多内存。
+const maxSamplerCapacity = 10080
+
+type SamplerConfig struct {
+	Enabled bool
+
+	Interval  time.Duration
+	Retention time.Duration
+
+	// PersistPath 非空时每次采样后将缓冲写入该 JSON 文件，启动时自动加载（重启后趋势不中断）。
+	PersistPath string
+}
+
+type statsSample struct {
+	At         time.Time                `json:"at"`
+	Containers []ContainerResourceStats `json:"containers"`
+}
+
+// statsRing 为固定容量的环形缓冲，写满后覆盖最旧的采样。
+type statsRing struct {
+	buf  []statsSample
+	next int
+	full bool
+}
+
+func newStatsRing(capacity int) *statsRing {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &statsRing{buf: make([]statsSample, capacity)}
+}
+
+func (r *statsRing) add(s statsSample) {
+	r.buf[r.next] = s
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ordered 返回按时间先后排列的全部采样。
+func (r *statsRing) ordered() []statsSample {
+	if !r.full {
+		return append([]statsSample(nil), r.buf[:r.next]...)
+	}
+	out := make([]statsSample, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
+
+type StatsSampler struct {
+	client *Client
+	cfg    SamplerConfig
+
+	mu   sync.Mutex
+	ring *statsRing
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	startOnce sync.Once
+}
+
+func NewStatsSampler(client *Client, cfg SamplerConfig) *StatsSampler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = time.Hour
+	}
+	capacity := int(cfg.Retention/cfg.Interval) + 1
+	if capacity > maxSamplerCapacity {
+		capacity = maxSamplerCapacity
+	}
+	return &StatsSampler{
+		client: client,
+		cfg:    cfg,
+		ring:   newStatsRing(capacity),
+		stopCh: make(chan struct{}),
+	}
+}
+
+func (s *StatsSampler) Enabled() bool { return s != nil && s.cfg.Enabled }
+
+func (s *StatsSampler) Interval() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.Interval
+}
+
+func (s *StatsSampler) Start() {
+	if s == nil || !s.cfg.Enabled || s.client == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.load()
+		go s.loop()
+	})
+}
+
+func (s *StatsSampler) Close() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+func (s *StatsSampler) loop() {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	s.sampleOnce()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.sampleOnce()
+		}
+	}
+}
+
+func (s *StatsSampler) sampleOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stats, err := s.client.ListContainerResourceStats(ctx)
+	if err != nil {
+		slog.Warn("unraid 资源采样失败", "error", err)
+		return
+	}
+	s.add(statsSample{At: time.Now(), Containers: stats})
+	s.persist()
+}
+
+func (s *StatsSampler) add(sample statsSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ring.add(sample)
+}
+
+// samplesSince 返回 since 之后（含）的采样，按时间先后排列。
+func (s *StatsSampler) samplesSince(since time.Time) []statsSample {
+	s.mu.Lock()
+	all := s.ring.ordered()
+	s.mu.Unlock()
+
+	i := sort.Search(len(all), func(i int) bool { return !all[i].At.Before(since) })
+	return all[i:]
+}
+
+func (s *StatsSampler) persist() {
+	path := strings.TrimSpace(s.cfg.PersistPath)
+	if path == "" {
+		return
+	}
+	s.mu.Lock()
+	data, err := json.Marshal(s.ring.ordered())
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	// 先写临时文件再 rename，避免进程中断时留下半截 JSON。
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.Warn("unraid 资源采样落盘失败", "path", path, "error", err)
+		return
+	}
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		slog.Warn("unraid 资源采样落盘失败", "path", path, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		slog.Warn("unraid 资源采样落盘失败", "path", path, "error", err)
+	}
+}
+
+func (s *StatsSampler) load() {
+	path := strings.TrimSpace(s.cfg.PersistPath)
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("unraid 资源采样加载失败", "path", path, "error", err)
+		}
+		return
+	}
+	var samples []statsSample
+	if err := json.Unmarshal(data, &samples); err != nil {
+		slog.Warn("unraid 资源采样加载失败", "path", path, "error", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-s.cfg.Retention)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sample := range samples {
+		if sample.At.Before(cutoff) {
+			continue
+		}
+		s.ring.add(sample)
+	}
+	slog.Info("unraid 资源采样已加载", "path", path, "samples", len(s.ring.ordered()))
+}
+
+// aggStat 为一组数值的最低/平均/最高。
+type aggStat struct {
+	Min, Avg, Max float64
+	Count         int
+}
+
+func (a *aggStat) add(v float64) {
+	if a.Count == 0 || v < a.Min {
+		a.Min = v
+	}
+	if a.Count == 0 || v > a.Max {
+		a.Max = v
+	}
+	// 增量均值，避免额外保存求和。
+	a.Count++
+	a.Avg += (v - a.Avg) / float64(a.Count)
+}
+
+type containerAggregate struct {
+	Name    string
+	Samples int
+	CPU     aggStat
+	Mem     aggStat
+
+	// NetRx/NetTx 为窗口内首末采样的累计值之差（容器重启导致计数回绕时不展示）。
+	NetRx, NetTx int64
+	HasNet       bool
+}
+
+func aggregateSamples(samples []statsSample) map[string]*containerAggregate {
+	out := make(map[string]*containerAggregate)
+	type netPoint struct{ rx, tx int64 }
+	first := make(map[string]netPoint)
+	for _, sample := range samples {
+		for _, c := range sample.Containers {
+			agg, ok := out[c.Name]
+			if !ok {
+				agg = &containerAggregate{Name: c.Name}
+				out[c.Name] = agg
+			}
+			agg.Samples++
+			if c.HasCPU {
+				agg.CPU.add(c.CPUPercent)
+			}
+			if c.HasMem {
+				agg.Mem.add(float64(c.MemUsage))
+			}
+			if c.HasNet {
+				f, seen := first[c.Name]
+				if !seen {
+					first[c.Name] = netPoint{rx: c.NetRx, tx: c.NetTx}
+					continue
+				}
+				if c.NetRx >= f.rx && c.NetTx >= f.tx {
+					agg.NetRx, agg.NetTx, agg.HasNet = c.NetRx-f.rx, c.NetTx-f.tx, true
+				} else {
+					agg.HasNet = false
+				}
+			}
+		}
+	}
+	return out
+}
+
+// TopN 返回窗口内按平均 CPU 与平均内存排序的前 n 个容器，以及采样数。
+func (s *StatsSampler) TopN(window time.Duration, n int) (byCPU, byMem []containerAggregate, samples int) {
+	list := s.samplesSince(time.Now().Add(-window))
+	aggs := aggregateSamples(list)
+	for _, a := range aggs {
+		if a.CPU.Count > 0 {
+			byCPU = append(byCPU, *a)
+		}
+		if a.Mem.Count > 0 {
+			byMem = append(byMem, *a)
+		}
+	}
+	sort.Slice(byCPU, func(i, j int) bool {
+		if byCPU[i].CPU.Avg != byCPU[j].CPU.Avg {
+			return byCPU[i].CPU.Avg > byCPU[j].CPU.Avg
+		}
+		return byCPU[i].Name < byCPU[j].Name
+	})
+	sort.Slice(byMem, func(i, j int) bool {
+		if byMem[i].Mem.Avg != byMem[j].Mem.Avg {
+			return byMem[i].Mem.Avg > byMem[j].Mem.Avg
+		}
+		return byMem[i].Name < byMem[j].Name
+	})
+	if len(byCPU) > n {
+		byCPU = byCPU[:n]
+	}
+	if len(byMem) > n {
+		byMem = byMem[:n]
+	}
+	return byCPU, byMem, len(list)
+}
+
+// Trend 返回单个容器在窗口内的聚合结果。
+func (s *StatsSampler) Trend(name string, window time.Duration) (containerAggregate, int, bool) {
+	list := s.samplesSince(time.Now().Add(-window))
+	agg, ok := aggregateSamples(list)[normalizeName(name)]
+	if !ok {
+		return containerAggregate{}, len(list), false
+	}
+	return *agg, len(list), true
+}
+
+// 资源排行/趋势默认统计最近 1 小时。
+const (
+	statsReportWindow = time.Hour
+	statsReportTopN   = 10
+)
+
+func (s *StatsSampler) formatTopN() string {
+	byCPU, byMem, samples := s.TopN(statsReportWindow, statsReportTopN)
+	if samples == 0 {
+		return fmt.Sprintf("暂无采样数据（采样间隔 %s，请稍后再试）。", s.cfg.Interval)
+	}
+
+	lines := []string{fmt.Sprintf("【资源排行】最近 %s（%d 个采样）", formatWindowCN(statsReportWindow), samples)}
+	lines = append(lines, "", "CPU（平均，括号内为峰值）:")
+	for i, a := range byCPU {
+		lines = append(lines, fmt.Sprintf("%d. %s %.1f%%（%.1f%%）", i+1, a.Name, a.CPU.Avg, a.CPU.Max))
+	}
+	lines = append(lines, "", "内存（平均，括号内为峰值）:")
+	for i, a := range byMem {
+		lines = append(lines, fmt.Sprintf("%d. %s %s（%s）", i+1, a.Name, formatBytesIEC(int64(a.Mem.Avg)), formatBytesIEC(int64(a.Mem.Max))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *StatsSampler) formatTrend(name string) string {
+	agg, samples, ok := s.Trend(name, statsReportWindow)
+	if samples == 0 {
+		return fmt.Sprintf("暂无采样数据（采样间隔 %s，请稍后再试）。", s.cfg.Interval)
+	}
+	if !ok {
+		return fmt.Sprintf("最近 %s 内没有容器 %s 的采样（容器可能未运行）。", formatWindowCN(statsReportWindow), normalizeName(name))
+	}
+
+	lines := []string{fmt.Sprintf("【资源趋势】%s（最近 %s，%d 个采样）", agg.Name, formatWindowCN(statsReportWindow), agg.Samples)}
+	if agg.CPU.Count > 0 {
+		lines = append(lines, fmt.Sprintf("CPU：最低 %.1f%% / 平均 %.1f%% / 最高 %.1f%%", agg.CPU.Min, agg.CPU.Avg, agg.CPU.Max))
+	}
+	if agg.Mem.Count > 0 {
+		lines = append(lines, fmt.Sprintf("内存：最低 %s / 平均 %s / 最高 %s",
+			formatBytesIEC(int64(agg.Mem.Min)), formatBytesIEC(int64(agg.Mem.Avg)), formatBytesIEC(int64(agg.Mem.Max))))
+	}
+	if agg.HasNet {
+		lines = append(lines, fmt.Sprintf("网络：接收 +%s / 发送 +%s", formatBytesIEC(agg.NetRx), formatBytesIEC(agg.NetTx)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatWindowCN(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d 分钟", int(d/time.Minute))
+}
@@ -0,0 +1,146 @@
+package unraid
+
+// stats.go 批量查询所有运行中容器的 CPU/内存/网络占用（基于 docker.containers 的 stats 字段），供告警与资源采样使用。
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ContainerResourceStats struct {
+	Name string `json:"name"`
+
+	CPUPercent float64 `json:"cpu,omitempty"`
+	HasCPU     bool    `json:"has_cpu,omitempty"`
+
+	MemUsage int64 `json:"mem,omitempty"`
+	MemLimit int64 `json:"mem_limit,omitempty"`
+	HasMem   bool  `json:"has_mem,omitempty"`
+
+	// NetRx/NetTx 为容器启动以来的累计收发字节（docker stats netIO）。
+	NetRx  int64 `json:"net_rx,omitempty"`
+	NetTx  int64 `json:"net_tx,omitempty"`
+	HasNet bool  `json:"has_net,omitempty"`
+}
+
+// MemPercent 返回内存占用相对限制的百分比；限制未知时返回 0。
+func (s ContainerResourceStats) MemPercent() float64 {
+	if s.MemLimit <= 0 {
+		return 0
+	}
+	return float64(s.MemUsage) / float64(s.MemLimit) * 100
+}
+
+// ListContainerResourceStats 查询所有运行中容器的资源占用（按名称排序）。
+// stats 可能为对象（cpuPercent/memUsage/memLimit）或 JSON 字符串；memUsage 兼容 docker stats 的 "12MiB / 1GiB" 形式。
+func (c *Client) ListContainerResourceStats(ctx context.Context) ([]ContainerResourceStats, error) {
+	fieldName, fieldExpr, err := c.buildStatsFieldExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	q := fmt.Sprintf(`query { docker { containers { names state %s } } }`, fieldExpr)
+	var raw map[string]interface{}
+	if err := c.do(ctx, q, nil, &raw); err != nil {
+		return nil, wrapMaybeUnsupported(err, "资源统计", []string{
+			"unraid.stats_field",
+			"unraid.stats_fields",
+		})
+	}
+
+	dockerObj, _ := raw["docker"].(map[string]interface{})
+	list, _ := dockerObj["containers"].([]interface{})
+
+	var out []ContainerResourceStats
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if state, _ := m["state"].(string); state != "" && !strings.EqualFold(state, "running") {
+			continue
+		}
+		names := normalizeContainerNames(m["names"])
+		if len(names) == 0 {
+			continue
+		}
+		st, ok := parseContainerResourceStats(m[fieldName])
+		if !ok {
+			continue
+		}
+		st.Name = normalizeName(names[0])
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func parseContainerResourceStats(v interface{}) (ContainerResourceStats, bool) {
+	var obj map[string]interface{}
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		obj = vv
+	case string:
+		if err := json.Unmarshal([]byte(vv), &obj); err != nil {
+			return ContainerResourceStats{}, false
+		}
+	default:
+		return ContainerResourceStats{}, false
+	}
+
+	var out ContainerResourceStats
+	if p, ok := parsePercentish(obj["cpuPercent"]); ok {
+		out.CPUPercent = p
+		out.HasCPU = true
+	}
+
+	usage, limit := obj["memUsage"], obj["memLimit"]
+	if s, ok := usage.(string); ok && strings.Contains(s, "/") {
+		parts := strings.SplitN(s, "/", 2)
+		usage, limit = parts[0], parts[1]
+	}
+	if u, ok := parseBytesish(usage); ok {
+		out.MemUsage = u
+		out.HasMem = true
+		if l, ok := parseBytesish(limit); ok {
+			out.MemLimit = l
+		}
+	}
+	if s, ok := obj["netIO"].(string); ok {
+		if rx, tx, ok := parseDockerNetIO(s); ok {
+			out.NetRx, out.NetTx, out.HasNet = rx, tx, true
+		}
+	}
+	return out, out.HasCPU || out.HasMem
+}
+
+// parsePercentish 解析百分比：数字或 "12.5%" 形式的字符串。
+func parsePercentish(v interface{}) (float64, bool) {
+	switch vv := v.(type) {
+	case float64:
+		return vv, true
+	case string:
+		s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(vv), "%"))
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// parseBytesish 解析字节数：数字、数字字符串或带单位的人类可读字符串（12.3MiB）。
+func parseBytesish(v interface{}) (int64, bool) {
+	if n, ok := parseNumberishToInt64(v); ok {
+		return n, true
+	}
+	if s, ok := v.(string); ok {
+		return parseHumanBytes(s)
+	}
+	return 0, false
+}
@@ -0,0 +1,165 @@
+package unraid
+
+// storage.go 实现“存储空间”：通过 GraphQL shares/array 查询共享目录、缓存池与阵列容量（上游单位为 KiB）。
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type StorageUsage struct {
+	Name string
+	Used int64
+	Free int64
+	Size int64
+}
+
+// Percent 返回使用率（0~100）；容量未知时返回 0。
+func (u StorageUsage) Percent() float64 {
+	total := u.Size
+	if total <= 0 {
+		total = u.Used + u.Free
+	}
+	if total <= 0 {
+		return 0
+	}
+	return float64(u.Used) / float64(total) * 100
+}
+
+type StorageReport struct {
+	ArrayState string
+	Array      StorageUsage
+	Pools      []StorageUsage
+	Shares     []StorageUsage
+}
+
+// GetStorageReport 查询共享目录、缓存池与阵列容量；共享与缓存池按使用率从高到低排序。
+func (c *Client) GetStorageReport(ctx context.Context) (StorageReport, error) {
+	const q = `query { shares { name free used size } array { state capacity { kilobytes { free used total } } caches { name fsSize fsFree fsUsed } } }`
+	var resp struct {
+		Shares []struct {
+			Name string      `json:"name"`
+			Free interface{} `json:"free"`
+			Used interface{} `json:"used"`
+			Size interface{} `json:"size"`
+		} `json:"shares"`
+		Array struct {
+			State    string `json:"state"`
+			Capacity struct {
+				Kilobytes struct {
+					Free  interface{} `json:"free"`
+					Used  interface{} `json:"used"`
+					Total interface{} `json:"total"`
+				} `json:"kilobytes"`
+			} `json:"capacity"`
+			Caches []struct {
+				Name   string      `json:"name"`
+				FsSize interface{} `json:"fsSize"`
+				FsFree interface{} `json:"fsFree"`
+				FsUsed interface{} `json:"fsUsed"`
+			} `json:"caches"`
+		} `json:"array"`
+	}
+	if err := c.do(ctx, q, nil, &resp); err != nil {
+		if isMaybeUnsupportedGraphQL(err) {
+			return StorageReport{}, fmt.Errorf("存储空间查询失败：%w（目标 Unraid API 可能不支持 shares/array 字段，请升级 Unraid Connect/API 插件）", err)
+		}
+		return StorageReport{}, err
+	}
+
+	var out StorageReport
+	out.ArrayState = strings.TrimSpace(resp.Array.State)
+	out.Array = StorageUsage{
+		Name: "阵列",
+		Used: kibToBytes(resp.Array.Capacity.Kilobytes.Used),
+		Free: kibToBytes(resp.Array.Capacity.Kilobytes.Free),
+		Size: kibToBytes(resp.Array.Capacity.Kilobytes.Total),
+	}
+
+	for _, s := range resp.Shares {
+		name := strings.TrimSpace(s.Name)
+		if name == "" {
+			continue
+		}
+		out.Shares = append(out.Shares, StorageUsage{
+			Name: name,
+			Used: kibToBytes(s.Used),
+			Free: kibToBytes(s.Free),
+			Size: kibToBytes(s.Size),
+		})
+	}
+	for _, d := range resp.Array.Caches {
+		name := strings.TrimSpace(d.Name)
+		size := kibToBytes(d.FsSize)
+		// 多盘缓存池中仅首块盘携带文件系统容量，其余成员盘跳过。
+		if name == "" || size <= 0 {
+			continue
+		}
+		out.Pools = append(out.Pools, StorageUsage{
+			Name: name,
+			Used: kibToBytes(d.FsUsed),
+			Free: kibToBytes(d.FsFree),
+			Size: size,
+		})
+	}
+
+	sortStorageUsageDesc(out.Shares)
+	sortStorageUsageDesc(out.Pools)
+	return out, nil
+}
+
+func kibToBytes(v interface{}) int64 {
+	n, ok := parseNumberishToInt64(v)
+	if !ok || n <= 0 {
+		return 0
+	}
+	return n * 1024
+}
+
+func sortStorageUsageDesc(list []StorageUsage) {
+	sort.SliceStable(list, func(i, j int) bool {
+		pi, pj := list[i].Percent(), list[j].Percent()
+		if pi != pj {
+			return pi > pj
+		}
+		return list[i].Name < list[j].Name
+	})
+}
+
+func formatStorageReport(r StorageReport) string {
+	var lines []string
+	lines = append(lines, "【存储空间】")
+
+	arrayLine := "阵列: " + formatStorageUsage(r.Array)
+	if r.ArrayState != "" {
+		arrayLine += "（" + r.ArrayState + "）"
+	}
+	lines = append(lines, arrayLine)
+
+	if len(r.Pools) > 0 {
+		lines = append(lines, "", "缓存池:")
+		for _, u := range r.Pools {
+			lines = append(lines, fmt.Sprintf("- %s: %s", u.Name, formatStorageUsage(u)))
+		}
+	}
+
+	if len(r.Shares) > 0 {
+		lines = append(lines, "", fmt.Sprintf("共享目录（%d 个，按使用率排序）:", len(r.Shares)))
+		for _, u := range r.Shares {
+			lines = append(lines, fmt.Sprintf("- %s: 已用 %s，剩余 %s（%.1f%%）", u.Name, formatBytesIEC(u.Used), formatBytesIEC(u.Free), u.Percent()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatStorageUsage(u StorageUsage) string {
+	total := u.Size
+	if total <= 0 {
+		total = u.Used + u.Free
+	}
+	if total <= 0 {
+		return "容量未知"
+	}
+	return fmt.Sprintf("%s / %s（%.1f%%），剩余 %s", formatBytesIEC(u.Used), formatBytesIEC(total), u.Percent(), formatBytesIEC(u.Free))
+}
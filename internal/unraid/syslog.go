@@ -0,0 +1,358 @@
+package unraid
+
+// syslog.go 实现“系统日志”：通过 GraphQL logFiles/logFile 浏览 /var/log 下的日志文件，
+// 支持查看末尾、按 startLine 向前翻页与关键词过滤。
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+const (
+	syslogPageLines    = 50
+	syslogFilterWindow = 2000
+	syslogFilterMax    = 50
+)
+
+type LogFile struct {
+	Name       string
+	Path       string
+	Size       int64
+	ModifiedAt string
+}
+
+type LogFileContent struct {
+	Path       string
+	Content    string
+	TotalLines int
+	StartLine  int
+}
+
+// ListLogFiles 列出 Unraid 可查看的日志文件（syslog 等常用文件优先）。
+func (c *Client) ListLogFiles(ctx context.Context) ([]LogFile, error) {
+	const q = `query { logFiles { name path size modifiedAt } }`
+	var resp struct {
+		LogFiles []struct {
+			Name       string      `json:"name"`
+			Path       string      `json:"path"`
+			Size       interface{} `json:"size"`
+			ModifiedAt string      `json:"modifiedAt"`
+		} `json:"logFiles"`
+	}
+	if err := c.do(ctx, q, nil, &resp); err != nil {
+		if isMaybeUnsupportedGraphQL(err) {
+			return nil, fmt.Errorf("获取日志文件列表失败：%w（目标 Unraid API 可能不支持 logFiles，请升级 Unraid Connect/API 插件）", err)
+		}
+		return nil, err
+	}
+
+	out := make([]LogFile, 0, len(resp.LogFiles))
+	for _, f := range resp.LogFiles {
+		p := strings.TrimSpace(f.Path)
+		if p == "" {
+			continue
+		}
+		name := strings.TrimSpace(f.Name)
+		if name == "" {
+			name = path.Base(p)
+		}
+		size, _ := parseNumberishToInt64(f.Size)
+		out = append(out, LogFile{Name: name, Path: p, Size: size, ModifiedAt: strings.TrimSpace(f.ModifiedAt)})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := logFileRank(out[i].Name), logFileRank(out[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// GetLogFile 读取日志文件片段：startLine<=0 时返回末尾 lines 行，否则从 startLine（1 起）开始读取。
+func (c *Client) GetLogFile(ctx context.Context, filePath string, lines int, startLine int) (LogFileContent, error) {
+	if err := validateLogFilePath(filePath); err != nil {
+		return LogFileContent{}, err
+	}
+
+	q := `query LogFile($path: String!, $lines: Int) { logFile(path: $path, lines: $lines) { path content totalLines startLine } }`
+	vars := map[string]interface{}{"path": filePath, "lines": lines}
+	if startLine > 0 {
+		q = `query LogFile($path: String!, $lines: Int, $startLine: Int) { logFile(path: $path, lines: $lines, startLine: $startLine) { path content totalLines startLine } }`
+		vars["startLine"] = startLine
+	}
+
+	var resp struct {
+		LogFile struct {
+			Path       string      `json:"path"`
+			Content    string      `json:"content"`
+			TotalLines interface{} `json:"totalLines"`
+			StartLine  interface{} `json:"startLine"`
+		} `json:"logFile"`
+	}
+	if err := c.do(ctx, q, vars, &resp); err != nil {
+		if isMaybeUnsupportedGraphQL(err) {
+			return LogFileContent{}, fmt.Errorf("读取日志失败：%w（目标 Unraid API 可能不支持 logFile）", err)
+		}
+		return LogFileContent{}, err
+	}
+
+	total, _ := parseNumberishToInt64(resp.LogFile.TotalLines)
+	start, _ := parseNumberishToInt64(resp.LogFile.StartLine)
+	out := LogFileContent{
+		Path:       resp.LogFile.Path,
+		Content:    strings.TrimRight(resp.LogFile.Content, "\n"),
+		TotalLines: int(total),
+		StartLine:  int(start),
+	}
+	if out.Path == "" {
+		out.Path = filePath
+	}
+	// 兼容：部分实现不返回 startLine，按“末尾 N 行”推算。
+	if out.StartLine <= 0 {
+		if startLine > 0 {
+			out.StartLine = startLine
+		} else {
+			n := countLines(out.Content)
+			out.StartLine = out.TotalLines - n + 1
+			if out.StartLine < 1 {
+				out.StartLine = 1
+			}
+		}
+	}
+	return out, nil
+}
+
+func validateLogFilePath(p string) error {
+	p = strings.TrimSpace(p)
+	if p == "" || !strings.HasPrefix(p, "/") {
+		return errors.New("日志文件路径不合法")
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return errors.New("日志文件路径不合法")
+		}
+	}
+	return nil
+}
+
+func logFileRank(name string) int {
+	switch strings.ToLower(name) {
+	case "syslog":
+		return 0
+	case "docker.log":
+		return 1
+	case "libvirt.log", "libvirtd.log":
+		return 2
+	default:
+		return 10
+	}
+}
+
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
+
+// handleSyslogEvent 处理系统日志相关 EventKey；返回 handled=false 表示非系统日志事件。
+func (p *Provider) handleSyslogEvent(ctx context.Context, userID string, key string) (bool, error) {
+	if strings.HasPrefix(key, wecom.EventKeyUnraidLogFileSelectPrefix) {
+		filePath := strings.TrimPrefix(key, wecom.EventKeyUnraidLogFileSelectPrefix)
+		return true, p.sendLogFileTail(ctx, userID, filePath, 0, syslogPageLines)
+	}
+
+	switch key {
+	case wecom.EventKeyUnraidViewSyslog:
+		return true, p.sendLogFileList(ctx, userID)
+	case wecom.EventKeyUnraidLogFileLatest, wecom.EventKeyUnraidLogFileOlder, wecom.EventKeyUnraidLogFileFilter:
+	default:
+		return false, nil
+	}
+
+	state, ok := p.state.Get(userID)
+	if !ok || state.ServiceKey != p.Key() || state.UnraidLogPath == "" {
+		_ = p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "会话已过期，请重新选择日志文件。"})
+		return true, p.sendLogFileList(ctx, userID)
+	}
+
+	switch key {
+	case wecom.EventKeyUnraidLogFileLatest:
+		return true, p.sendLogFileTail(ctx, userID, state.UnraidLogPath, 0, syslogPageLines)
+	case wecom.EventKeyUnraidLogFileOlder:
+		if state.UnraidLogStartLine <= 1 {
+			return true, p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "已到文件开头。"})
+		}
+		// 向前翻一页，只读取当前窗口之前的行，避免与当前内容重叠。
+		start, lines := state.UnraidLogStartLine-syslogPageLines, syslogPageLines
+		if start < 1 {
+			start, lines = 1, state.UnraidLogStartLine-1
+		}
+		return true, p.sendLogFileTail(ctx, userID, state.UnraidLogPath, start, lines)
+	default:
+		state.Step = core.StepAwaitingUnraidLogKeyword
+		p.state.Set(userID, state)
+		return true, p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("请输入过滤关键词（在最近 %d 行中查找，不区分大小写）：", syslogFilterWindow),
+		})
+	}
+}
+
+func (p *Provider) sendLogFileList(ctx context.Context, userID string) error {
+	files, err := p.client.ListLogFiles(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: err.Error()})
+	}
+	if len(files) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未找到可查看的日志文件。"})
+	}
+
+	p.state.Set(userID, core.ConversationState{ServiceKey: p.Key()})
+
+	lines := []string{fmt.Sprintf("【系统日志】共 %d 个文件", len(files))}
+	var opts []wecom.UnraidLogFileOption
+	for _, f := range files {
+		line := "- " + f.Name
+		if f.Size > 0 {
+			line += "（" + formatBytesIEC(f.Size) + "）"
+		}
+		lines = append(lines, line)
+		opts = append(opts, wecom.UnraidLogFileOption{Path: f.Path, Text: truncateRunes(f.Name, 20)})
+	}
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: truncateForWecom(strings.Join(lines, "\n")),
+	}); err != nil {
+		return err
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidLogFileSelectCard(len(files), opts),
+	})
+}
+
+// sendLogFileTail 展示日志片段：startLine<=0 为末尾 lines 行；否则从 startLine 开始向后 lines 行。
+func (p *Provider) sendLogFileTail(ctx context.Context, userID string, filePath string, startLine int, lines int) error {
+	lg, err := p.client.GetLogFile(ctx, filePath, lines, startLine)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: err.Error()})
+	}
+
+	name := path.Base(lg.Path)
+	shown := strings.Split(lg.Content, "\n")
+	end := lg.StartLine + countLines(lg.Content) - 1
+	start := lg.StartLine
+	note := ""
+	if lg.Content != "" && p.truncatesLogs() {
+		// 以结束行号估算标题长度（起始行号位数不会更多），保证按实际展示范围生成的标题不超出预算。
+		budget := wecom.TextChunkBytes - len(logRangeHeader(name, end, end, lg.TotalLines)) - len(tailOmittedNote)
+		kept := tailLinesWithin(shown, budget)
+		if len(kept) == 0 {
+			// 最后一行本身超长时只展示该行，由 core.SendLongText 截断。
+			kept = shown[len(shown)-1:]
+		}
+		if len(kept) < len(shown) {
+			shown = kept
+			start = end - len(kept) + 1
+			note = tailOmittedNote
+		}
+	}
+
+	// 翻页起点与标题都以实际发送的行为准，避免“上一页”跳过未展示的内容。
+	p.state.Set(userID, core.ConversationState{
+		ServiceKey:         p.Key(),
+		UnraidLogPath:      lg.Path,
+		UnraidLogStartLine: start,
+	})
+
+	header := logRangeHeader(name, start, end, lg.TotalLines)
+	if lg.Content == "" {
+		header = fmt.Sprintf("【%s】（空）", name)
+	}
+	if err := p.sendLogText(ctx, userID, name, header+note+"\n"+strings.Join(shown, "\n")); err != nil {
+		return err
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidLogFileViewCard(name, start > 1),
+	})
+}
+
+func logRangeHeader(name string, start, end, total int) string {
+	return fmt.Sprintf("【%s】第 %d-%d 行 / 共 %d 行", name, start, end, total)
+}
+
+// handleLogKeyword 在日志末尾窗口内按关键词过滤（类似 grep -i），展示最近的匹配行。
+func (p *Provider) handleLogKeyword(ctx context.Context, userID string, state core.ConversationState, content string) error {
+	keyword := strings.TrimSpace(content)
+	if keyword == "" {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "关键词不能为空，请重新输入："})
+	}
+	state.Step = ""
+	p.state.Set(userID, state)
+
+	lg, err := p.client.GetLogFile(ctx, state.UnraidLogPath, syslogFilterWindow, 0)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: err.Error()})
+	}
+
+	kw := strings.ToLower(keyword)
+	var matches []string
+	for i, line := range strings.Split(lg.Content, "\n") {
+		if strings.Contains(strings.ToLower(line), kw) {
+			matches = append(matches, fmt.Sprintf("%d: %s", lg.StartLine+i, line))
+		}
+	}
+
+	name := path.Base(lg.Path)
+	header := fmt.Sprintf("【%s】关键词「%s」：最近 %d 行中匹配 %d 行", name, keyword, countLines(lg.Content), len(matches))
+	if len(matches) > syslogFilterMax {
+		matches = matches[len(matches)-syslogFilterMax:]
+		header += fmt.Sprintf("（仅展示最后 %d 行）", syslogFilterMax)
+	}
+	text := header + "\n" + strings.Join(matches, "\n")
+	if p.truncatesLogs() {
+		text = joinTailForWecom(header, matches)
+	}
+	if err := p.sendLogText(ctx, userID, name+".grep.txt", text); err != nil {
+		return err
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidLogFileViewCard(name, state.UnraidLogStartLine > 1),
+	})
+}
+
+const tailOmittedNote = "\n（内容过长，已省略较早的行）"
+
+// joinTailForWecom 拼接标题与日志行；超出企业微信长度限制时丢弃较早的行，保留最新内容。
+func joinTailForWecom(header string, lines []string) string {
+	s := header + "\n" + strings.Join(lines, "\n")
+	if len(s) <= wecom.TextChunkBytes {
+		return s
+	}
+	kept := tailLinesWithin(lines, wecom.TextChunkBytes-len(header)-len(tailOmittedNote))
+	if len(kept) == 0 {
+		return truncateForWecom(s)
+	}
+	return header + tailOmittedNote + "\n" + strings.Join(kept, "\n")
+}
+
+// tailLinesWithin 返回末尾若干行，使其（每行计入一个换行符）总字节数不超过 budget。
+func tailLinesWithin(lines []string, budget int) []string {
+	size := 0
+	i := len(lines)
+	for i > 0 && size+len(lines[i-1])+1 <= budget {
+		size += len(lines[i-1]) + 1
+		i--
+	}
+	return lines[i:]
+}
@@ -0,0 +1,240 @@
+package unraid
+
+// update.go 实现容器更新可用性检查（GraphQL containerUpdateStatuses）与“全部更新”（串行执行，支持失败跳过）。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// 容器更新状态（与 Unraid API UpdateStatus 枚举对应）。
+const (
+	UpdateStatusUpToDate        = "UP_TO_DATE"
+	UpdateStatusUpdateAvailable = "UPDATE_AVAILABLE"
+	UpdateStatusRebuildReady    = "REBUILD_READY"
+	UpdateStatusUnknown         = "UNKNOWN"
+)
+
+// updateAllTimeout 为“全部更新”后台任务的整体超时（逐个拉取镜像可能较慢）。
+const updateAllTimeout = 60 * time.Minute
+
+type ContainerUpdateStatus struct {
+	Name   string
+	Status string
+}
+
+// HasUpdate 判断容器是否可更新（有新镜像或需按新模板重建）。
+func (s ContainerUpdateStatus) HasUpdate() bool {
+	switch strings.ToUpper(strings.TrimSpace(s.Status)) {
+	case UpdateStatusUpdateAvailable, UpdateStatusRebuildReady:
+		return true
+	default:
+		return false
+	}
+}
+
+// ListContainerUpdateStatuses 查询所有容器的更新状态（需较新的 Unraid API 提供 docker.containerUpdateStatuses）。
+func (c *Client) ListContainerUpdateStatuses(ctx context.Context) ([]ContainerUpdateStatus, error) {
+	const q = `query { docker { containerUpdateStatuses { name updateStatus } } }`
+	var resp struct {
+		Docker struct {
+			ContainerUpdateStatuses []struct {
+				Name         string `json:"name"`
+				UpdateStatus string `json:"updateStatus"`
+			} `json:"containerUpdateStatuses"`
+		} `json:"docker"`
+	}
+	if err := c.do(ctx, q, nil, &resp); err != nil {
+		if isMaybeUnsupportedGraphQL(err) {
+			return nil, fmt.Errorf("更新检查失败：%w（目标 Unraid API 可能不支持 docker.containerUpdateStatuses，请升级 Unraid Connect/API 插件）", err)
+		}
+		return nil, err
+	}
+
+	out := make([]ContainerUpdateStatus, 0, len(resp.Docker.ContainerUpdateStatuses))
+	for _, st := range resp.Docker.ContainerUpdateStatuses {
+		name := normalizeName(st.Name)
+		if name == "" {
+			continue
+		}
+		out = append(out, ContainerUpdateStatus{Name: name, Status: strings.TrimSpace(st.UpdateStatus)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// ListUpdatableContainers 返回可更新的容器名（已排序）。
+func (c *Client) ListUpdatableContainers(ctx context.Context) ([]string, error) {
+	statuses, err := c.ListContainerUpdateStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, st := range statuses {
+		if st.HasUpdate() {
+			names = append(names, st.Name)
+		}
+	}
+	return names, nil
+}
+
+type containerOpResult struct {
+	Name    string
+	Err     error
+	Skipped bool
+	Cost    time.Duration
+}
+
+// updateContainersSequential 逐个更新容器；stopOnFailure=false 时失败后继续下一个（失败跳过）。
+func (c *Client) updateContainersSequential(ctx context.Context, names []string, stopOnFailure bool) []containerOpResult {
+	results := make([]containerOpResult, 0, len(names))
+	stopped := false
+	for _, name := range names {
+		if stopped || ctx.Err() != nil {
+			results = append(results, containerOpResult{Name: name, Skipped: true})
+			continue
+		}
+		start := time.Now()
+		err := c.ForceUpdateContainerByName(ctx, name)
+		results = append(results, containerOpResult{Name: name, Err: err, Cost: time.Since(start)})
+		if err != nil {
+			slog.Warn("unraid 全部更新：容器更新失败", "container", name, "error", err)
+			if stopOnFailure {
+				stopped = true
+			}
+		}
+	}
+	return results
+}
+
+func (p *Provider) sendUpdatableContainers(ctx context.Context, userID string) error {
+	start := time.Now()
+	names, err := p.client.ListUpdatableContainers(ctx)
+	cost := time.Since(start).Milliseconds()
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("查询失败（%dms）：%s", cost, err.Error()),
+		})
+	}
+	if len(names) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "所有容器均已是最新版本。"})
+	}
+
+	// 单个容器按钮复用“强制更新”的选择/确认流程。
+	p.state.Set(userID, core.ConversationState{
+		ServiceKey: p.Key(),
+		Action:     core.ActionUnraidForceUpdate,
+	})
+
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: truncateForWecom(fmt.Sprintf("【可更新容器】共 %d 个\n- %s", len(names), strings.Join(names, "\n- "))),
+	}); err != nil {
+		return err
+	}
+
+	var opts []wecom.UnraidContainerOption
+	for _, n := range names {
+		opts = append(opts, wecom.UnraidContainerOption{Name: n, Text: truncateRunes(n, 28)})
+	}
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewUnraidUpdatableCard(len(names), opts),
+	})
+}
+
+func (p *Provider) prepareUpdateAll(ctx context.Context, userID string) error {
+	names, err := p.client.ListUpdatableContainers(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("查询失败：%s", err.Error()),
+		})
+	}
+	if len(names) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "所有容器均已是最新版本。"})
+	}
+
+	p.state.Set(userID, core.ConversationState{
+		ServiceKey: p.Key(),
+		Step:       core.StepAwaitingConfirm,
+		Action:     core.ActionUnraidUpdateAll,
+	})
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewConfirmCard(core.ActionUnraidUpdateAll.DisplayName(), fmt.Sprintf("%d 个可更新容器", len(names))),
+	})
+}
+
+// startUpdateAll 在确认后启动后台串行更新，并在结束时发送汇总结果。
+// 回调请求的 ctx 会随 HTTP 响应结束而取消，因此后台任务使用 WithoutCancel 派生的独立超时。
+func (p *Provider) startUpdateAll(ctx context.Context, userID string) error {
+	names, err := p.client.ListUpdatableContainers(ctx)
+	if err != nil {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: fmt.Sprintf("查询失败：%s", err.Error()),
+		})
+	}
+	if len(names) == 0 {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "所有容器均已是最新版本。"})
+	}
+
+	mode := "失败跳过"
+	if p.updateAllStopOnFailure {
+		mode = "失败即停止"
+	}
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{
+		ToUser:  userID,
+		Content: fmt.Sprintf("已开始全部更新：共 %d 个容器（串行执行，%s），完成后发送汇总结果。", len(names), mode),
+	}); err != nil {
+		return err
+	}
+
+	bg, cancel := context.WithTimeout(context.WithoutCancel(ctx), updateAllTimeout)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		results := p.client.updateContainersSequential(bg, names, p.updateAllStopOnFailure)
+		if err := p.wecom.SendText(bg, wecom.TextMessage{
+			ToUser:  userID,
+			Content: truncateForWecom(formatOpResults("全部更新结果", results, time.Since(start))),
+		}); err != nil {
+			slog.Error("unraid 全部更新：发送汇总失败", "user_id", userID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// formatOpResults 汇总批量操作的逐个容器结果（全部更新/容器组操作共用）。
+func formatOpResults(title string, results []containerOpResult, total time.Duration) string {
+	var okCount, failCount, skipCount int
+	var lines []string
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			skipCount++
+			lines = append(lines, fmt.Sprintf("- %s：未执行（已停止）", r.Name))
+		case r.Err != nil:
+			failCount++
+			lines = append(lines, fmt.Sprintf("- %s：失败（%.1fs）：%s", r.Name, r.Cost.Seconds(), r.Err.Error()))
+		default:
+			okCount++
+			lines = append(lines, fmt.Sprintf("- %s：成功（%.1fs）", r.Name, r.Cost.Seconds()))
+		}
+	}
+
+	header := fmt.Sprintf("【%s】成功 %d，失败 %d", title, okCount, failCount)
+	if skipCount > 0 {
+		header += fmt.Sprintf("，未执行 %d", skipCount)
+	}
+	header += fmt.Sprintf("（耗时 %s）", total.Round(time.Second))
+	return header + "\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,358 @@
+package unraid
+
+// ups.go 实现 UPS 电源监控：轮询 upsDevices，在市电中断/电池低电量/市电恢复等状态变化，
+// 以及电池供电期间电量或剩余续航低于阈值时告警；可选附带“关机预案”按钮（停止容器组、关闭 PVE 虚拟机等）。
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zcw199604/wecom-home-ops/internal/core"
+	"github.com/zcw199604/wecom-home-ops/internal/wecom"
+)
+
+// shutdownPlanTimeout 为关机预案后台任务的整体超时。
+const shutdownPlanTimeout = 30 * time.Minute
+
+type UPSConfig struct {
+	Enabled bool
+
+	Interval time.Duration
+
+	// ChargeThreshold 为电池供电时的电量告警阈值（百分比，<=0 表示不检查）。
+	ChargeThreshold float64
+	// RuntimeThreshold 为电池供电时的剩余续航告警阈值（<=0 表示不检查）。
+	RuntimeThreshold time.Duration
+}
+
+// ShutdownPlan 为 UPS 断电时可一键执行的“优雅关机预案”：先按顺序停止容器组，再依次执行外部步骤。
+type ShutdownPlan struct {
+	// Groups 为需要停止的容器组 ID（按顺序执行，组内逆序停止）。
+	Groups []string
+	// Steps 为额外步骤（例如关闭 PVE 虚拟机），由装配层注入。
+	Steps []ShutdownStep
+}
+
+type ShutdownStep struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Empty 判断预案是否未配置任何步骤。
+func (p ShutdownPlan) Empty() bool { return len(p.Groups) == 0 && len(p.Steps) == 0 }
+
+type upsPowerState string
+
+const (
+	upsPowerUnknown    upsPowerState = ""
+	upsPowerOnline     upsPowerState = "online"
+	upsPowerOnBattery  upsPowerState = "on_battery"
+	upsPowerLowBattery upsPowerState = "low_battery"
+)
+
+// classifyUPSStatus 将 UPS 状态字符串归类（兼容 Unraid API 文本、apcupsd 与 NUT 状态码）。
+func classifyUPSStatus(status string) upsPowerState {
+	s := strings.ToUpper(strings.TrimSpace(status))
+	if s == "" {
+		return upsPowerUnknown
+	}
+	compact := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+	tokens := make(map[string]struct{})
+	for _, t := range strings.Fields(s) {
+		tokens[t] = struct{}{}
+	}
+	has := func(t string) bool { _, ok := tokens[t]; return ok }
+
+	switch {
+	case strings.Contains(compact, "LOWBATT") || has("LB"):
+		return upsPowerLowBattery
+	case strings.Contains(compact, "ONBATT") || strings.Contains(compact, "DISCHARG") || has("OB"):
+		return upsPowerOnBattery
+	case strings.Contains(compact, "ONLINE") || has("OL"):
+		return upsPowerOnline
+	default:
+		return upsPowerUnknown
+	}
+}
+
+type upsDeviceState struct {
+	Power      upsPowerState
+	ChargeLow  bool
+	RuntimeLow bool
+}
+
+type UPSWatcherDeps struct {
+	WeCom   core.WeComSender
+	UserIDs []string
+	// ToParty/ToTag 为按部门/标签直接投递的接收者（“|”分隔，可选），与 UserIDs 合并为同一条消息。
+	ToParty string
+	ToTag   string
+	Client  *Client
+	Config  UPSConfig
+
+	// ShutdownPlanEnabled 为 true 时，断电类告警附带“执行关机预案”按钮。
+	ShutdownPlanEnabled bool
+
+	// Broadcast 为可选的广播出口（例如运维群聊）；“执行关机预案”按钮仍仅私聊发送给 UserIDs。
+	Broadcast core.Notifier
+}
+
+// UPSWatcher 按设备记录上一次状态，仅在状态变化（或阈值首次越过）时告警，恢复后重新布防。
+type UPSWatcher struct {
+	wecom   core.WeComSender
+	userIDs []string
+	toParty string
+	toTag   string
+	client  *Client
+	cfg     UPSConfig
+
+	shutdownPlanEnabled bool
+	broadcast           core.Notifier
+
+	mu     sync.Mutex
+	states map[string]upsDeviceState
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	startOnce sync.Once
+}
+
+func NewUPSWatcher(deps UPSWatcherDeps) *UPSWatcher {
+	return &UPSWatcher{
+		wecom:               deps.WeCom,
+		userIDs:             uniqueNonEmpty(deps.UserIDs),
+		toParty:             strings.TrimSpace(deps.ToParty),
+		toTag:               strings.TrimSpace(deps.ToTag),
+		client:              deps.Client,
+		cfg:                 deps.Config,
+		shutdownPlanEnabled: deps.ShutdownPlanEnabled,
+		broadcast:           deps.Broadcast,
+		states:              make(map[string]upsDeviceState),
+		stopCh:              make(chan struct{}),
+	}
+}
+
+func (w *UPSWatcher) Start() {
+	if w == nil || !w.cfg.Enabled || w.wecom == nil || w.client == nil || (len(w.userIDs) == 0 && w.toParty == "" && w.toTag == "" && w.broadcast == nil) {
+		return
+	}
+	w.startOnce.Do(func() {
+		interval := w.cfg.Interval
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		go w.loop(interval)
+	})
+}
+
+func (w *UPSWatcher) Close() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stopCh) })
+}
+
+func (w *UPSWatcher) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w.checkOnce()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.checkOnce()
+		}
+	}
+}
+
+func (w *UPSWatcher) checkOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	devices, _, err := w.client.getUPSDevices(ctx)
+	if err != nil {
+		slog.Warn("unraid UPS 查询失败", "error", err)
+		return
+	}
+	for _, d := range devices {
+		w.checkDevice(ctx, d)
+	}
+}
+
+func upsDeviceKey(d UPSDeviceMetrics) string {
+	for _, s := range []string{d.ID, d.Name, d.Model} {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return "ups"
+}
+
+// checkDevice 对比上一次状态并发送告警。首次观测到市电正常时不告警（避免启动即刷屏）。
+func (w *UPSWatcher) checkDevice(ctx context.Context, d UPSDeviceMetrics) {
+	key := upsDeviceKey(d)
+	power := classifyUPSStatus(d.Status)
+
+	w.mu.Lock()
+	prev, seen := w.states[key]
+	next := upsDeviceState{Power: power}
+	if power == upsPowerUnknown {
+		// 状态无法识别时沿用上一次的判断，避免抖动产生误报。
+		next.Power = prev.Power
+	}
+	onBattery := next.Power == upsPowerOnBattery || next.Power == upsPowerLowBattery
+
+	var events []string
+	if next.Power != prev.Power && next.Power != upsPowerUnknown {
+		switch next.Power {
+		case upsPowerOnBattery:
+			events = append(events, "⚡ 市电中断，UPS 已切换为电池供电")
+		case upsPowerLowBattery:
+			events = append(events, "🪫 UPS 电池电量低，即将耗尽")
+		case upsPowerOnline:
+			if seen && prev.Power != upsPowerUnknown {
+				events = append(events, "✅ 市电已恢复，UPS 恢复在线供电")
+			}
+		}
+	}
+
+	if onBattery && d.Battery != nil {
+		if t := w.cfg.ChargeThreshold; t > 0 && d.Battery.ChargeLevel != nil {
+			next.ChargeLow = *d.Battery.ChargeLevel < t
+			if next.ChargeLow && !prev.ChargeLow {
+				events = append(events, fmt.Sprintf("🔋 电池电量 %s 低于阈值 %.0f%%", formatPercent(*d.Battery.ChargeLevel), t))
+			}
+		}
+		if t := w.cfg.RuntimeThreshold; t > 0 && d.Battery.EstimatedRuntime != nil && *d.Battery.EstimatedRuntime > 0 {
+			rt := int64(*d.Battery.EstimatedRuntime)
+			next.RuntimeLow = time.Duration(rt)*time.Second < t
+			if next.RuntimeLow && !prev.RuntimeLow {
+				events = append(events, fmt.Sprintf("⏳ 剩余续航 %s 低于阈值 %s", formatSecondsCN(rt), formatSecondsCN(int64(t.Seconds()))))
+			}
+		}
+	}
+	w.states[key] = next
+	w.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+	content := fmt.Sprintf("⚠️ Unraid UPS 告警\n\n%s\n\n当前：%s", strings.Join(events, "\n"), formatUPSDeviceInline(d))
+	if len(w.userIDs) > 0 || w.toParty != "" || w.toTag != "" {
+		toUser := strings.Join(w.userIDs, "|")
+		if err := w.wecom.SendText(ctx, wecom.TextMessage{ToUser: toUser, ToParty: w.toParty, ToTag: w.toTag, Content: content}); err != nil {
+			slog.Error("unraid UPS 告警发送失败", "to_user", toUser, "to_party", w.toParty, "to_tag", w.toTag, "error", err)
+		} else if w.shutdownPlanEnabled && onBattery {
+			_ = w.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+				ToUser:  toUser,
+				ToParty: w.toParty,
+				ToTag:   w.toTag,
+				Card:    wecom.NewUnraidUPSShutdownPlanCard("电池供电中，可执行预设的关机预案"),
+			})
+		}
+	}
+	if w.broadcast != nil {
+		if err := w.broadcast.Notify(ctx, content); err != nil {
+			slog.Error("unraid UPS 告警广播失败", "device", key, "error", err)
+		}
+	}
+}
+
+// prepareShutdownPlan 展示预案内容并进入确认态。
+func (p *Provider) prepareShutdownPlan(ctx context.Context, userID string) error {
+	if p.shutdownPlan.Empty() {
+		return p.wecom.SendText(ctx, wecom.TextMessage{
+			ToUser:  userID,
+			Content: "未配置关机预案（可在 config.yaml 的 unraid.ups.shutdown_plan 中配置）。",
+		})
+	}
+
+	lines := []string{"【关机预案】将按顺序执行："}
+	for _, id := range p.shutdownPlan.Groups {
+		name := id
+		if g, ok := p.findGroup(id); ok {
+			name = groupDisplayName(g)
+		}
+		lines = append(lines, fmt.Sprintf("- 停止容器组 %s", name))
+	}
+	for _, s := range p.shutdownPlan.Steps {
+		lines = append(lines, "- "+s.Name)
+	}
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: truncateForWecom(strings.Join(lines, "\n"))}); err != nil {
+		return err
+	}
+
+	p.state.Set(userID, core.ConversationState{
+		ServiceKey: p.Key(),
+		Step:       core.StepAwaitingConfirm,
+		Action:     core.ActionUnraidShutdownPlan,
+	})
+	return p.wecom.SendTemplateCard(ctx, wecom.TemplateCardMessage{
+		ToUser: userID,
+		Card:   wecom.NewConfirmCard(core.ActionUnraidShutdownPlan.DisplayName(), fmt.Sprintf("%d 个步骤", len(p.shutdownPlan.Groups)+len(p.shutdownPlan.Steps))),
+	})
+}
+
+// startShutdownPlan 在确认后于后台执行关机预案（失败继续），结束时发送汇总结果。
+func (p *Provider) startShutdownPlan(ctx context.Context, userID string) error {
+	if p.shutdownPlan.Empty() {
+		return p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "未配置关机预案。"})
+	}
+	if err := p.wecom.SendText(ctx, wecom.TextMessage{ToUser: userID, Content: "已开始执行关机预案，完成后发送汇总结果。"}); err != nil {
+		return err
+	}
+
+	bg, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownPlanTimeout)
+	go func() {
+		defer cancel()
+		start := time.Now()
+		results := p.runShutdownPlan(bg)
+		if err := p.wecom.SendText(bg, wecom.TextMessage{
+			ToUser:  userID,
+			Content: truncateForWecom(formatOpResults("关机预案执行结果", results, time.Since(start))),
+		}); err != nil {
+			slog.Error("unraid 关机预案：发送汇总失败", "user_id", userID, "error", err)
+		}
+	}()
+	return nil
+}
+
+func (p *Provider) runShutdownPlan(ctx context.Context) []containerOpResult {
+	var results []containerOpResult
+	for _, id := range p.shutdownPlan.Groups {
+		g, ok := p.findGroup(id)
+		if !ok {
+			results = append(results, containerOpResult{Name: "容器组 " + id, Err: fmt.Errorf("容器组不存在")})
+			continue
+		}
+		members, err := p.client.resolveGroupMembers(ctx, g)
+		if err != nil {
+			results = append(results, containerOpResult{Name: "容器组 " + groupDisplayName(g), Err: err})
+			continue
+		}
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+		results = append(results, p.runGroupSequential(ctx, members, core.ActionUnraidStop, g.Delay)...)
+	}
+	for _, s := range p.shutdownPlan.Steps {
+		if ctx.Err() != nil {
+			results = append(results, containerOpResult{Name: s.Name, Skipped: true})
+			continue
+		}
+		start := time.Now()
+		err := s.Run(ctx)
+		results = append(results, containerOpResult{Name: s.Name, Err: err, Cost: time.Since(start)})
+		if err != nil {
+			slog.Warn("unraid 关机预案：步骤执行失败", "step", s.Name, "error", err)
+		}
+	}
+	return results
+}
@@ -0,0 +1,296 @@
+package unraid
+
+// webgui_session.go 实现 WebGUI 会话管理：使用用户名/密码自动登录并抓取 csrf_token，
+// 缓存 Cookie 与 csrf_token，在鉴权失败或 token 被拒绝（含空响应）时自动重新登录并重试一次。
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// webGUISession 缓存当前可用的 WebGUI 凭据（来源为静态配置或自动登录）。
+type webGUISession struct {
+	mu     sync.Mutex
+	inited bool
+	csrf   string
+	cookie string
+	// login 非 nil 表示正在登录（登录期间不持有 mu），登录结束后关闭，等待者随后重新读取缓存。
+	login chan struct{}
+}
+
+// webGUIAuthError 表示 WebGUI 鉴权失败（未登录、会话过期或 csrf_token 无效），可通过重新登录恢复。
+type webGUIAuthError struct {
+	msg string
+}
+
+func (e *webGUIAuthError) Error() string { return e.msg }
+
+var csrfTokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`csrf_token\s*=\s*["']([0-9A-Za-z]+)["']`),
+	regexp.MustCompile(`name=["']csrf_token["'][^>]*value=["']([0-9A-Za-z]+)["']`),
+	regexp.MustCompile(`csrf_token=([0-9A-Za-z]+)`),
+}
+
+func (c *Client) canWebGUILogin() bool {
+	return strings.TrimSpace(c.cfg.WebGUILoginURL) != "" &&
+		strings.TrimSpace(c.cfg.WebGUIUsername) != "" &&
+		c.cfg.WebGUIPassword != ""
+}
+
+func (c *Client) hasWebGUIAuth() bool {
+	return strings.TrimSpace(c.cfg.WebGUICSRFToken) != "" || c.canWebGUILogin()
+}
+
+// webGUICredentials 返回当前可用的 csrf_token 与 Cookie；优先使用缓存/静态配置，缺失时自动登录。
+func (c *Client) webGUICredentials(ctx context.Context) (string, string, error) {
+	for {
+		c.webgui.mu.Lock()
+		if !c.webgui.inited {
+			c.webgui.inited = true
+			c.webgui.csrf = strings.TrimSpace(c.cfg.WebGUICSRFToken)
+			c.webgui.cookie = strings.TrimSpace(c.cfg.WebGUICookie)
+		}
+		if c.webgui.csrf != "" {
+			csrf, cookie := c.webgui.csrf, c.webgui.cookie
+			c.webgui.mu.Unlock()
+			return csrf, cookie, nil
+		}
+		if !c.canWebGUILogin() {
+			c.webgui.mu.Unlock()
+			return "", "", errors.New("unraid webgui csrf_token 未配置（可配置 unraid.webgui_username/webgui_password 自动登录）")
+		}
+		if wait := c.webgui.login; wait != nil {
+			// 其他请求正在登录：等待其完成后复用结果，避免并发重复登录。
+			c.webgui.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return "", "", ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		c.webgui.login = done
+		c.webgui.mu.Unlock()
+
+		// 登录涉及多次网络往返，不持有锁，避免慢登录阻塞其他 WebGUI 调用。
+		csrf, cookie, err := c.loginWebGUI(ctx)
+
+		c.webgui.mu.Lock()
+		c.webgui.login = nil
+		if err == nil {
+			c.webgui.csrf = csrf
+			c.webgui.cookie = cookie
+		}
+		c.webgui.mu.Unlock()
+		close(done)
+		if err != nil {
+			return "", "", err
+		}
+		return csrf, cookie, nil
+	}
+}
+
+// invalidateWebGUISession 丢弃失效的会话；仅当缓存仍为 usedCSRF 时清空，避免并发请求重复登录。
+func (c *Client) invalidateWebGUISession(usedCSRF string) {
+	c.webgui.mu.Lock()
+	defer c.webgui.mu.Unlock()
+	if c.webgui.csrf == usedCSRF {
+		c.webgui.csrf = ""
+		c.webgui.cookie = ""
+	}
+}
+
+// postWebGUIForm 携带 csrf_token/Cookie 提交 WebGUI 表单；鉴权失败且配置了登录凭据时重新登录并重试一次。
+func (c *Client) postWebGUIForm(ctx context.Context, target string, form url.Values) error {
+	csrf, cookie, err := c.webGUICredentials(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.postWebGUIFormOnce(ctx, target, form, csrf, cookie)
+
+	var authErr *webGUIAuthError
+	if err == nil || !errors.As(err, &authErr) || !c.canWebGUILogin() {
+		return err
+	}
+
+	slog.Info("unraid webgui 会话失效，重新登录", "reason", err.Error())
+	c.invalidateWebGUISession(csrf)
+	csrf, cookie, err = c.webGUICredentials(ctx)
+	if err != nil {
+		return err
+	}
+	return c.postWebGUIFormOnce(ctx, target, form, csrf, cookie)
+}
+
+func (c *Client) postWebGUIFormOnce(ctx context.Context, target string, form url.Values, csrf string, cookie string) error {
+	values := url.Values{}
+	for k, v := range form {
+		values[k] = v
+	}
+	values.Set("csrf_token", csrf)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+	body := strings.TrimSpace(string(b))
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return &webGUIAuthError{msg: fmt.Sprintf("unraid webgui http status %d: %s", res.StatusCode, body)}
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unraid webgui http status %d: %s", res.StatusCode, body)
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Type")))
+	bodyLower := strings.ToLower(body)
+	if strings.Contains(bodyLower, "invalid csrf") || strings.Contains(bodyLower, "csrf") && strings.Contains(bodyLower, "invalid") {
+		return &webGUIAuthError{msg: "unraid webgui csrf_token 无效或已过期"}
+	}
+	if strings.Contains(contentType, "text/html") && isWebGUILoginPage(bodyLower) {
+		return &webGUIAuthError{msg: "unraid webgui 可能未登录（请配置 unraid.webgui_username/webgui_password 或 unraid.webgui_cookie）或 csrf_token 已失效"}
+	}
+	// Unraid 的 local_prepend.php 拒绝 csrf_token 时只记录日志并以空的 200 响应退出；
+	// 配置了登录凭据时将空响应视为 token 被拒绝，以便重新登录后重试。
+	if body == "" && c.canWebGUILogin() {
+		return &webGUIAuthError{msg: "unraid webgui 返回空响应（csrf_token 可能已被拒绝）"}
+	}
+	return nil
+}
+
+// loginWebGUI 提交 /login 表单获取会话 Cookie，再访问登录后页面抓取 csrf_token。
+func (c *Client) loginWebGUI(ctx context.Context) (string, string, error) {
+	loginURL := strings.TrimSpace(c.cfg.WebGUILoginURL)
+
+	// 登录成功通常返回 302 并在该响应上 Set-Cookie，需禁止自动跟随重定向才能拿到 Cookie。
+	hc := *c.httpClient
+	hc.Jar = nil
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	form := url.Values{}
+	form.Set("username", c.cfg.WebGUIUsername)
+	form.Set("password", c.cfg.WebGUIPassword)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, loginURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("unraid webgui 登录失败：%w", err)
+	}
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+
+	cookies := make(map[string]string)
+	var order []string
+	mergeCookies(cookies, &order, res.Cookies())
+
+	switch {
+	case res.StatusCode >= 300 && res.StatusCode <= 399:
+	case res.StatusCode >= 200 && res.StatusCode <= 299:
+		if isWebGUILoginPage(strings.ToLower(string(b))) {
+			return "", "", errors.New("unraid webgui 登录失败：用户名或密码错误")
+		}
+	default:
+		return "", "", fmt.Errorf("unraid webgui 登录失败：http status %d", res.StatusCode)
+	}
+	if len(cookies) == 0 {
+		return "", "", errors.New("unraid webgui 登录失败：未返回会话 Cookie")
+	}
+
+	pageURL := webGUIPageURL(loginURL, res.Header.Get("Location"))
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Cookie", joinCookies(cookies, order))
+	res, err = hc.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("unraid webgui 获取 csrf_token 失败：%w", err)
+	}
+	b, _ = io.ReadAll(io.LimitReader(res.Body, 512<<10))
+	res.Body.Close()
+	mergeCookies(cookies, &order, res.Cookies())
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", "", fmt.Errorf("unraid webgui 获取 csrf_token 失败：http status %d", res.StatusCode)
+	}
+	csrf := extractCSRFToken(string(b))
+	if csrf == "" {
+		return "", "", errors.New("unraid webgui 获取 csrf_token 失败：页面中未找到 csrf_token")
+	}
+
+	slog.Info("unraid webgui 登录成功", "login_url", loginURL)
+	return csrf, joinCookies(cookies, order), nil
+}
+
+// webGUIPageURL 返回登录后用于抓取 csrf_token 的页面（优先跟随登录重定向，默认 /Dashboard）。
+func webGUIPageURL(loginURL string, location string) string {
+	base, err := url.Parse(loginURL)
+	if err != nil {
+		return loginURL
+	}
+	if loc := strings.TrimSpace(location); loc != "" {
+		if u, err := base.Parse(loc); err == nil && !strings.Contains(strings.ToLower(u.Path), "login") {
+			return u.String()
+		}
+	}
+	u, _ := base.Parse("Dashboard")
+	return u.String()
+}
+
+func extractCSRFToken(page string) string {
+	for _, re := range csrfTokenPatterns {
+		if m := re.FindStringSubmatch(page); len(m) == 2 {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func isWebGUILoginPage(bodyLower string) bool {
+	return strings.Contains(bodyLower, "login") && strings.Contains(bodyLower, "password")
+}
+
+func mergeCookies(dst map[string]string, order *[]string, cookies []*http.Cookie) {
+	for _, ck := range cookies {
+		if ck == nil || ck.Name == "" {
+			continue
+		}
+		if _, ok := dst[ck.Name]; !ok {
+			*order = append(*order, ck.Name)
+		}
+		dst[ck.Name] = ck.Value
+	}
+}
+
+func joinCookies(cookies map[string]string, order []string) string {
+	parts := make([]string, 0, len(order))
+	for _, name := range order {
+		parts = append(parts, name+"="+cookies[name])
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,181 @@
+package wecom
+
+// appchat.go 封装应用群聊（appchat/create、appchat/send），用于告警与操作播报投递到运维群。
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AppChat 定义群聊创建参数：UserList 至少 2 人（含群主），ChatID 为空时由企业微信生成。
+type AppChat struct {
+	Name     string
+	Owner    string
+	UserList []string
+	ChatID   string
+}
+
+// AppChatMessage 定义群聊消息；Markdown 为 true 时以 markdown 发送。
+type AppChatMessage struct {
+	ChatID   string
+	Content  string
+	Markdown bool
+}
+
+// CreateAppChat 创建群聊会话并返回 chatid。
+//
+// 官方文档（SSOT）：创建群聊会话
+// https://developer.work.weixin.qq.com/document/path/90245
+func (c *Client) CreateAppChat(ctx context.Context, chat AppChat) (string, error) {
+	if len(chat.UserList) < 2 {
+		return "", errors.New("wecom appchat/create: userlist 至少需要 2 人")
+	}
+	payload := map[string]interface{}{
+		"userlist": chat.UserList,
+	}
+	if s := strings.TrimSpace(chat.Name); s != "" {
+		payload["name"] = s
+	}
+	if s := strings.TrimSpace(chat.Owner); s != "" {
+		payload["owner"] = s
+	}
+	if s := strings.TrimSpace(chat.ChatID); s != "" {
+		payload["chatid"] = s
+	}
+
+	var out struct {
+		apiResult
+		ChatID string `json:"chatid"`
+	}
+	if err := c.postJSON(ctx, "appchat/create", payload, &out); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(out.ChatID) == "" {
+		return "", errors.New("wecom appchat/create 返回 chatid 为空")
+	}
+	return out.ChatID, nil
+}
+
+// SendAppChat 推送消息到群聊会话（应用需为群聊创建者）。
+//
+// 官方文档（SSOT）：应用推送消息
+// https://developer.work.weixin.qq.com/document/path/90248
+func (c *Client) SendAppChat(ctx context.Context, msg AppChatMessage) error {
+	if strings.TrimSpace(msg.ChatID) == "" {
+		return errors.New("wecom appchat/send: chatid 为空")
+	}
+	msgType := "text"
+	if msg.Markdown {
+		msgType = "markdown"
+	}
+	payload := map[string]interface{}{
+		"chatid":  msg.ChatID,
+		"msgtype": msgType,
+		msgType: map[string]interface{}{
+			"content": msg.Content,
+		},
+	}
+	var out apiResult
+	return c.postJSON(ctx, "appchat/send", payload, &out)
+}
+
+// apiResult 为企业微信接口通用返回字段。
+type apiResult struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (r *apiResult) result() *apiResult { return r }
+
+type apiResponse interface {
+	result() *apiResult
+}
+
+// postJSON 携带 access_token 调用 POST JSON 接口，errcode 非 0 时返回错误。
+func (c *Client) postJSON(ctx context.Context, endpoint string, payload interface{}, out apiResponse) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.callJSON(ctx, http.MethodPost, endpoint, nil, body, out)
+}
+
+// getJSON 携带 access_token 调用 GET 接口（查询参数 query），errcode 非 0 时返回错误。
+func (c *Client) getJSON(ctx context.Context, endpoint string, query url.Values, out apiResponse) error {
+	return c.callJSON(ctx, http.MethodGet, endpoint, query, nil, out)
+}
+
+func (c *Client) callJSON(ctx context.Context, method, endpoint string, query url.Values, body []byte, out apiResponse) error {
+	return c.withRetry(ctx, endpoint, func(token string) error {
+		start := time.Now()
+
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("access_token", token)
+		u := c.cfg.APIBaseURL + "/" + endpoint + "?" + q.Encode()
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom "+endpoint+" HTTP 请求失败",
+				"error", err,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
+
+		if err := checkHTTPStatus(endpoint, res); err != nil {
+			slog.Error("wecom "+endpoint+" HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			slog.Error("wecom "+endpoint+" 解析响应失败",
+				"error", err,
+				"status_code", res.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		r := out.result()
+		attrs := []any{
+			"status_code", res.StatusCode,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"errcode", r.ErrCode,
+			"errmsg", r.ErrMsg,
+		}
+		if r.ErrCode != 0 {
+			apiErr := &APIError{Endpoint: endpoint, ErrCode: r.ErrCode, ErrMsg: r.ErrMsg}
+			slog.Error("wecom "+endpoint+" 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
+		if method == http.MethodGet {
+			// 查询接口（通讯录同步等）会周期调用，成功日志降为 Debug。
+			slog.Debug("wecom "+endpoint+" 成功", attrs...)
+			return nil
+		}
+		slog.Info("wecom "+endpoint+" 成功", attrs...)
+		return nil
+	})
+}
@@ -9,13 +9,17 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type CallbackDeps struct {
 	Crypto  *Crypto
 	Core    MessageHandler
 	Deduper *Deduper
+	// Replay 为可选的重放防护（timestamp 时钟偏差 + nonce 缓存），验签通过后校验。
+	Replay *ReplayGuard
 	// MaxBodyBytes 限制回调请求体大小，避免恶意超大 body 导致内存/CPU 被占满。默认 1MiB。
 	MaxBodyBytes int64
 }
@@ -131,6 +135,27 @@ func NewCallbackHandler(deps CallbackDeps) http.Handler {
 			return
 		}
 
+		if deps.Replay != nil {
+			if err := deps.Replay.Check(timestamp, nonce); err != nil {
+				stats := deps.Replay.Stats()
+				slog.Warn("wecom callback 疑似重放已拒绝",
+					"reason", err.Error(),
+					"timestamp", timestamp,
+					"nonce", nonce,
+					"rejected_stale_total", stats.Stale,
+					"rejected_replay_total", stats.Replayed,
+				)
+				if errors.Is(err, ErrCallbackReplay) {
+					// 同一请求重复到达（含企业微信超时重试）：应答成功以免继续重试，但不再处理。
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte("success"))
+					return
+				}
+				http.Error(w, "stale timestamp", http.StatusForbidden)
+				return
+			}
+		}
+
 		plain, err := deps.Crypto.Decrypt(env.Encrypt)
 		if err != nil {
 			slog.Warn("wecom callback 解密失败",
@@ -188,6 +213,28 @@ func NewCallbackHandler(deps CallbackDeps) http.Handler {
 			}
 		}
 
+		if replier, ok := deps.Core.(PassiveReplier); ok {
+			if content, ok := replier.PassiveReply(r.Context(), msg); ok {
+				body, err := encryptTextReply(deps.Crypto, msg, content, nonce)
+				if err == nil {
+					slog.Info("wecom callback 被动回复",
+						"user_id", strings.TrimSpace(msg.FromUserName),
+						"msg_type", strings.TrimSpace(msg.MsgType),
+						"content_len", len(content),
+					)
+					w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write(body)
+					return
+				}
+				// 构造失败时回退到主动发送（HandleMessage 会走 message/send）。
+				slog.Warn("wecom callback 被动回复构造失败，回退主动发送",
+					"error", err,
+					"user_id", strings.TrimSpace(msg.FromUserName),
+				)
+			}
+		}
+
 		if err := deps.Core.HandleMessage(r.Context(), msg); err != nil {
 			slog.Error("wecom callback 处理失败（不触发重试）",
 				"error", err,
@@ -220,3 +267,13 @@ func callbackDedupeKey(msg IncomingMessage, plain []byte) string {
 	}
 	return ""
 }
+
+// encryptTextReply 构造并加密被动回复的文本消息。
+func encryptTextReply(crypto *Crypto, msg IncomingMessage, content string, nonce string) ([]byte, error) {
+	now := time.Now()
+	plain, err := BuildTextReply(msg, content, now)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.EncryptReply(plain, strconv.FormatInt(now.Unix(), 10), nonce)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/xml"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -656,3 +657,78 @@ func mustEncrypt(t *testing.T, crypto *Crypto, plain []byte) string {
 	}
 	return encrypted
 }
+
+type passiveCoreHandler struct {
+	testCoreHandler
+}
+
+func (h *passiveCoreHandler) PassiveReply(_ context.Context, msg IncomingMessage) (string, bool) {
+	if strings.TrimSpace(msg.Content) != "ping" {
+		return "", false
+	}
+	return "pong", true
+}
+
+func TestCallbackHandler_PassiveReply_EncryptedResponse(t *testing.T) {
+	t.Parallel()
+
+	token := "test-token"
+	crypto := mustTestCrypto(t, token, "ww123")
+	core := &passiveCoreHandler{}
+	h := NewCallbackHandler(CallbackDeps{Crypto: crypto, Core: core})
+
+	post := func(content string, msgID string) *httptest.ResponseRecorder {
+		plain := []byte("<xml>" +
+			"<ToUserName><![CDATA[ww123]]></ToUserName>" +
+			"<FromUserName><![CDATA[user]]></FromUserName>" +
+			"<CreateTime>1700000000</CreateTime>" +
+			"<MsgType><![CDATA[text]]></MsgType>" +
+			"<Content><![CDATA[" + content + "]]></Content>" +
+			"<MsgId>" + msgID + "</MsgId>" +
+			"</xml>")
+		encrypted := mustEncrypt(t, crypto, plain)
+		timestamp, nonce := "1700000001", "nonce"
+		sig := signature(token, timestamp, nonce, encrypted)
+		body := []byte("<xml><Encrypt><![CDATA[" + encrypted + "]]></Encrypt></xml>")
+		req := httptest.NewRequest(http.MethodPost, "/wecom/callback?msg_signature="+sig+"&timestamp="+timestamp+"&nonce="+nonce, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("ping", "1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := core.Calls(); got != 0 {
+		t.Fatalf("HandleMessage calls = %d, want 0 (passive reply)", got)
+	}
+	var env struct {
+		Encrypt      string `xml:"Encrypt"`
+		MsgSignature string `xml:"MsgSignature"`
+		TimeStamp    string `xml:"TimeStamp"`
+		Nonce        string `xml:"Nonce"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &env); err != nil {
+		t.Fatalf("unmarshal reply envelope: %v (%s)", err, w.Body.String())
+	}
+	if env.Nonce != "nonce" || !crypto.VerifySignature(env.MsgSignature, env.TimeStamp, env.Nonce, env.Encrypt) {
+		t.Fatalf("reply envelope signature invalid: %+v", env)
+	}
+	plain, err := crypto.Decrypt(env.Encrypt)
+	if err != nil {
+		t.Fatalf("Decrypt(reply) error: %v", err)
+	}
+	var reply IncomingMessage
+	if err := xml.Unmarshal(plain, &reply); err != nil {
+		t.Fatalf("unmarshal reply: %v", err)
+	}
+	if reply.ToUserName != "user" || reply.FromUserName != "ww123" || reply.MsgType != "text" || reply.Content != "pong" {
+		t.Fatalf("reply = %+v", reply)
+	}
+
+	w = post("菜单", "2")
+	if w.Body.String() != "success" || core.Calls() != 1 {
+		t.Fatalf("non-passive message: body=%q calls=%d, want success/1", w.Body.String(), core.Calls())
+	}
+}
@@ -21,6 +21,9 @@ type ClientConfig struct {
 	CorpID     string
 	AgentID    int
 	Secret     string
+
+	// Retry 为暂时性错误（系统繁忙/5xx/网络错误）的退避重试参数，零值使用默认。
+	Retry RetryPolicy
 }
 
 type Client struct {
@@ -50,6 +53,7 @@ func (c *Client) SendText(ctx context.Context, msg TextMessage) error {
 			"content": msg.Content,
 		},
 	}
+	addRecipients(payload, msg.ToParty, msg.ToTag)
 	return c.sendMessage(ctx, payload)
 }
 
@@ -63,78 +67,167 @@ func (c *Client) SendTemplateCard(ctx context.Context, msg TemplateCardMessage)
 		"agentid":       c.cfg.AgentID,
 		"template_card": msg.Card,
 	}
+	addRecipients(payload, msg.ToParty, msg.ToTag)
 	return c.sendMessage(ctx, payload)
 }
 
+// addRecipients 按需写入部门/标签接收者（message/send 的 toparty/totag，“|”分隔）。
+func addRecipients(payload map[string]interface{}, toParty, toTag string) {
+	if toParty != "" {
+		payload["toparty"] = toParty
+	}
+	if toTag != "" {
+		payload["totag"] = toTag
+	}
+}
+
 // CreateMenu 创建/覆盖企业微信自建应用的自定义菜单。
 //
 // 官方文档（SSOT）：创建菜单
 // https://developer.work.weixin.qq.com/document/path/90231
 func (c *Client) CreateMenu(ctx context.Context, menu Menu) error {
-	start := time.Now()
+	return c.withRetry(ctx, "menu/create", func(token string) error {
+		start := time.Now()
 
-	token, err := c.getAccessToken(ctx)
-	if err != nil {
-		slog.Error("wecom menu/create 获取 access_token 失败", "error", err)
-		return err
-	}
+		u := c.cfg.APIBaseURL +
+			"/menu/create?access_token=" + url.QueryEscape(token) +
+			"&agentid=" + strconv.Itoa(c.cfg.AgentID)
+		body, err := json.Marshal(menu)
+		if err != nil {
+			slog.Error("wecom menu/create 编码 payload 失败", "error", err)
+			return err
+		}
 
-	u := c.cfg.APIBaseURL +
-		"/menu/create?access_token=" + url.QueryEscape(token) +
-		"&agentid=" + strconv.Itoa(c.cfg.AgentID)
-	body, err := json.Marshal(menu)
-	if err != nil {
-		slog.Error("wecom menu/create 编码 payload 失败", "error", err)
-		return err
-	}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+		if err != nil {
+			slog.Error("wecom menu/create 创建请求失败", "error", err)
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
-	if err != nil {
-		slog.Error("wecom menu/create 创建请求失败", "error", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom menu/create HTTP 请求失败",
+				"error", err,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
 
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		slog.Error("wecom menu/create HTTP 请求失败",
-			"error", err,
+		if err := checkHTTPStatus("menu/create", res); err != nil {
+			slog.Error("wecom menu/create HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		var out struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			slog.Error("wecom menu/create 解析响应失败",
+				"error", err,
+				"status_code", res.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		attrs := []any{
+			"status_code", res.StatusCode,
 			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
-	defer res.Body.Close()
+			"errcode", out.ErrCode,
+			"errmsg", out.ErrMsg,
+			"agentid", c.cfg.AgentID,
+			"top_buttons", len(menu.Buttons),
+		}
 
-	var out struct {
-		ErrCode int    `json:"errcode"`
-		ErrMsg  string `json:"errmsg"`
-	}
-	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
-		slog.Error("wecom menu/create 解析响应失败",
-			"error", err,
+		if out.ErrCode != 0 {
+			apiErr := &APIError{Endpoint: "menu/create", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
+			slog.Error("wecom menu/create 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
+
+		slog.Info("wecom menu/create 成功", attrs...)
+		return nil
+	})
+}
+
+// GetMenu 获取企业微信自建应用当前的自定义菜单；未设置菜单（46003）时返回空菜单。
+//
+// 官方文档（SSOT）：获取菜单
+// https://developer.work.weixin.qq.com/document/path/90232
+func (c *Client) GetMenu(ctx context.Context) (Menu, error) {
+	var menu Menu
+	err := c.withRetry(ctx, "menu/get", func(token string) error {
+		start := time.Now()
+
+		u := c.cfg.APIBaseURL +
+			"/menu/get?access_token=" + url.QueryEscape(token) +
+			"&agentid=" + strconv.Itoa(c.cfg.AgentID)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			slog.Error("wecom menu/get 创建请求失败", "error", err)
+			return err
+		}
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom menu/get HTTP 请求失败",
+				"error", err,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
+
+		if err := checkHTTPStatus("menu/get", res); err != nil {
+			slog.Error("wecom menu/get HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		var out struct {
+			ErrCode int          `json:"errcode"`
+			ErrMsg  string       `json:"errmsg"`
+			Buttons []MenuButton `json:"button"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			slog.Error("wecom menu/get 解析响应失败",
+				"error", err,
+				"status_code", res.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		attrs := []any{
 			"status_code", res.StatusCode,
 			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
+			"errcode", out.ErrCode,
+			"errmsg", out.ErrMsg,
+			"agentid", c.cfg.AgentID,
+			"top_buttons", len(out.Buttons),
+		}
 
-	attrs := []any{
-		"status_code", res.StatusCode,
-		"duration_ms", time.Since(start).Milliseconds(),
-		"errcode", out.ErrCode,
-		"errmsg", out.ErrMsg,
-		"agentid", c.cfg.AgentID,
-		"top_buttons", len(menu.Buttons),
-	}
+		switch out.ErrCode {
+		case 0:
+			menu = Menu{Buttons: out.Buttons}
+		case 46003:
+			// 菜单不存在：视为空菜单。
+			menu = Menu{}
+		default:
+			apiErr := &APIError{Endpoint: "menu/get", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
+			slog.Error("wecom menu/get 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
 
-	if out.ErrCode != 0 {
-		apiErr := fmt.Errorf("wecom api error: %d %s", out.ErrCode, out.ErrMsg)
-		slog.Error("wecom menu/create 返回错误", append(attrs, "error", apiErr)...)
-		return apiErr
+		slog.Debug("wecom menu/get 成功", attrs...)
+		return nil
+	})
+	if err != nil {
+		return Menu{}, err
 	}
-
-	slog.Info("wecom menu/create 成功", attrs...)
-	return nil
+	return menu, nil
 }
 
 // UpdateTemplateCardButton 将模板卡片的按钮更新为不可点击状态（替换按钮文案）。
@@ -160,8 +253,9 @@ func (c *Client) UpdateTemplateCardButton(ctx context.Context, responseCode stri
 }
 
 func (c *Client) sendMessage(ctx context.Context, payload map[string]interface{}) error {
-	start := time.Now()
 	toUser, _ := payload["touser"].(string)
+	toParty, _ := payload["toparty"].(string)
+	toTag, _ := payload["totag"].(string)
 	msgType, _ := payload["msgtype"].(string)
 	contentLen := 0
 	if textBody, ok := payload["text"].(map[string]interface{}); ok {
@@ -189,181 +283,181 @@ func (c *Client) sendMessage(ctx context.Context, payload map[string]interface{}
 		}
 	}
 
-	token, err := c.getAccessToken(ctx)
-	if err != nil {
-		slog.Error("wecom message/send 获取 access_token 失败",
-			"error", err,
-			"to_user", toUser,
-			"msgtype", msgType,
-		)
-		return err
-	}
+	return c.withRetry(ctx, "message/send", func(token string) error {
+		start := time.Now()
 
-	u := c.cfg.APIBaseURL + "/message/send?access_token=" + url.QueryEscape(token)
-	body, err := json.Marshal(payload)
-	if err != nil {
-		slog.Error("wecom message/send 编码 payload 失败",
-			"error", err,
-			"to_user", toUser,
-			"msgtype", msgType,
-		)
-		return err
-	}
+		u := c.cfg.APIBaseURL + "/message/send?access_token=" + url.QueryEscape(token)
+		body, err := json.Marshal(payload)
+		if err != nil {
+			slog.Error("wecom message/send 编码 payload 失败",
+				"error", err,
+				"to_user", toUser,
+				"msgtype", msgType,
+			)
+			return err
+		}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
-	if err != nil {
-		slog.Error("wecom message/send 创建请求失败",
-			"error", err,
-			"to_user", toUser,
-			"msgtype", msgType,
-		)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+		if err != nil {
+			slog.Error("wecom message/send 创建请求失败",
+				"error", err,
+				"to_user", toUser,
+				"msgtype", msgType,
+			)
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		slog.Error("wecom message/send HTTP 请求失败",
-			"error", err,
-			"to_user", toUser,
-			"msgtype", msgType,
-			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
-	defer res.Body.Close()
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom message/send HTTP 请求失败",
+				"error", err,
+				"to_user", toUser,
+				"msgtype", msgType,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
 
-	var out struct {
-		ErrCode        int    `json:"errcode"`
-		ErrMsg         string `json:"errmsg"`
-		MsgID          string `json:"msgid"`
-		InvalidUser    string `json:"invaliduser"`
-		InvalidParty   string `json:"invalidparty"`
-		InvalidTag     string `json:"invalidtag"`
-		UnlicensedUser string `json:"unlicenseduser"`
-	}
-	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
-		slog.Error("wecom message/send 解析响应失败",
-			"error", err,
+		if err := checkHTTPStatus("message/send", res); err != nil {
+			slog.Error("wecom message/send HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		var out struct {
+			ErrCode        int    `json:"errcode"`
+			ErrMsg         string `json:"errmsg"`
+			MsgID          string `json:"msgid"`
+			InvalidUser    string `json:"invaliduser"`
+			InvalidParty   string `json:"invalidparty"`
+			InvalidTag     string `json:"invalidtag"`
+			UnlicensedUser string `json:"unlicenseduser"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			slog.Error("wecom message/send 解析响应失败",
+				"error", err,
+				"to_user", toUser,
+				"msgtype", msgType,
+				"status_code", res.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		attrs := []any{
 			"to_user", toUser,
+			"to_party", toParty,
+			"to_tag", toTag,
 			"msgtype", msgType,
+			"content_len", contentLen,
+			"card_type", cardType,
+			"task_id", taskID,
 			"status_code", res.StatusCode,
 			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
-
-	attrs := []any{
-		"to_user", toUser,
-		"msgtype", msgType,
-		"content_len", contentLen,
-		"card_type", cardType,
-		"task_id", taskID,
-		"status_code", res.StatusCode,
-		"duration_ms", time.Since(start).Milliseconds(),
-		"errcode", out.ErrCode,
-		"errmsg", out.ErrMsg,
-		"msgid", out.MsgID,
-		"invaliduser", out.InvalidUser,
-		"invalidparty", out.InvalidParty,
-		"invalidtag", out.InvalidTag,
-		"unlicenseduser", out.UnlicensedUser,
-	}
+			"errcode", out.ErrCode,
+			"errmsg", out.ErrMsg,
+			"msgid", out.MsgID,
+			"invaliduser", out.InvalidUser,
+			"invalidparty", out.InvalidParty,
+			"invalidtag", out.InvalidTag,
+			"unlicenseduser", out.UnlicensedUser,
+		}
 
-	if out.ErrCode != 0 {
-		apiErr := fmt.Errorf("wecom api error: %d %s", out.ErrCode, out.ErrMsg)
-		slog.Error("wecom message/send 返回错误", append(attrs, "error", apiErr)...)
-		return apiErr
-	}
+		if out.ErrCode != 0 {
+			apiErr := &APIError{Endpoint: "message/send", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
+			slog.Error("wecom message/send 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
 
-	if out.InvalidUser != "" || out.InvalidParty != "" || out.InvalidTag != "" || out.UnlicensedUser != "" {
-		apiErr := fmt.Errorf("wecom message/send 部分失败: invaliduser=%s invalidparty=%s invalidtag=%s unlicenseduser=%s",
-			out.InvalidUser, out.InvalidParty, out.InvalidTag, out.UnlicensedUser,
-		)
-		slog.Warn("wecom message/send 部分失败", append(attrs, "error", apiErr)...)
-		return apiErr
-	}
+		if out.InvalidUser != "" || out.InvalidParty != "" || out.InvalidTag != "" || out.UnlicensedUser != "" {
+			apiErr := fmt.Errorf("wecom message/send 部分失败: invaliduser=%s invalidparty=%s invalidtag=%s unlicenseduser=%s",
+				out.InvalidUser, out.InvalidParty, out.InvalidTag, out.UnlicensedUser,
+			)
+			slog.Warn("wecom message/send 部分失败", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
 
-	slog.Info("wecom message/send 成功", attrs...)
-	return nil
+		slog.Info("wecom message/send 成功", attrs...)
+		return nil
+	})
 }
 
 func (c *Client) updateTemplateCard(ctx context.Context, payload map[string]interface{}) error {
-	start := time.Now()
 	responseCodeLen := 0
 	if v, ok := payload["response_code"].(string); ok {
 		responseCodeLen = len(v)
 	}
 
-	token, err := c.getAccessToken(ctx)
-	if err != nil {
-		slog.Error("wecom message/update_template_card 获取 access_token 失败",
-			"error", err,
-			"response_code_len", responseCodeLen,
-		)
-		return err
-	}
+	return c.withRetry(ctx, "message/update_template_card", func(token string) error {
+		start := time.Now()
 
-	u := c.cfg.APIBaseURL + "/message/update_template_card?access_token=" + url.QueryEscape(token)
-	body, err := json.Marshal(payload)
-	if err != nil {
-		slog.Error("wecom message/update_template_card 编码 payload 失败",
-			"error", err,
-			"response_code_len", responseCodeLen,
-		)
-		return err
-	}
+		u := c.cfg.APIBaseURL + "/message/update_template_card?access_token=" + url.QueryEscape(token)
+		body, err := json.Marshal(payload)
+		if err != nil {
+			slog.Error("wecom message/update_template_card 编码 payload 失败",
+				"error", err,
+				"response_code_len", responseCodeLen,
+			)
+			return err
+		}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
-	if err != nil {
-		slog.Error("wecom message/update_template_card 创建请求失败",
-			"error", err,
-			"response_code_len", responseCodeLen,
-		)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+		if err != nil {
+			slog.Error("wecom message/update_template_card 创建请求失败",
+				"error", err,
+				"response_code_len", responseCodeLen,
+			)
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		slog.Error("wecom message/update_template_card HTTP 请求失败",
-			"error", err,
-			"response_code_len", responseCodeLen,
-			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
-	defer res.Body.Close()
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom message/update_template_card HTTP 请求失败",
+				"error", err,
+				"response_code_len", responseCodeLen,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
 
-	var out struct {
-		ErrCode int    `json:"errcode"`
-		ErrMsg  string `json:"errmsg"`
-	}
-	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
-		slog.Error("wecom message/update_template_card 解析响应失败",
-			"error", err,
+		if err := checkHTTPStatus("message/update_template_card", res); err != nil {
+			slog.Error("wecom message/update_template_card HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		var out struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			slog.Error("wecom message/update_template_card 解析响应失败",
+				"error", err,
+				"status_code", res.StatusCode,
+				"response_code_len", responseCodeLen,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		attrs := []any{
 			"status_code", res.StatusCode,
-			"response_code_len", responseCodeLen,
 			"duration_ms", time.Since(start).Milliseconds(),
-		)
-		return err
-	}
-
-	attrs := []any{
-		"status_code", res.StatusCode,
-		"duration_ms", time.Since(start).Milliseconds(),
-		"errcode", out.ErrCode,
-		"errmsg", out.ErrMsg,
-		"response_code_len", responseCodeLen,
-	}
+			"errcode", out.ErrCode,
+			"errmsg", out.ErrMsg,
+			"response_code_len", responseCodeLen,
+		}
 
-	if out.ErrCode != 0 {
-		apiErr := fmt.Errorf("wecom api error: %d %s", out.ErrCode, out.ErrMsg)
-		slog.Error("wecom message/update_template_card 返回错误", append(attrs, "error", apiErr)...)
-		return apiErr
-	}
-	slog.Info("wecom message/update_template_card 成功", attrs...)
-	return nil
+		if out.ErrCode != 0 {
+			apiErr := &APIError{Endpoint: "message/update_template_card", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
+			slog.Error("wecom message/update_template_card 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
+		slog.Info("wecom message/update_template_card 成功", attrs...)
+		return nil
+	})
 }
 
 func (c *Client) getAccessToken(ctx context.Context) (string, error) {
@@ -428,6 +522,11 @@ func (c *Client) fetchAccessToken(ctx context.Context) (string, time.Time, error
 	}
 	defer res.Body.Close()
 
+	if err := checkHTTPStatus("gettoken", res); err != nil {
+		slog.Error("wecom gettoken HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+		return "", time.Time{}, err
+	}
+
 	var out struct {
 		ErrCode     int    `json:"errcode"`
 		ErrMsg      string `json:"errmsg"`
@@ -443,7 +542,7 @@ func (c *Client) fetchAccessToken(ctx context.Context) (string, time.Time, error
 		return "", time.Time{}, err
 	}
 	if out.ErrCode != 0 {
-		apiErr := fmt.Errorf("wecom gettoken error: %d %s", out.ErrCode, out.ErrMsg)
+		apiErr := &APIError{Endpoint: "gettoken", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
 		slog.Error("wecom gettoken 返回错误",
 			"error", apiErr,
 			"status_code", res.StatusCode,
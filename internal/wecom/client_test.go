@@ -3,9 +3,13 @@ package wecom
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -302,7 +306,10 @@ func TestClient_CreateMenu_RequestShape(t *testing.T) {
 		Secret:     "sec",
 	}, srv.Client())
 
-	if err := c.CreateMenu(context.Background(), DefaultMenu()); err != nil {
+	menu := Menu{Buttons: []MenuButton{
+		{Name: "常用", SubButtons: []MenuButton{{Type: "click", Name: "操作菜单", Key: EventKeyCoreMenu}}},
+	}}
+	if err := c.CreateMenu(context.Background(), menu); err != nil {
 		t.Fatalf("CreateMenu() error: %v", err)
 	}
 	select {
@@ -320,3 +327,388 @@ func TestClient_CreateMenu_RequestShape(t *testing.T) {
 		t.Fatalf("menu/create hits = %d, want 1", createHits)
 	}
 }
+
+func TestClient_UploadMediaAndSendFile_RequestShape(t *testing.T) {
+	t.Parallel()
+
+	var uploadHits int32
+	var sendHits int32
+	validateErr := make(chan error, 2)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errcode":      0,
+				"errmsg":       "ok",
+				"access_token": "AT",
+				"expires_in":   7200,
+			})
+			return
+		case "/media/upload":
+			atomic.AddInt32(&uploadHits, 1)
+			if got := r.URL.Query().Get("type"); got != MediaTypeFile {
+				validateErr <- fmt.Errorf("type = %q, want %q", got, MediaTypeFile)
+			}
+			f, hdr, err := r.FormFile("media")
+			if err != nil {
+				validateErr <- fmt.Errorf("FormFile(media) error: %w", err)
+			} else {
+				defer f.Close()
+				if hdr.Filename != "config.sh" {
+					validateErr <- fmt.Errorf("filename = %q, want %q", hdr.Filename, "config.sh")
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errcode":  0,
+				"errmsg":   "ok",
+				"type":     "file",
+				"media_id": "MID",
+			})
+			return
+		case "/message/send":
+			atomic.AddInt32(&sendHits, 1)
+			var payload map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			file, _ := payload["file"].(map[string]interface{})
+			if payload["msgtype"] != "file" || file["media_id"] != "MID" {
+				validateErr <- fmt.Errorf("payload = %#v", payload)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errcode": 0,
+				"errmsg":  "ok",
+			})
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{
+		APIBaseURL: srv.URL,
+		CorpID:     "ww",
+		AgentID:    1,
+		Secret:     "sec",
+	}, srv.Client())
+
+	ctx := context.Background()
+	mediaID, err := c.UploadMedia(ctx, MediaTypeFile, "config.sh", []byte("export A=1\n"))
+	if err != nil {
+		t.Fatalf("UploadMedia() error: %v", err)
+	}
+	if mediaID != "MID" {
+		t.Fatalf("media_id = %q, want %q", mediaID, "MID")
+	}
+	if err := c.SendFile(ctx, FileMessage{ToUser: "u", MediaID: mediaID}); err != nil {
+		t.Fatalf("SendFile() error: %v", err)
+	}
+	select {
+	case err := <-validateErr:
+		t.Fatalf("validate request error: %v", err)
+	default:
+	}
+
+	if atomic.LoadInt32(&uploadHits) != 1 {
+		t.Fatalf("media/upload hits = %d, want 1", uploadHits)
+	}
+	if atomic.LoadInt32(&sendHits) != 1 {
+		t.Fatalf("message/send hits = %d, want 1", sendHits)
+	}
+}
+
+func TestClient_SendMarkdown_RequestShapeAndTextFallback(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": "AT", "expires_in": 7200})
+		case "/message/send":
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "ok"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{APIBaseURL: srv.URL, CorpID: "ww", AgentID: 7, Secret: "sec"}, srv.Client())
+	content := "### 标题\n" + MarkdownBold("节点") + "\n- pve " + MarkdownColor(MarkdownColorWarning, "95%") + "\n> 引用 [文档](https://example.com) `code`"
+	if err := c.SendMarkdown(context.Background(), MarkdownMessage{ToUser: "u", Content: content}); err != nil {
+		t.Fatalf("SendMarkdown() error: %v", err)
+	}
+	if got["msgtype"] != "markdown" || got["agentid"] != float64(7) {
+		t.Fatalf("payload = %v", got)
+	}
+	md, _ := got["markdown"].(map[string]interface{})
+	if md["content"] != content {
+		t.Fatalf("markdown.content = %v", md["content"])
+	}
+
+	want := "【标题】\n节点\n- pve 95%\n引用 文档 https://example.com code"
+	if text := MarkdownToText(content); text != want {
+		t.Fatalf("MarkdownToText() = %q, want %q", text, want)
+	}
+}
+
+func TestClient_AppChat_CreateAndSendRequestShape(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	bodies := make(map[string]map[string]interface{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": "AT", "expires_in": 7200})
+		case "/appchat/create", "/appchat/send":
+			if r.URL.Query().Get("access_token") != "AT" {
+				t.Errorf("%s access_token = %q", r.URL.Path, r.URL.Query().Get("access_token"))
+			}
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			bodies[r.URL.Path] = body
+			mu.Unlock()
+			if r.URL.Path == "/appchat/create" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "ok", "chatid": "ops"})
+				return
+			}
+			if body["chatid"] == "missing" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 86003, "errmsg": "chat not exist"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "ok"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{APIBaseURL: srv.URL, CorpID: "ww", AgentID: 1, Secret: "sec"}, srv.Client())
+	ctx := context.Background()
+
+	if _, err := c.CreateAppChat(ctx, AppChat{Name: "ops", UserList: []string{"a"}}); err == nil {
+		t.Fatalf("CreateAppChat(1 user) error = nil, want error")
+	}
+	chatID, err := c.CreateAppChat(ctx, AppChat{Name: "运维群", Owner: "a", UserList: []string{"a", "b"}})
+	if err != nil || chatID != "ops" {
+		t.Fatalf("CreateAppChat() = %q, %v", chatID, err)
+	}
+	if err := c.SendAppChat(ctx, AppChatMessage{ChatID: "ops", Content: "**hi**", Markdown: true}); err != nil {
+		t.Fatalf("SendAppChat() error: %v", err)
+	}
+
+	mu.Lock()
+	create, send := bodies["/appchat/create"], bodies["/appchat/send"]
+	mu.Unlock()
+	if create["name"] != "运维群" || create["owner"] != "a" || len(create["userlist"].([]interface{})) != 2 {
+		t.Fatalf("create body = %v", create)
+	}
+	if _, ok := create["chatid"]; ok {
+		t.Fatalf("create body should omit empty chatid: %v", create)
+	}
+	md, _ := send["markdown"].(map[string]interface{})
+	if send["chatid"] != "ops" || send["msgtype"] != "markdown" || md["content"] != "**hi**" {
+		t.Fatalf("send body = %v", send)
+	}
+
+	if err := c.SendAppChat(ctx, AppChatMessage{ChatID: "missing", Content: "x"}); err == nil || !strings.Contains(err.Error(), "86003") {
+		t.Fatalf("SendAppChat(missing) error = %v", err)
+	}
+}
+
+func TestWebhookClient_SendRequestShape(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/webhook/send" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("key") != "k1" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 93000, "errmsg": "invalid webhook url"})
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "ok"})
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	c := NewWebhookClient(WebhookConfig{APIBaseURL: srv.URL, Key: "k1"}, srv.Client())
+	if err := c.Notify(ctx, "hello"); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	if err := c.SendMarkdown(ctx, MarkdownMessage{ToUser: "ignored", Content: "**hi**"}); err != nil {
+		t.Fatalf("SendMarkdown() error: %v", err)
+	}
+	if err := c.SendNews(ctx, NewsMessage{Articles: []NewsArticle{{Title: "t", URL: "https://example.com"}}}); err != nil {
+		t.Fatalf("SendNews() error: %v", err)
+	}
+	if err := c.SendTemplateCard(ctx, TemplateCardMessage{}); !errors.Is(err, ErrWebhookTemplateCardUnsupported) {
+		t.Fatalf("SendTemplateCard() error = %v, want ErrWebhookTemplateCardUnsupported", err)
+	}
+
+	mu.Lock()
+	got := bodies
+	mu.Unlock()
+	if len(got) != 3 {
+		t.Fatalf("requests = %d, want 3", len(got))
+	}
+	if text, _ := got[0]["text"].(map[string]interface{}); got[0]["msgtype"] != "text" || text["content"] != "hello" {
+		t.Fatalf("text body = %v", got[0])
+	}
+	if md, _ := got[1]["markdown"].(map[string]interface{}); got[1]["msgtype"] != "markdown" || md["content"] != "**hi**" {
+		t.Fatalf("markdown body = %v", got[1])
+	}
+	if _, ok := got[1]["touser"]; ok {
+		t.Fatalf("markdown body should not carry touser: %v", got[1])
+	}
+	news, _ := got[2]["news"].(map[string]interface{})
+	articles, _ := news["articles"].([]interface{})
+	if got[2]["msgtype"] != "news" || len(articles) != 1 {
+		t.Fatalf("news body = %v", got[2])
+	}
+
+	bad := NewWebhookClient(WebhookConfig{APIBaseURL: srv.URL, Key: "wrong"}, srv.Client())
+	if err := bad.Notify(ctx, "x"); err == nil || !strings.Contains(err.Error(), "93000") {
+		t.Fatalf("Notify(wrong key) error = %v, want errcode 93000", err)
+	}
+}
+
+func TestClient_Retry_TokenRefreshBackoffAndRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var getTokenHits int32
+	var sendHits int32
+	var mu sync.Mutex
+	var tokens []string
+	// script 为 message/send 依次返回的结果：errcode（500 表示 HTTP 500）。
+	script := []int{42001, 0, -1, 500, 0, 45009, -1, -1, -1}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			n := atomic.AddInt32(&getTokenHits, 1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": fmt.Sprintf("AT%d", n), "expires_in": 7200})
+		case "/message/send":
+			n := int(atomic.AddInt32(&sendHits, 1))
+			mu.Lock()
+			tokens = append(tokens, r.URL.Query().Get("access_token"))
+			mu.Unlock()
+			code := script[n-1]
+			if code == 500 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("<html>bad gateway</html>"))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": code, "errmsg": "scripted"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{
+		APIBaseURL: srv.URL,
+		CorpID:     "ww",
+		AgentID:    1,
+		Secret:     "sec",
+		Retry:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	}, srv.Client())
+	ctx := context.Background()
+
+	// 42001：强制刷新 token 后重试一次。
+	if err := c.SendText(ctx, TextMessage{ToUser: "u", Content: "a"}); err != nil {
+		t.Fatalf("SendText(token expired) error: %v", err)
+	}
+	mu.Lock()
+	if len(tokens) != 2 || tokens[0] != "AT1" || tokens[1] != "AT2" {
+		t.Fatalf("tokens = %v, want [AT1 AT2]", tokens)
+	}
+	mu.Unlock()
+
+	// -1、HTTP 500：退避重试后成功。
+	if err := c.SendText(ctx, TextMessage{ToUser: "u", Content: "b"}); err != nil {
+		t.Fatalf("SendText(busy) error: %v", err)
+	}
+	if got := atomic.LoadInt32(&sendHits); got != 5 {
+		t.Fatalf("message/send hits = %d, want 5", got)
+	}
+
+	// 45009：不重试，返回可识别的频率超限错误。
+	err := c.SendText(ctx, TextMessage{ToUser: "u", Content: "c"})
+	var apiErr *APIError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.ErrCode != 45009 {
+		t.Fatalf("SendText(rate limited) error = %v, want ErrRateLimited(45009)", err)
+	}
+	if got := atomic.LoadInt32(&sendHits); got != 6 {
+		t.Fatalf("message/send hits = %d, want 6 (no retry on rate limit)", got)
+	}
+
+	// 持续繁忙：达到 MaxAttempts 后返回最后一次错误。
+	err = c.SendText(ctx, TextMessage{ToUser: "u", Content: "d"})
+	if !errors.As(err, &apiErr) || !apiErr.Busy() {
+		t.Fatalf("SendText(always busy) error = %v, want busy APIError", err)
+	}
+	if got := atomic.LoadInt32(&sendHits); got != 9 {
+		t.Fatalf("message/send hits = %d, want 9", got)
+	}
+	if got := atomic.LoadInt32(&getTokenHits); got != 2 {
+		t.Fatalf("gettoken hits = %d, want 2", got)
+	}
+}
+
+func TestClient_Retry_NoImmediateRetryAfterRequestSent(t *testing.T) {
+	t.Parallel()
+
+	var sendHits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": "AT", "expires_in": 7200})
+		case "/message/send":
+			atomic.AddInt32(&sendHits, 1)
+			// 请求已被服务端读取后断开连接：客户端无法确认是否已投递。
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{
+		APIBaseURL: srv.URL,
+		CorpID:     "ww",
+		AgentID:    1,
+		Secret:     "sec",
+		Retry:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	}, srv.Client())
+
+	err := c.SendText(context.Background(), TextMessage{ToUser: "u", Content: "a"})
+	if err == nil {
+		t.Fatalf("SendText() error = nil, want connection error")
+	}
+	if got := atomic.LoadInt32(&sendHits); got != 1 {
+		t.Fatalf("message/send hits = %d, want 1 (no immediate retry once sent)", got)
+	}
+	if !outboxRetryable(err) {
+		t.Fatalf("outboxRetryable(%v) = false, want true", err)
+	}
+
+	dialErr := &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	if !isRetryable(dialErr) {
+		t.Fatalf("isRetryable(dial error) = false, want true")
+	}
+}
@@ -0,0 +1,212 @@
+package wecom
+
+// contact.go 封装通讯录查询（user/list、tag/get），并提供按部门/标签解析成员的 Directory（带缓存与定时刷新）。
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ListDepartmentUserIDs 返回部门（含子部门）成员的 userid。
+//
+// 官方文档（SSOT）：获取部门成员详情
+// https://developer.work.weixin.qq.com/document/path/90201
+func (c *Client) ListDepartmentUserIDs(ctx context.Context, departmentID int) ([]string, error) {
+	var out struct {
+		apiResult
+		UserList []struct {
+			UserID string `json:"userid"`
+		} `json:"userlist"`
+	}
+	query := url.Values{}
+	query.Set("department_id", strconv.Itoa(departmentID))
+	query.Set("fetch_child", "1")
+	if err := c.getJSON(ctx, "user/list", query, &out); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(out.UserList))
+	for _, u := range out.UserList {
+		ids = append(ids, u.UserID)
+	}
+	return ids, nil
+}
+
+// GetTagMembers 返回标签下的成员 userid 与部门 ID（标签可直接包含部门）。
+//
+// 官方文档（SSOT）：获取标签成员
+// https://developer.work.weixin.qq.com/document/path/90213
+func (c *Client) GetTagMembers(ctx context.Context, tagID int) (userIDs []string, departmentIDs []int, err error) {
+	var out struct {
+		apiResult
+		UserList []struct {
+			UserID string `json:"userid"`
+		} `json:"userlist"`
+		PartyList []int `json:"partylist"`
+	}
+	query := url.Values{}
+	query.Set("tagid", strconv.Itoa(tagID))
+	if err := c.getJSON(ctx, "tag/get", query, &out); err != nil {
+		return nil, nil, err
+	}
+	for _, u := range out.UserList {
+		userIDs = append(userIDs, u.UserID)
+	}
+	return userIDs, out.PartyList, nil
+}
+
+// DirectoryClient 为 Directory 依赖的通讯录查询能力（*Client 实现）。
+type DirectoryClient interface {
+	ListDepartmentUserIDs(ctx context.Context, departmentID int) ([]string, error)
+	GetTagMembers(ctx context.Context, tagID int) ([]string, []int, error)
+}
+
+// DirectoryConfig 定义需要解析的部门/标签与刷新间隔（默认 10 分钟）。
+type DirectoryConfig struct {
+	DepartmentIDs []int
+	TagIDs        []int
+	Refresh       time.Duration
+}
+
+// Directory 将部门/标签解析为成员集合并缓存；刷新失败时保留上一次结果。
+type Directory struct {
+	client DirectoryClient
+	cfg    DirectoryConfig
+
+	mu      sync.RWMutex
+	members map[string]struct{}
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewDirectory(client DirectoryClient, cfg DirectoryConfig) *Directory {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = 10 * time.Minute
+	}
+	return &Directory{
+		client:  client,
+		cfg:     cfg,
+		members: make(map[string]struct{}),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Contains 判断 userid 是否属于已解析的部门/标签成员。
+func (d *Directory) Contains(userID string) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.members[userID]
+	return ok
+}
+
+// Members 返回当前缓存的成员（已排序）。
+func (d *Directory) Members() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make([]string, 0, len(d.members))
+	for id := range d.members {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Refresh 重新拉取部门与标签成员；任一查询失败时不替换缓存，避免误拒合法成员。
+func (d *Directory) Refresh(ctx context.Context) error {
+	members := make(map[string]struct{})
+	departments := append([]int(nil), d.cfg.DepartmentIDs...)
+	var errs []error
+	for _, tagID := range d.cfg.TagIDs {
+		users, parties, err := d.client.GetTagMembers(ctx, tagID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, u := range users {
+			members[u] = struct{}{}
+		}
+		departments = append(departments, parties...)
+	}
+	seen := make(map[int]bool, len(departments))
+	for _, id := range departments {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		users, err := d.client.ListDepartmentUserIDs(ctx, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, u := range users {
+			members[u] = struct{}{}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.members = members
+	d.mu.Unlock()
+	slog.Info("wecom 通讯录成员已刷新",
+		"departments", len(d.cfg.DepartmentIDs),
+		"tags", len(d.cfg.TagIDs),
+		"members", len(members),
+	)
+	return nil
+}
+
+// Start 立即刷新一次并按间隔定时刷新。
+func (d *Directory) Start() {
+	if d == nil || d.client == nil || (len(d.cfg.DepartmentIDs) == 0 && len(d.cfg.TagIDs) == 0) {
+		return
+	}
+	d.startOnce.Do(func() {
+		go d.loop()
+	})
+}
+
+func (d *Directory) Close() {
+	if d == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+}
+
+func (d *Directory) loop() {
+	ticker := time.NewTicker(d.cfg.Refresh)
+	defer ticker.Stop()
+
+	d.refreshOnce()
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.refreshOnce()
+		}
+	}
+}
+
+func (d *Directory) refreshOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := d.Refresh(ctx); err != nil {
+		slog.Error("wecom 通讯录成员刷新失败，沿用上次结果", "error", err, "members", len(d.Members()))
+	}
+}
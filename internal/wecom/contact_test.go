@@ -0,0 +1,96 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDirectory_ResolvesDepartmentsAndTags(t *testing.T) {
+	t.Parallel()
+
+	var failTag atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": "AT", "expires_in": 7200})
+		case "/tag/get":
+			if failTag.Load() {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 40068, "errmsg": "invalid tagid"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errcode":   0,
+				"userlist":  []map[string]string{{"userid": "tagged"}},
+				"partylist": []int{5},
+			})
+		case "/user/list":
+			if r.URL.Query().Get("fetch_child") != "1" {
+				t.Errorf("fetch_child = %q, want 1", r.URL.Query().Get("fetch_child"))
+			}
+			users := map[string][]map[string]string{
+				"2": {{"userid": "ops"}},
+				"5": {{"userid": "nas"}},
+			}[r.URL.Query().Get("department_id")]
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "userlist": users})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{APIBaseURL: srv.URL, CorpID: "ww", AgentID: 1, Secret: "sec"}, srv.Client())
+	d := NewDirectory(c, DirectoryConfig{DepartmentIDs: []int{2}, TagIDs: []int{9}})
+	if err := d.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error: %v", err)
+	}
+	for _, id := range []string{"ops", "nas", "tagged"} {
+		if !d.Contains(id) {
+			t.Fatalf("Contains(%q) = false, members=%v", id, d.Members())
+		}
+	}
+	if d.Contains("stranger") {
+		t.Fatalf("Contains(stranger) = true")
+	}
+
+	// 刷新失败时保留上一次的成员，避免误拒。
+	failTag.Store(true)
+	if err := d.Refresh(context.Background()); err == nil {
+		t.Fatalf("Refresh() error = nil, want tag/get error")
+	}
+	if !d.Contains("tagged") || len(d.Members()) != 3 {
+		t.Fatalf("members after failed refresh = %v, want unchanged", d.Members())
+	}
+}
+
+func TestClient_SendText_PartyAndTagRecipients(t *testing.T) {
+	t.Parallel()
+
+	payloads := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gettoken":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "access_token": "AT", "expires_in": 7200})
+		case "/message/send":
+			var payload map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			payloads <- payload
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "ok"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(ClientConfig{APIBaseURL: srv.URL, CorpID: "ww", AgentID: 1, Secret: "sec"}, srv.Client())
+	if err := c.SendText(context.Background(), TextMessage{ToParty: "2|5", ToTag: "9", Content: "hi"}); err != nil {
+		t.Fatalf("SendText() error: %v", err)
+	}
+	payload := <-payloads
+	if payload["toparty"] != "2|5" || payload["totag"] != "9" || payload["touser"] != "" {
+		t.Fatalf("payload = %v, want toparty=2|5 totag=9", payload)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"sort"
@@ -133,6 +135,40 @@ func (c *Crypto) Encrypt(plaintext []byte, random16 []byte) (string, error) {
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
+// replyEnvelope 为被动回复的加密响应包。
+type replyEnvelope struct {
+	XMLName      xml.Name `xml:"xml"`
+	Encrypt      cdata    `xml:"Encrypt"`
+	MsgSignature cdata    `xml:"MsgSignature"`
+	TimeStamp    string   `xml:"TimeStamp"`
+	Nonce        cdata    `xml:"Nonce"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// EncryptReply 加密被动回复明文并签名，返回可直接写入回调响应体的 XML。
+//
+// 官方文档（SSOT）：被动回复消息格式 / 加解密方案
+// https://developer.work.weixin.qq.com/document/path/90241
+func (c *Crypto) EncryptReply(plainXML []byte, timestamp, nonce string) ([]byte, error) {
+	random16 := make([]byte, 16)
+	if _, err := rand.Read(random16); err != nil {
+		return nil, err
+	}
+	encrypted, err := c.Encrypt(plainXML, random16)
+	if err != nil {
+		return nil, err
+	}
+	return xml.Marshal(replyEnvelope{
+		Encrypt:      cdata{encrypted},
+		MsgSignature: cdata{signature(c.token, timestamp, nonce, encrypted)},
+		TimeStamp:    timestamp,
+		Nonce:        cdata{nonce},
+	})
+}
+
 func pkcs7Pad(b []byte, blockSize int) []byte {
 	padding := blockSize - (len(b) % blockSize)
 	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
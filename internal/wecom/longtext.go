@@ -0,0 +1,164 @@
+package wecom
+
+// longtext.go 处理超长文本：按行边界切分为带序号的多条消息，或配合 media/upload 以文件形式下发。
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextChunkBytes 为单条文本消息的分段上限（官方 content 上限 2048 字节，预留序号与余量）。
+const TextChunkBytes = 1800
+
+// LongTextMode 指定超长文本的下发方式，由调用点按场景选择。
+type LongTextMode string
+
+const (
+	// LongTextModeTruncate 截断为一条消息（默认）。
+	LongTextModeTruncate LongTextMode = "truncate"
+	// LongTextModeSplit 按行切分为多条带序号的消息。
+	LongTextModeSplit LongTextMode = "split"
+	// LongTextModeFile 上传为临时素材并发送文件消息。
+	LongTextModeFile LongTextMode = "file"
+)
+
+// ParseLongTextMode 解析配置值；空值返回 LongTextModeTruncate。
+func ParseLongTextMode(s string) (LongTextMode, bool) {
+	switch LongTextMode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", LongTextModeTruncate:
+		return LongTextModeTruncate, true
+	case LongTextModeSplit:
+		return LongTextModeSplit, true
+	case LongTextModeFile:
+		return LongTextModeFile, true
+	default:
+		return "", false
+	}
+}
+
+// splitPrefixReserve 为分段序号前缀预留的字节数（例如 "（12/34）\n"）。
+const splitPrefixReserve = 24
+
+// SplitText 将 content 按行切分为多段，每段（含序号前缀）不超过 maxBytes 字节。
+// 仅一段时不加序号；单行超长时按 UTF-8 字符边界硬切。
+func SplitText(content string, maxBytes int) []string {
+	if maxBytes <= 0 {
+		maxBytes = TextChunkBytes
+	}
+	if len(content) <= maxBytes {
+		return []string{content}
+	}
+
+	budget := maxBytes - splitPrefixReserve
+	if budget < 1 {
+		budget = 1
+	}
+
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		for len(line) > budget {
+			flush()
+			cut := utf8Prefix(line, budget)
+			chunks = append(chunks, cut)
+			line = line[len(cut):]
+		}
+		need := len(line)
+		if cur.Len() > 0 {
+			need++
+		}
+		if cur.Len()+need > budget {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte('\n')
+		}
+		cur.WriteString(line)
+	}
+	flush()
+
+	if len(chunks) <= 1 {
+		return chunks
+	}
+	for i := range chunks {
+		chunks[i] = fmt.Sprintf("（%d/%d）\n%s", i+1, len(chunks), chunks[i])
+	}
+	return chunks
+}
+
+// TruncateSuffix 为截断模式追加的提示。
+const TruncateSuffix = "\n…（已截断）"
+
+// truncateTailNote 为保留末尾截断时替代被省略内容的提示。
+const truncateTailNote = "\n…（前略）\n"
+
+// TruncateText 将 content 截断到不超过 maxBytes 字节（含截断提示），不截断 UTF-8 字符。
+func TruncateText(content string, maxBytes int) string {
+	if maxBytes <= 0 {
+		maxBytes = TextChunkBytes
+	}
+	if len(content) <= maxBytes {
+		return content
+	}
+	if maxBytes <= len(TruncateSuffix) {
+		return utf8Prefix(content, maxBytes)
+	}
+	return utf8Prefix(content, maxBytes-len(TruncateSuffix)) + TruncateSuffix
+}
+
+// TruncateTextTail 保留首行（标题）与最新的末尾内容，截断到不超过 maxBytes 字节，适用于日志。
+// 末尾内容尽量从整行开始；无标题行或标题过长时退回 TruncateText。
+func TruncateTextTail(content string, maxBytes int) string {
+	if maxBytes <= 0 {
+		maxBytes = TextChunkBytes
+	}
+	if len(content) <= maxBytes {
+		return content
+	}
+	head, body, ok := strings.Cut(content, "\n")
+	budget := maxBytes - len(head) - len(truncateTailNote)
+	if !ok || budget <= 0 {
+		return TruncateText(content, maxBytes)
+	}
+	tail := utf8Suffix(body, budget)
+	if start := len(body) - len(tail); start > 0 && body[start-1] != '\n' {
+		if i := strings.IndexByte(tail, '\n'); i >= 0 && i+1 < len(tail) {
+			tail = tail[i+1:]
+		}
+	}
+	return head + truncateTailNote + tail
+}
+
+// utf8Suffix 返回 s 不超过 maxBytes 字节且不截断 UTF-8 字符的最长后缀（可能为空）。
+func utf8Suffix(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := len(s) - maxBytes
+	for cut < len(s) && !utf8.RuneStart(s[cut]) {
+		cut++
+	}
+	return s[cut:]
+}
+
+// utf8Prefix 返回 s 不超过 maxBytes 字节且不截断 UTF-8 字符的最长前缀（至少包含一个字符）。
+func utf8Prefix(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		cut = size
+	}
+	return s[:cut]
+}
@@ -0,0 +1,64 @@
+package wecom
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitText_LineBoundariesAndNumbering(t *testing.T) {
+	t.Parallel()
+
+	if got := SplitText("short", 100); len(got) != 1 || got[0] != "short" {
+		t.Fatalf("SplitText(short) = %q", got)
+	}
+
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, strings.Repeat("日", 10)) // 30 bytes
+	}
+	content := strings.Join(lines, "\n")
+	parts := SplitText(content, 200)
+	if len(parts) < 2 {
+		t.Fatalf("parts = %d, want >1", len(parts))
+	}
+	var rebuilt []string
+	for i, p := range parts {
+		if len(p) > 200 {
+			t.Fatalf("part %d len = %d, want <=200", i, len(p))
+		}
+		prefix, body, ok := strings.Cut(p, "\n")
+		if !ok || !strings.HasPrefix(prefix, "（") || !strings.HasSuffix(prefix, "/"+strconv.Itoa(len(parts))+"）") {
+			t.Fatalf("part %d prefix = %q", i, prefix)
+		}
+		rebuilt = append(rebuilt, body)
+	}
+	if got := strings.Join(rebuilt, "\n"); got != content {
+		t.Fatalf("rebuilt content mismatch")
+	}
+
+	// 单行超长按 UTF-8 边界硬切。
+	long := strings.Repeat("界", 100)
+	for _, p := range SplitText(long, 64) {
+		if !utf8.ValidString(p) || len(p) > 64 {
+			t.Fatalf("invalid hard-cut part %q", p)
+		}
+	}
+
+	if got := TruncateText(long, 50); len(got) > 50 || !utf8.ValidString(got) || !strings.HasSuffix(got, "（已截断）") {
+		t.Fatalf("TruncateText = %q", got)
+	}
+
+	// 保留末尾：标题行与最新的整行内容。
+	logText := "标题\n" + strings.Repeat("旧日志行\n", 20) + "最新一行"
+	got := TruncateTextTail(logText, 64)
+	if len(got) > 64 || !utf8.ValidString(got) || !strings.HasPrefix(got, "标题\n…（前略）\n") || !strings.HasSuffix(got, "\n最新一行") {
+		t.Fatalf("TruncateTextTail = %q", got)
+	}
+	for _, line := range strings.Split(strings.TrimPrefix(got, "标题\n…（前略）\n"), "\n") {
+		if line != "旧日志行" && line != "最新一行" {
+			t.Fatalf("TruncateTextTail kept partial line %q", line)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package wecom
+
+// markdown.go 封装 markdown 消息发送与格式辅助，并提供不支持 markdown 时的纯文本降级。
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// MarkdownMessage 定义 markdown 消息（msgtype=markdown，content 上限 2048 字节）。
+// 仅企业微信客户端可渲染；微信插件/微工作台会显示为“暂不支持”，需要时请降级为文本。
+type MarkdownMessage struct {
+	ToUser  string
+	Content string
+}
+
+// markdown 支持的字体颜色（绿色/灰色/橙红色）。
+const (
+	MarkdownColorInfo    = "info"
+	MarkdownColorComment = "comment"
+	MarkdownColorWarning = "warning"
+)
+
+// SendMarkdown 发送 markdown 消息。
+//
+// 官方文档（SSOT）：发送应用消息 - markdown 消息
+// https://developer.work.weixin.qq.com/document/path/90236
+func (c *Client) SendMarkdown(ctx context.Context, msg MarkdownMessage) error {
+	payload := map[string]interface{}{
+		"touser":  msg.ToUser,
+		"msgtype": "markdown",
+		"agentid": c.cfg.AgentID,
+		"markdown": map[string]interface{}{
+			"content": msg.Content,
+		},
+	}
+	return c.sendMessage(ctx, payload)
+}
+
+// MarkdownColor 返回带颜色的文本片段。
+func MarkdownColor(color string, text string) string {
+	return `<font color="` + color + `">` + text + `</font>`
+}
+
+// MarkdownBold 返回加粗文本片段。
+func MarkdownBold(text string) string {
+	return "**" + text + "**"
+}
+
+var (
+	markdownFontPattern    = regexp.MustCompile(`(?i)<font[^>]*>(.*?)</font>`)
+	markdownLinkPattern    = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+	markdownHeadingPattern = regexp.MustCompile(`(?m)^#{1,6}\s+(.*)$`)
+	markdownQuotePattern   = regexp.MustCompile(`(?m)^>\s?`)
+)
+
+// MarkdownToText 将 markdown 内容降级为纯文本：去除颜色/加粗/行内代码标记，标题改为【】，链接展开为“文字 地址”。
+func MarkdownToText(content string) string {
+	s := markdownFontPattern.ReplaceAllString(content, "$1")
+	s = markdownLinkPattern.ReplaceAllString(s, "$1 $2")
+	s = markdownHeadingPattern.ReplaceAllString(s, "【$1】")
+	s = markdownQuotePattern.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "**", "")
+	s = strings.ReplaceAll(s, "`", "")
+	return s
+}
@@ -0,0 +1,148 @@
+package wecom
+
+// media.go 封装临时素材上传（media/upload）与文件消息发送，用于超长内容以“文件”形式下发。
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MediaTypeFile 为临时素材的“普通文件”类型（上限 20MB）。
+const MediaTypeFile = "file"
+
+// FileMessage 定义文件消息（msgtype=file），MediaID 来自 UploadMedia。
+type FileMessage struct {
+	ToUser  string
+	MediaID string
+}
+
+// SendFile 发送文件消息。
+//
+// 官方文档（SSOT）：发送应用消息 - 文件消息
+// https://developer.work.weixin.qq.com/document/path/90236
+func (c *Client) SendFile(ctx context.Context, msg FileMessage) error {
+	if strings.TrimSpace(msg.MediaID) == "" {
+		return errors.New("wecom send file: media_id 为空")
+	}
+	payload := map[string]interface{}{
+		"touser":  msg.ToUser,
+		"msgtype": "file",
+		"agentid": c.cfg.AgentID,
+		"file": map[string]interface{}{
+			"media_id": msg.MediaID,
+		},
+	}
+	return c.sendMessage(ctx, payload)
+}
+
+// UploadMedia 上传临时素材并返回 media_id（有效期 3 天）。
+//
+// 官方文档（SSOT）：上传临时素材
+// https://developer.work.weixin.qq.com/document/path/90253
+func (c *Client) UploadMedia(ctx context.Context, mediaType string, filename string, data []byte) (string, error) {
+	mediaType = strings.TrimSpace(mediaType)
+	if mediaType == "" {
+		mediaType = MediaTypeFile
+	}
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		return "", errors.New("wecom media/upload: filename 为空")
+	}
+	if len(data) == 0 {
+		return "", errors.New("wecom media/upload: 文件内容为空")
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("media", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	var mediaID string
+	err = c.withRetry(ctx, "media/upload", func(token string) error {
+		start := time.Now()
+
+		u := c.cfg.APIBaseURL +
+			"/media/upload?access_token=" + url.QueryEscape(token) +
+			"&type=" + url.QueryEscape(mediaType)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			slog.Error("wecom media/upload 创建请求失败", "error", err)
+			return err
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		res, err := c.httpClient.Do(req)
+		if err != nil {
+			slog.Error("wecom media/upload HTTP 请求失败",
+				"error", err,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+		defer res.Body.Close()
+
+		if err := checkHTTPStatus("media/upload", res); err != nil {
+			slog.Error("wecom media/upload HTTP 状态异常", "error", err, "duration_ms", time.Since(start).Milliseconds())
+			return err
+		}
+
+		var out struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+			Type    string `json:"type"`
+			MediaID string `json:"media_id"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			slog.Error("wecom media/upload 解析响应失败",
+				"error", err,
+				"status_code", res.StatusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return err
+		}
+
+		attrs := []any{
+			"status_code", res.StatusCode,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"errcode", out.ErrCode,
+			"errmsg", out.ErrMsg,
+			"media_type", mediaType,
+			"filename", filename,
+			"file_bytes", len(data),
+		}
+
+		if out.ErrCode != 0 {
+			apiErr := &APIError{Endpoint: "media/upload", ErrCode: out.ErrCode, ErrMsg: out.ErrMsg}
+			slog.Error("wecom media/upload 返回错误", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
+		if strings.TrimSpace(out.MediaID) == "" {
+			apiErr := errors.New("wecom media/upload 返回 media_id 为空")
+			slog.Error("wecom media/upload 返回为空", append(attrs, "error", apiErr)...)
+			return apiErr
+		}
+
+		slog.Info("wecom media/upload 成功", attrs...)
+		mediaID = out.MediaID
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return mediaID, nil
+}
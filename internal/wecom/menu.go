@@ -0,0 +1,155 @@
+package wecom
+
+// menu.go 定义应用自定义菜单的结构、官方限制校验与差异对比（--wecom-sync-menu 预览）。
+import (
+	"fmt"
+	"strings"
+)
+
+// 自定义菜单限制（SSOT：https://developer.work.weixin.qq.com/document/path/90231）。
+const (
+	MaxMenuButtons    = 3
+	MaxMenuSubButtons = 5
+
+	maxMenuNameBytes    = 16
+	maxSubMenuNameBytes = 40
+)
+
+// Menu 定义企业微信“应用自定义菜单”的请求体。
+//
+// 官方文档（SSOT）：
+// - 创建菜单：https://developer.work.weixin.qq.com/document/path/90231
+// - 获取菜单：https://developer.work.weixin.qq.com/document/path/90232
+// - 删除菜单：https://developer.work.weixin.qq.com/document/path/90233
+type Menu struct {
+	Buttons []MenuButton `json:"button"`
+}
+
+// MenuButton 定义菜单按钮（最多 3 个一级按钮，每个最多 5 个二级按钮）。
+type MenuButton struct {
+	Type string `json:"type,omitempty"`
+	Name string `json:"name"`
+	Key  string `json:"key,omitempty"`
+	URL  string `json:"url,omitempty"`
+
+	SubButtons []MenuButton `json:"sub_button,omitempty"`
+}
+
+// Validate 校验按钮数量、名称长度与按钮类型，避免 menu/create 返回难以定位的错误码。
+func (m Menu) Validate() error {
+	var problems []string
+	if len(m.Buttons) == 0 {
+		problems = append(problems, "一级按钮不能为空")
+	}
+	if len(m.Buttons) > MaxMenuButtons {
+		problems = append(problems, fmt.Sprintf("一级按钮最多 %d 个（当前 %d）", MaxMenuButtons, len(m.Buttons)))
+	}
+	for _, b := range m.Buttons {
+		problems = append(problems, b.problems(maxMenuNameBytes)...)
+		if len(b.SubButtons) > MaxMenuSubButtons {
+			problems = append(problems, fmt.Sprintf("“%s”子按钮最多 %d 个（当前 %d）", b.Name, MaxMenuSubButtons, len(b.SubButtons)))
+		}
+		for _, sub := range b.SubButtons {
+			problems = append(problems, sub.problems(maxSubMenuNameBytes)...)
+			if len(sub.SubButtons) > 0 {
+				problems = append(problems, fmt.Sprintf("“%s”不支持三级菜单", sub.Name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("自定义菜单不合法：%s", strings.Join(problems, "；"))
+	}
+	return nil
+}
+
+func (b MenuButton) problems(maxNameBytes int) []string {
+	var problems []string
+	if strings.TrimSpace(b.Name) == "" {
+		problems = append(problems, "按钮名称不能为空")
+	} else if len(b.Name) > maxNameBytes {
+		problems = append(problems, fmt.Sprintf("“%s”名称过长（最多 %d 字节）", b.Name, maxNameBytes))
+	}
+	if len(b.SubButtons) > 0 {
+		return problems
+	}
+	switch b.Type {
+	case "click":
+		if b.Key == "" {
+			problems = append(problems, fmt.Sprintf("“%s”缺少 key", b.Name))
+		}
+	case "view":
+		if b.URL == "" {
+			problems = append(problems, fmt.Sprintf("“%s”缺少 url", b.Name))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("“%s”类型不支持：%q", b.Name, b.Type))
+	}
+	return problems
+}
+
+// DiffMenu 以逐行形式对比当前菜单与目标菜单：“- ”为将移除，“+ ”为将新增，“  ”为不变。
+// 两者一致时返回 nil。
+func DiffMenu(current, desired Menu) []string {
+	a, b := menuLines(current), menuLines(desired)
+
+	// 菜单最多 18 行，直接用 LCS 表求最小差异。
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	changed := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			changed = true
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return out
+}
+
+func menuLines(m Menu) []string {
+	var lines []string
+	for _, b := range m.Buttons {
+		if len(b.SubButtons) == 0 {
+			lines = append(lines, b.Name+" "+b.target())
+			continue
+		}
+		lines = append(lines, b.Name)
+		for _, sub := range b.SubButtons {
+			lines = append(lines, b.Name+" / "+sub.Name+" "+sub.target())
+		}
+	}
+	return lines
+}
+
+func (b MenuButton) target() string {
+	if b.Type == "view" {
+		return "[view " + b.URL + "]"
+	}
+	return "[" + b.Type + " " + b.Key + "]"
+}
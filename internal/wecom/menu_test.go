@@ -0,0 +1,69 @@
+package wecom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMenu_ValidateLimits(t *testing.T) {
+	t.Parallel()
+
+	ok := Menu{Buttons: []MenuButton{
+		{Name: "常用", SubButtons: []MenuButton{{Type: "click", Name: "帮助", Key: EventKeyCoreHelp}}},
+		{Type: "view", Name: "文档", URL: "https://example.com"},
+	}}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	subs := make([]MenuButton, 6)
+	for i := range subs {
+		subs[i] = MenuButton{Type: "click", Name: "按钮", Key: "k"}
+	}
+	bad := Menu{Buttons: []MenuButton{
+		{Name: "超长的一级菜单名称", SubButtons: subs},
+		{Type: "click", Name: "缺少key"},
+		{Type: "view", Name: "a", URL: "u"},
+		{Type: "view", Name: "b", URL: "u"},
+	}}
+	err := bad.Validate()
+	if err == nil {
+		t.Fatalf("Validate() error = nil, want error")
+	}
+	for _, want := range []string{"一级按钮最多 3 个", "名称过长", "子按钮最多 5 个", "缺少 key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("Validate() error = %q, want contains %q", err.Error(), want)
+		}
+	}
+}
+
+func TestDiffMenu(t *testing.T) {
+	t.Parallel()
+
+	current := Menu{Buttons: []MenuButton{
+		{Name: "常用", SubButtons: []MenuButton{
+			{Type: "click", Name: "操作菜单", Key: EventKeyCoreMenu},
+			{Type: "click", Name: "PVE", Key: EventKeyServiceSelectPrefix + "pve"},
+		}},
+	}}
+	if diff := DiffMenu(current, current); diff != nil {
+		t.Fatalf("DiffMenu(same) = %v, want nil", diff)
+	}
+
+	desired := Menu{Buttons: []MenuButton{
+		{Name: "常用", SubButtons: []MenuButton{
+			{Type: "click", Name: "操作菜单", Key: EventKeyCoreMenu},
+			{Type: "click", Name: "帮助", Key: EventKeyCoreHelp},
+		}},
+	}}
+	got := strings.Join(DiffMenu(current, desired), "\n")
+	want := strings.Join([]string{
+		"  常用",
+		"  常用 / 操作菜单 [click core.menu]",
+		"- 常用 / PVE [click svc.select.pve]",
+		"+ 常用 / 帮助 [click core.help]",
+	}, "\n")
+	if got != want {
+		t.Fatalf("DiffMenu() =\n%s\nwant\n%s", got, want)
+	}
+}
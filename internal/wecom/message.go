@@ -2,6 +2,7 @@
 package wecom
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -19,6 +20,24 @@ type IncomingMessage struct {
 	TaskId       string `xml:"TaskId"`
 	CardType     string `xml:"CardType"`
 	ResponseCode string `xml:"ResponseCode"`
+	// SelectedItems 为投票选择型/多项选择型卡片提交时的选择结果。
+	SelectedItems []SelectedItem `xml:"SelectedItems>SelectedItem"`
+}
+
+// SelectedItem 为卡片中一个问题（question_key）的选中选项。
+type SelectedItem struct {
+	QuestionKey string   `xml:"QuestionKey"`
+	OptionIDs   []string `xml:"OptionIds>OptionId"`
+}
+
+// SelectedOptions 返回指定问题的选中选项 id（未提交该问题时返回 nil）。
+func (m IncomingMessage) SelectedOptions(questionKey string) []string {
+	for _, item := range m.SelectedItems {
+		if strings.TrimSpace(item.QuestionKey) == questionKey {
+			return item.OptionIDs
+		}
+	}
+	return nil
 }
 
 const (
@@ -36,14 +55,37 @@ const (
 	EventKeyUnraidRestart         = "unraid.action.restart"
 	EventKeyUnraidStop            = "unraid.action.stop"
 	EventKeyUnraidForceUpdate     = "unraid.action.force_update"
+	EventKeyUnraidStart           = "unraid.action.start"
+	EventKeyUnraidPause           = "unraid.action.pause"
+	EventKeyUnraidUnpause         = "unraid.action.unpause"
+	EventKeyUnraidMenuOpsMore     = "unraid.menu.ops_more"
+	EventKeyUnraidUpdateCheck     = "unraid.action.update_check"
+	EventKeyUnraidUpdateAll       = "unraid.action.update_all"
+	EventKeyUnraidMenuGroups      = "unraid.menu.groups"
+	EventKeyUnraidAlertStatus     = "unraid.action.alert_status"
+	EventKeyUnraidAlertMute       = "unraid.action.alert_mute"
+	EventKeyUnraidAlertUnmute     = "unraid.action.alert_unmute"
 	EventKeyUnraidViewStatus      = "unraid.view.status"
 	EventKeyUnraidViewSystemStats = "unraid.view.system_stats"
 
 	EventKeyUnraidViewSystemStatsDetail = "unraid.view.system_stats_detail"
 	EventKeyUnraidViewLogs              = "unraid.view.logs"
+	EventKeyUnraidViewSyslog            = "unraid.view.syslog"
+	EventKeyUnraidViewStorage           = "unraid.view.storage"
+	EventKeyUnraidViewPlugins           = "unraid.view.plugins"
+	EventKeyUnraidViewTrend             = "unraid.view.trend"
+	EventKeyUnraidViewTop               = "unraid.view.top"
+	EventKeyUnraidFlashBackup           = "unraid.action.flash_backup"
+	EventKeyUnraidLogFileSelectPrefix   = "unraid.logfile.select."
+	EventKeyUnraidLogFileOlder          = "unraid.logfile.older"
+	EventKeyUnraidLogFileLatest         = "unraid.logfile.latest"
+	EventKeyUnraidLogFileFilter         = "unraid.logfile.filter"
 
 	EventKeyUnraidContainerSelectPrefix = "unraid.container.select."
 	EventKeyUnraidContainerPagePrefix   = "unraid.container.page."
+	EventKeyUnraidGroupSelectPrefix     = "unraid.group.select."
+	EventKeyUnraidGroupActionPrefix     = "unraid.group.action."
+	EventKeyUnraidUPSShutdownPlan       = "unraid.ups.shutdown_plan"
 
 	EventKeyQinglongMenu                 = "qinglong.menu"
 	EventKeyQinglongInstanceSelectPrefix = "qinglong.instance.select."
@@ -57,6 +99,23 @@ const (
 	EventKeyQinglongCronDisable          = "qinglong.cron.disable"
 	EventKeyQinglongCronLog              = "qinglong.cron.log"
 
+	EventKeyQinglongActionFiles        = "qinglong.action.files"
+	EventKeyQinglongFileScripts        = "qinglong.file.scripts"
+	EventKeyQinglongFileScriptSearch   = "qinglong.file.script_search"
+	EventKeyQinglongFileConfigs        = "qinglong.file.configs"
+	EventKeyQinglongFileSendFull       = "qinglong.file.send_full"
+	EventKeyQinglongScriptPagePrefix   = "qinglong.script.page."
+	EventKeyQinglongScriptSelectPrefix = "qinglong.script.select."
+	EventKeyQinglongConfigSelectPrefix = "qinglong.config.select."
+
+	EventKeyQinglongActionSystem       = "qinglong.action.system"
+	EventKeyQinglongDepListPrefix      = "qinglong.dep.list."
+	EventKeyQinglongDepInstallPrefix   = "qinglong.dep.install."
+	EventKeyQinglongDepReinstallPrefix = "qinglong.dep.reinstall."
+
+	EventKeyQinglongGlobalSearch    = "qinglong.global.search"
+	EventKeyQinglongGlobalRunPrefix = "qinglong.global.run."
+
 	EventKeyPVEMenu                 = "pve.menu"
 	EventKeyPVEInstanceSelectPrefix = "pve.instance.select."
 	EventKeyPVEGuestSelectPrefix    = "pve.guest.select."
@@ -83,76 +142,36 @@ const (
 	EventKeyCancel  = "core.action.cancel"
 )
 
-// Menu 定义企业微信“应用自定义菜单”的请求体。
-//
-// 官方文档（SSOT）：
-// - 创建菜单：https://developer.work.weixin.qq.com/document/path/90231
-// - 获取菜单：https://developer.work.weixin.qq.com/document/path/90232
-// - 删除菜单：https://developer.work.weixin.qq.com/document/path/90233
-type Menu struct {
-	Buttons []MenuButton `json:"button"`
-}
-
-// MenuButton 定义菜单按钮（最多 3 个一级按钮，每个最多 5 个二级按钮）。
-type MenuButton struct {
-	Type string `json:"type,omitempty"`
-	Name string `json:"name"`
-	Key  string `json:"key,omitempty"`
-	URL  string `json:"url,omitempty"`
-
-	SubButtons []MenuButton `json:"sub_button,omitempty"`
-}
-
-// DefaultMenu 返回本项目推荐的“应用自定义菜单”。
-// 该菜单以 CLICK 事件为主，EventKey 与回调路由约定保持一致。
-func DefaultMenu() Menu {
-	return Menu{
-		Buttons: []MenuButton{
-			{
-				Name: "常用",
-				SubButtons: []MenuButton{
-					{Type: "click", Name: "操作菜单", Key: EventKeyCoreMenu},
-					{Type: "click", Name: "PVE", Key: EventKeyServiceSelectPrefix + "pve"},
-					{Type: "click", Name: "自检", Key: EventKeyCoreSelfTest},
-					{Type: "click", Name: "帮助", Key: EventKeyCoreHelp},
-				},
-			},
-			{
-				Name: "Unraid",
-				SubButtons: []MenuButton{
-					{Type: "click", Name: "进入菜单", Key: EventKeyServiceSelectPrefix + "unraid"},
-					{Type: "click", Name: "容器操作", Key: EventKeyUnraidMenuOps},
-					{Type: "click", Name: "容器查看", Key: EventKeyUnraidMenuView},
-					{Type: "click", Name: "系统监控", Key: EventKeyUnraidMenuSystem},
-				},
-			},
-			{
-				Name: "青龙",
-				SubButtons: []MenuButton{
-					{Type: "click", Name: "进入青龙", Key: EventKeyServiceSelectPrefix + "qinglong"},
-					{Type: "click", Name: "动作菜单", Key: EventKeyQinglongMenu},
-				},
-			},
-		},
-	}
-}
-
+// TextMessage 为文本消息；ToUser/ToParty/ToTag 均以“|”分隔多个接收者，三者至少一个非空。
 type TextMessage struct {
 	ToUser  string
+	ToParty string
+	ToTag   string
 	Content string
 }
 
+// TemplateCardMessage 为模板卡片消息；接收者规则同 TextMessage。
 type TemplateCardMessage struct {
-	ToUser string
-	Card   TemplateCard
+	ToUser  string
+	ToParty string
+	ToTag   string
+	Card    TemplateCard
 }
 
 type TemplateCard map[string]interface{}
 
-// TemplateCardButton 用于“button_interaction”卡片的按钮信息抽取（供文本兜底与序号选择映射使用）。
+// TemplateCardButton 用于交互型卡片的按钮信息抽取（供文本兜底与序号选择映射使用）。
+// 投票/多项选择卡片的每个选项也映射为一项：Key 为提交按钮 key，QuestionKey/OptionID 标识选项。
 type TemplateCardButton struct {
 	Text string
 	Key  string
+
+	QuestionKey string
+	OptionID    string
+	// Multiple 表示该问题可多选（vote_interaction mode=1）。
+	Multiple bool
+	// Selected 表示该选项为默认选中（is_checked / selected_id），未回复该问题时沿用。
+	Selected bool
 }
 
 const defaultCardSourceDesc = "wecom-home-ops"
@@ -171,23 +190,27 @@ func applyDefaultSource(card TemplateCard) TemplateCard {
 	return card
 }
 
-// RenderButtonInteractionTextMenu 将 button_interaction 卡片渲染为“文本菜单”兜底，并返回按钮映射。
-// 返回 ok=false 表示当前卡片不是 button_interaction 或无法抽取有效按钮。
+// RenderButtonInteractionTextMenu 将交互型卡片渲染为“文本菜单”兜底，并返回按钮映射：
+// button_interaction 回复单个序号；vote_interaction / multiple_interaction 回复一个或多个序号（例如“1,3,5”）。
+// 返回 ok=false 表示当前卡片不是上述类型或无法抽取有效按钮/选项。
 func RenderButtonInteractionTextMenu(card TemplateCard) (text string, buttons []TemplateCardButton, ok bool) {
 	if card == nil {
 		return "", nil, false
 	}
 	cardType, _ := card["card_type"].(string)
-	if strings.ToLower(strings.TrimSpace(cardType)) != "button_interaction" {
+	switch strings.ToLower(strings.TrimSpace(cardType)) {
+	case "button_interaction":
+		buttons = extractButtonList(card)
+	case "vote_interaction", "multiple_interaction":
+		buttons = extractOptionList(card)
+	default:
 		return "", nil, false
 	}
-
-	title, desc := extractMainTitle(card)
-	buttons = extractButtonList(card)
 	if len(buttons) == 0 {
 		return "", nil, false
 	}
 
+	title, desc := extractMainTitle(card)
 	var b strings.Builder
 	if strings.TrimSpace(title) != "" {
 		b.WriteString(strings.TrimSpace(title))
@@ -201,18 +224,188 @@ func RenderButtonInteractionTextMenu(card TemplateCard) (text string, buttons []
 	if b.Len() > 0 {
 		b.WriteString("\n\n")
 	}
+
+	questions := 0
+	multiple := false
+	lastQuestion := ""
 	for i, btn := range buttons {
+		if btn.OptionID != "" && btn.QuestionKey != lastQuestion {
+			lastQuestion = btn.QuestionKey
+			questions++
+			if btn.Multiple {
+				multiple = true
+			}
+			if label := questionLabel(card, btn.QuestionKey); label != "" {
+				b.WriteString("【" + label + "】\n")
+			}
+		}
 		lineText := strings.TrimSpace(btn.Text)
 		if lineText == "" {
 			lineText = "按钮"
 		}
+		if btn.Selected {
+			lineText += "（默认）"
+		}
 		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, lineText))
 	}
-	b.WriteString("\n回复序号选择。")
+	switch {
+	case questions == 0:
+		b.WriteString("\n回复序号选择。")
+	case multiple:
+		b.WriteString("\n回复序号选择，可多选（例如“回复 1,3,5”）。")
+	case questions > 1:
+		b.WriteString("\n每组选择一项，回复序号（例如“回复 1,4”），未回复的组沿用默认选项。")
+	default:
+		b.WriteString("\n回复序号选择一项。")
+	}
 
 	return b.String(), buttons, true
 }
 
+// extractOptionList 抽取投票选择型（checkbox）或多项选择型（select_list）卡片的选项，Key 为提交按钮 key。
+func extractOptionList(card TemplateCard) []TemplateCardButton {
+	submit := asMap(card["submit_button"])
+	submitKey, _ := submit["key"].(string)
+	submitKey = strings.TrimSpace(submitKey)
+	if submitKey == "" {
+		return nil
+	}
+
+	var buttons []TemplateCardButton
+	if checkbox := asMap(card["checkbox"]); checkbox != nil {
+		questionKey, _ := checkbox["question_key"].(string)
+		multiple := toInt(checkbox["mode"]) == 1
+		for _, opt := range asMapList(checkbox["option_list"]) {
+			id, _ := opt["id"].(string)
+			text, _ := opt["text"].(string)
+			checked, _ := opt["is_checked"].(bool)
+			if strings.TrimSpace(id) == "" {
+				continue
+			}
+			buttons = append(buttons, TemplateCardButton{
+				Text:        strings.TrimSpace(text),
+				Key:         submitKey,
+				QuestionKey: questionKey,
+				OptionID:    id,
+				Multiple:    multiple,
+				Selected:    checked,
+			})
+		}
+	}
+	for _, sel := range asMapList(card["select_list"]) {
+		questionKey, _ := sel["question_key"].(string)
+		selectedID, _ := sel["selected_id"].(string)
+		for _, opt := range asMapList(sel["option_list"]) {
+			id, _ := opt["id"].(string)
+			text, _ := opt["text"].(string)
+			if strings.TrimSpace(id) == "" {
+				continue
+			}
+			buttons = append(buttons, TemplateCardButton{
+				Text:        strings.TrimSpace(text),
+				Key:         submitKey,
+				QuestionKey: questionKey,
+				OptionID:    id,
+				Selected:    id == selectedID,
+			})
+		}
+	}
+	return buttons
+}
+
+// questionLabel 返回多项选择型卡片中选择器的标签（投票卡片无标签）。
+func questionLabel(card TemplateCard, questionKey string) string {
+	for _, sel := range asMapList(card["select_list"]) {
+		if k, _ := sel["question_key"].(string); k == questionKey {
+			title, _ := sel["title"].(string)
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// SelectedItemsFromButtons 将文本兜底回复的序号（从 1 开始）转换为提交按钮 key 与选择结果。
+// 单选问题只能选择一项；未回复的问题沿用默认选中项。
+func SelectedItemsFromButtons(buttons []TemplateCardButton, indexes []int) (string, []SelectedItem, error) {
+	if len(indexes) == 0 {
+		return "", nil, errors.New("未选择任何选项")
+	}
+	chosen := make(map[string][]string)
+	var order []string
+	add := func(btn TemplateCardButton) {
+		if _, ok := chosen[btn.QuestionKey]; !ok {
+			order = append(order, btn.QuestionKey)
+		}
+		chosen[btn.QuestionKey] = append(chosen[btn.QuestionKey], btn.OptionID)
+	}
+
+	key := ""
+	for _, idx := range indexes {
+		if idx < 1 || idx > len(buttons) {
+			return "", nil, fmt.Errorf("序号超出范围：%d", idx)
+		}
+		btn := buttons[idx-1]
+		if btn.OptionID == "" {
+			return "", nil, errors.New("当前菜单不支持多选")
+		}
+		if !btn.Multiple && len(chosen[btn.QuestionKey]) > 0 {
+			return "", nil, fmt.Errorf("第 %d 项所在的组仅能选择一项", idx)
+		}
+		key = btn.Key
+		add(btn)
+	}
+	for _, btn := range buttons {
+		if _, ok := chosen[btn.QuestionKey]; !ok && btn.Selected && btn.OptionID != "" {
+			add(btn)
+		}
+	}
+
+	items := make([]SelectedItem, 0, len(order))
+	for _, q := range order {
+		items = append(items, SelectedItem{QuestionKey: q, OptionIDs: chosen[q]})
+	}
+	return key, items, nil
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m
+	case TemplateCard:
+		return map[string]interface{}(m)
+	default:
+		return nil
+	}
+}
+
+func asMapList(v interface{}) []map[string]interface{} {
+	switch list := v.(type) {
+	case []map[string]interface{}:
+		return list
+	case []interface{}:
+		var out []map[string]interface{}
+		for _, item := range list {
+			if m := asMap(item); m != nil {
+				out = append(out, m)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
 func extractMainTitle(card TemplateCard) (title, desc string) {
 	raw, ok := card["main_title"]
 	if !ok || raw == nil {
@@ -318,60 +511,329 @@ func NewServiceSelectCard(services []ServiceOption) TemplateCard {
 }
 
 func NewUnraidEntryCard() TemplateCard {
+	return NewUnraidEntryCardWithOptions(UnraidEntryCardOptions{})
+}
+
+type UnraidEntryCardOptions struct {
+	AlertDesc        string
+	ShowAlertActions bool
+	AlertMuted       bool
+}
+
+// NewUnraidEntryCardWithOptions 构建 Unraid 入口卡片；启用告警时追加“告警状态/静默告警（解除静默）”按钮。
+func NewUnraidEntryCardWithOptions(opts UnraidEntryCardOptions) TemplateCard {
+	desc := "请选择菜单"
+	if strings.TrimSpace(opts.AlertDesc) != "" {
+		desc = strings.TrimSpace(opts.AlertDesc)
+	}
+
+	buttons := []map[string]interface{}{
+		{
+			"text":  "容器操作",
+			"style": 1,
+			"key":   EventKeyUnraidMenuOps,
+		},
+		{
+			"text":  "容器查看",
+			"style": 2,
+			"key":   EventKeyUnraidMenuView,
+		},
+		{
+			"text":  "系统监控",
+			"style": 2,
+			"key":   EventKeyUnraidMenuSystem,
+		},
+	}
+	if opts.ShowAlertActions {
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "告警状态",
+			"style": 2,
+			"key":   EventKeyUnraidAlertStatus,
+		})
+		if opts.AlertMuted {
+			buttons = append(buttons, map[string]interface{}{
+				"text":  "解除静默",
+				"style": 2,
+				"key":   EventKeyUnraidAlertUnmute,
+			})
+		} else {
+			buttons = append(buttons, map[string]interface{}{
+				"text":  "静默告警",
+				"style": 2,
+				"key":   EventKeyUnraidAlertMute,
+			})
+		}
+	}
+
 	card := TemplateCard{
 		"card_type": "button_interaction",
 		"main_title": map[string]interface{}{
 			"title": "Unraid 容器",
-			"desc":  "请选择菜单",
+			"desc":  desc,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+func NewUnraidOpsCard() TemplateCard {
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "Unraid 容器操作",
+			"desc":  "请选择动作",
 		},
 		"button_list": []map[string]interface{}{
 			{
-				"text":  "容器操作",
+				"text":  "启动容器",
 				"style": 1,
-				"key":   EventKeyUnraidMenuOps,
+				"key":   EventKeyUnraidStart,
+			},
+			{
+				"text":  "重启容器",
+				"style": 1,
+				"key":   EventKeyUnraidRestart,
 			},
 			{
-				"text":  "容器查看",
+				"text":  "停止容器",
 				"style": 2,
-				"key":   EventKeyUnraidMenuView,
+				"key":   EventKeyUnraidStop,
 			},
 			{
-				"text":  "系统监控",
+				"text":  "强制更新",
 				"style": 2,
-				"key":   EventKeyUnraidMenuSystem,
+				"key":   EventKeyUnraidForceUpdate,
+			},
+			{
+				"text":  "更多操作",
+				"style": 2,
+				"key":   EventKeyUnraidMenuOpsMore,
+			},
+			{
+				"text":  "返回菜单",
+				"style": 1,
+				"key":   EventKeyUnraidBackToMenu,
 			},
 		},
 	}
 	return applyDefaultSource(card)
 }
 
-func NewUnraidOpsCard() TemplateCard {
+// NewUnraidOpsMoreCard 为“容器操作”的二级菜单（按钮数量受限，低频操作放在这里）。
+func NewUnraidOpsMoreCard() TemplateCard {
 	card := TemplateCard{
 		"card_type": "button_interaction",
 		"main_title": map[string]interface{}{
-			"title": "Unraid 容器操作",
+			"title": "Unraid 更多操作",
 			"desc":  "请选择动作",
 		},
 		"button_list": []map[string]interface{}{
 			{
-				"text":  "重启容器",
+				"text":  "暂停容器",
+				"style": 2,
+				"key":   EventKeyUnraidPause,
+			},
+			{
+				"text":  "恢复容器",
 				"style": 1,
-				"key":   EventKeyUnraidRestart,
+				"key":   EventKeyUnraidUnpause,
 			},
 			{
-				"text":  "停止容器",
+				"text":  "可更新容器",
+				"style": 1,
+				"key":   EventKeyUnraidUpdateCheck,
+			},
+			{
+				"text":  "容器组",
+				"style": 1,
+				"key":   EventKeyUnraidMenuGroups,
+			},
+			{
+				"text":  "返回",
 				"style": 2,
-				"key":   EventKeyUnraidStop,
+				"key":   EventKeyUnraidMenuOps,
+			},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// NewUnraidUpdatableCard 展示可更新容器：前若干个容器提供单独“更新”按钮，另附“全部更新”。
+func NewUnraidUpdatableCard(total int, containers []UnraidContainerOption) TemplateCard {
+	const maxContainerButtons = 4
+
+	var buttons []map[string]interface{}
+	for _, c := range containers {
+		if len(buttons) >= maxContainerButtons {
+			break
+		}
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			continue
+		}
+		text := strings.TrimSpace(c.Text)
+		if text == "" {
+			text = name
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "更新 " + text,
+			"style": 1,
+			"key":   EventKeyUnraidContainerSelectPrefix + name,
+		})
+	}
+	buttons = append(buttons,
+		map[string]interface{}{
+			"text":  "全部更新",
+			"style": 1,
+			"key":   EventKeyUnraidUpdateAll,
+		},
+		map[string]interface{}{
+			"text":  "返回",
+			"style": 2,
+			"key":   EventKeyUnraidMenuOpsMore,
+		},
+	)
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "可更新容器",
+			"desc":  fmt.Sprintf("共 %d 个", total),
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+type UnraidGroupOption struct {
+	ID   string
+	Text string
+}
+
+// NewUnraidGroupListCard 展示已配置的容器组（最多 5 个）。
+func NewUnraidGroupListCard(groups []UnraidGroupOption) TemplateCard {
+	const maxGroupButtons = 5
+
+	var buttons []map[string]interface{}
+	for _, g := range groups {
+		if len(buttons) >= maxGroupButtons {
+			break
+		}
+		id := strings.TrimSpace(g.ID)
+		if id == "" {
+			continue
+		}
+		text := strings.TrimSpace(g.Text)
+		if text == "" {
+			text = id
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  text,
+			"style": 1,
+			"key":   EventKeyUnraidGroupSelectPrefix + id,
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "返回",
+		"style": 2,
+		"key":   EventKeyUnraidMenuOpsMore,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "容器组",
+			"desc":  "请选择容器组",
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+// NewUnraidGroupActionCard 为容器组的批量动作菜单（按钮 key 后缀与 core.Action 取值一致）。
+func NewUnraidGroupActionCard(name string, count int) TemplateCard {
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "容器组：" + name,
+			"desc":  fmt.Sprintf("共 %d 个容器，请选择动作", count),
+		},
+		"button_list": []map[string]interface{}{
+			{
+				"text":  "启动",
+				"style": 1,
+				"key":   EventKeyUnraidGroupActionPrefix + "start",
 			},
 			{
-				"text":  "强制更新",
+				"text":  "重启",
+				"style": 1,
+				"key":   EventKeyUnraidGroupActionPrefix + "restart",
+			},
+			{
+				"text":  "停止",
 				"style": 2,
-				"key":   EventKeyUnraidForceUpdate,
+				"key":   EventKeyUnraidGroupActionPrefix + "stop",
 			},
 			{
-				"text":  "返回菜单",
+				"text":  "更新",
+				"style": 2,
+				"key":   EventKeyUnraidGroupActionPrefix + "force_update",
+			},
+			{
+				"text":  "返回",
+				"style": 2,
+				"key":   EventKeyUnraidMenuGroups,
+			},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// NewUnraidPluginsCard 为“插件与备份”的操作卡片（闪存备份需二次确认）。
+func NewUnraidPluginsCard(desc string) TemplateCard {
+	if strings.TrimSpace(desc) == "" {
+		desc = "升级前建议先做一次闪存备份"
+	}
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "Unraid 插件与备份",
+			"desc":  desc,
+		},
+		"button_list": []map[string]interface{}{
+			{
+				"text":  "闪存备份",
 				"style": 1,
-				"key":   EventKeyUnraidBackToMenu,
+				"key":   EventKeyUnraidFlashBackup,
+			},
+			{
+				"text":  "返回",
+				"style": 2,
+				"key":   EventKeyUnraidMenuSystem,
+			},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// NewUnraidUPSShutdownPlanCard 为 UPS 电源告警附带的“关机预案”入口（点击后仍需二次确认）。
+func NewUnraidUPSShutdownPlanCard(desc string) TemplateCard {
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "UPS 电源告警",
+			"desc":  desc,
+		},
+		"button_list": []map[string]interface{}{
+			{
+				"text":  "执行关机预案",
+				"style": 2,
+				"key":   EventKeyUnraidUPSShutdownPlan,
+			},
+			{
+				"text":  "忽略",
+				"style": 1,
+				"key":   EventKeyCancel,
 			},
 		},
 	}
@@ -422,20 +884,109 @@ func NewUnraidContainerSelectCard(actionDisplayName string, page int, totalPages
 		buttons = append(buttons, map[string]interface{}{
 			"text":  "下一页",
 			"style": 2,
-			"key":   EventKeyUnraidContainerPagePrefix + intToString(nextPage),
-		})
-	}
-	buttons = append(buttons, map[string]interface{}{
-		"text":  "动作菜单",
-		"style": 2,
-		"key":   EventKeyUnraidMenuOps,
-	})
+			"key":   EventKeyUnraidContainerPagePrefix + intToString(nextPage),
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "动作菜单",
+		"style": 2,
+		"key":   EventKeyUnraidMenuOps,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "选择容器",
+			"desc":  desc,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+type UnraidLogFileOption struct {
+	Path string
+	Text string
+}
+
+// NewUnraidLogFileSelectCard 展示可查看的系统日志文件（按钮数量受限，最多展示 5 个）。
+func NewUnraidLogFileSelectCard(total int, files []UnraidLogFileOption) TemplateCard {
+	const maxFileButtons = 5
+
+	var buttons []map[string]interface{}
+	for _, f := range files {
+		if len(buttons) >= maxFileButtons {
+			break
+		}
+		path := strings.TrimSpace(f.Path)
+		if path == "" {
+			continue
+		}
+		text := strings.TrimSpace(f.Text)
+		if text == "" {
+			text = path
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  text,
+			"style": 1,
+			"key":   EventKeyUnraidLogFileSelectPrefix + path,
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "返回",
+		"style": 2,
+		"key":   EventKeyUnraidMenuSystem,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "系统日志",
+			"desc":  fmt.Sprintf("共 %d 个日志文件，请选择", total),
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+// NewUnraidLogFileViewCard 为日志查看的后续操作：向前翻页、回到最新、关键词过滤。
+func NewUnraidLogFileViewCard(name string, hasOlder bool) TemplateCard {
+	var buttons []map[string]interface{}
+	if hasOlder {
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "更早",
+			"style": 1,
+			"key":   EventKeyUnraidLogFileOlder,
+		})
+	}
+	buttons = append(buttons,
+		map[string]interface{}{
+			"text":  "最新",
+			"style": 1,
+			"key":   EventKeyUnraidLogFileLatest,
+		},
+		map[string]interface{}{
+			"text":  "关键词过滤",
+			"style": 2,
+			"key":   EventKeyUnraidLogFileFilter,
+		},
+		map[string]interface{}{
+			"text":  "日志文件",
+			"style": 2,
+			"key":   EventKeyUnraidViewSyslog,
+		},
+		map[string]interface{}{
+			"text":  "返回菜单",
+			"style": 2,
+			"key":   EventKeyUnraidBackToMenu,
+		},
+	)
 
 	card := TemplateCard{
 		"card_type": "button_interaction",
 		"main_title": map[string]interface{}{
-			"title": "选择容器",
-			"desc":  desc,
+			"title": "日志：" + name,
+			"desc":  "请选择后续操作",
 		},
 		"button_list": buttons,
 	}
@@ -460,6 +1011,16 @@ func NewUnraidViewCard() TemplateCard {
 				"style": 2,
 				"key":   EventKeyUnraidViewLogs,
 			},
+			{
+				"text":  "资源趋势",
+				"style": 2,
+				"key":   EventKeyUnraidViewTrend,
+			},
+			{
+				"text":  "资源排行",
+				"style": 2,
+				"key":   EventKeyUnraidViewTop,
+			},
 			{
 				"text":  "系统监控",
 				"style": 1,
@@ -493,6 +1054,21 @@ func NewUnraidSystemCard() TemplateCard {
 				"style": 2,
 				"key":   EventKeyUnraidViewSystemStatsDetail,
 			},
+			{
+				"text":  "存储空间",
+				"style": 1,
+				"key":   EventKeyUnraidViewStorage,
+			},
+			{
+				"text":  "系统日志",
+				"style": 2,
+				"key":   EventKeyUnraidViewSyslog,
+			},
+			{
+				"text":  "插件与备份",
+				"style": 2,
+				"key":   EventKeyUnraidViewPlugins,
+			},
 			{
 				"text":  "返回菜单",
 				"style": 1,
@@ -520,6 +1096,13 @@ func NewQinglongInstanceSelectCard(instances []QinglongInstanceOption) TemplateC
 			"key":   EventKeyQinglongInstanceSelectPrefix + ins.ID,
 		})
 	}
+	if len(buttons) > 1 {
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "跨实例搜索",
+			"style": 2,
+			"key":   EventKeyQinglongGlobalSearch,
+		})
+	}
 
 	card := TemplateCard{
 		"card_type": "button_interaction",
@@ -559,6 +1142,16 @@ func NewQinglongActionCard(instanceName string) TemplateCard {
 				"style": 2,
 				"key":   EventKeyQinglongActionByID,
 			},
+			{
+				"text":  "文件查看",
+				"style": 2,
+				"key":   EventKeyQinglongActionFiles,
+			},
+			{
+				"text":  "系统",
+				"style": 2,
+				"key":   EventKeyQinglongActionSystem,
+			},
 			{
 				"text":  "切换实例",
 				"style": 2,
@@ -569,6 +1162,247 @@ func NewQinglongActionCard(instanceName string) TemplateCard {
 	return applyDefaultSource(card)
 }
 
+func NewQinglongFileMenuCard(instanceName string) TemplateCard {
+	desc := "请选择文件类型"
+	if instanceName != "" {
+		desc = "实例：" + instanceName
+	}
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "青龙(QL) 文件查看",
+			"desc":  desc,
+		},
+		"button_list": []map[string]interface{}{
+			{"text": "脚本文件", "style": 1, "key": EventKeyQinglongFileScripts},
+			{"text": "搜索脚本", "style": 1, "key": EventKeyQinglongFileScriptSearch},
+			{"text": "配置文件", "style": 1, "key": EventKeyQinglongFileConfigs},
+			{"text": "返回", "style": 2, "key": EventKeyQinglongMenu},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// QinglongFileOption 为脚本/配置文件选择按钮；Key 为选择后回传的文件标识（脚本为相对路径，配置为文件名）。
+type QinglongFileOption struct {
+	Key  string
+	Text string
+}
+
+func NewQinglongScriptListCard(title, instanceName string, page int, totalPages int, files []QinglongFileOption, prevPage int, nextPage int) TemplateCard {
+	desc := "请选择脚本"
+	if instanceName != "" {
+		desc = "实例：" + instanceName
+	}
+	if page > 0 && totalPages > 0 {
+		desc = fmt.Sprintf("%s | %d/%d", desc, page, totalPages)
+	}
+
+	var buttons []map[string]interface{}
+	for _, f := range files {
+		key := strings.TrimSpace(f.Key)
+		if key == "" {
+			continue
+		}
+		text := strings.TrimSpace(f.Text)
+		if text == "" {
+			text = key
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  text,
+			"style": 1,
+			"key":   EventKeyQinglongScriptSelectPrefix + key,
+		})
+	}
+	if prevPage > 0 {
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "上一页",
+			"style": 2,
+			"key":   EventKeyQinglongScriptPagePrefix + intToString(prevPage),
+		})
+	}
+	if nextPage > 0 {
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "下一页",
+			"style": 2,
+			"key":   EventKeyQinglongScriptPagePrefix + intToString(nextPage),
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "返回",
+		"style": 2,
+		"key":   EventKeyQinglongActionFiles,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": title,
+			"desc":  desc,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+func NewQinglongConfigListCard(instanceName string, files []QinglongFileOption) TemplateCard {
+	desc := "请选择配置文件"
+	if instanceName != "" {
+		desc = "实例：" + instanceName
+	}
+
+	var buttons []map[string]interface{}
+	for _, f := range files {
+		key := strings.TrimSpace(f.Key)
+		if key == "" {
+			continue
+		}
+		text := strings.TrimSpace(f.Text)
+		if text == "" {
+			text = key
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  text,
+			"style": 1,
+			"key":   EventKeyQinglongConfigSelectPrefix + key,
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "返回",
+		"style": 2,
+		"key":   EventKeyQinglongActionFiles,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "配置文件",
+			"desc":  desc,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+func NewQinglongFileViewCard(instanceName string, fileName string) TemplateCard {
+	desc := fileName
+	if instanceName != "" {
+		desc = "实例：" + instanceName + " | " + fileName
+	}
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "文件内容",
+			"desc":  desc,
+		},
+		"button_list": []map[string]interface{}{
+			{"text": "发送完整文件", "style": 1, "key": EventKeyQinglongFileSendFull},
+			{"text": "返回", "style": 2, "key": EventKeyQinglongActionFiles},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+func NewQinglongSystemCard(instanceName string) TemplateCard {
+	desc := "请选择依赖类型"
+	if instanceName != "" {
+		desc = "实例：" + instanceName
+	}
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "青龙(QL) 系统",
+			"desc":  desc,
+		},
+		"button_list": []map[string]interface{}{
+			{"text": "NodeJs依赖", "style": 1, "key": EventKeyQinglongDepListPrefix + "nodejs"},
+			{"text": "Python3依赖", "style": 1, "key": EventKeyQinglongDepListPrefix + "python3"},
+			{"text": "Linux依赖", "style": 1, "key": EventKeyQinglongDepListPrefix + "linux"},
+			{"text": "返回", "style": 2, "key": EventKeyQinglongMenu},
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// QinglongDependencyOption 为依赖重装按钮（通常仅展示安装失败的依赖）。
+type QinglongDependencyOption struct {
+	ID   int
+	Name string
+}
+
+func NewQinglongDependencyCard(instanceName string, depType string, reinstall []QinglongDependencyOption) TemplateCard {
+	desc := "依赖类型：" + depType
+	if instanceName != "" {
+		desc = "实例：" + instanceName + " | " + depType
+	}
+
+	var buttons []map[string]interface{}
+	for _, d := range reinstall {
+		if d.ID <= 0 {
+			continue
+		}
+		if len(buttons) >= 4 {
+			break
+		}
+		text := d.Name
+		if text == "" {
+			text = "依赖"
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "重装 " + text,
+			"style": 1,
+			"key":   EventKeyQinglongDepReinstallPrefix + intToString(d.ID),
+		})
+	}
+	buttons = append(buttons,
+		map[string]interface{}{"text": "安装依赖", "style": 1, "key": EventKeyQinglongDepInstallPrefix + depType},
+		map[string]interface{}{"text": "返回", "style": 2, "key": EventKeyQinglongActionSystem},
+	)
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "依赖管理",
+			"desc":  desc,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
+// NewQinglongGlobalRunCard 为跨实例搜索结果提供“全部实例运行同名任务”的入口（最多 5 个任务名）。
+func NewQinglongGlobalRunCard(keyword string, cronNames []string) TemplateCard {
+	var buttons []map[string]interface{}
+	for _, name := range cronNames {
+		if name == "" {
+			continue
+		}
+		if len(buttons) >= 5 {
+			break
+		}
+		buttons = append(buttons, map[string]interface{}{
+			"text":  "全部运行 " + name,
+			"style": 1,
+			"key":   EventKeyQinglongGlobalRunPrefix + name,
+		})
+	}
+	buttons = append(buttons, map[string]interface{}{
+		"text":  "返回",
+		"style": 2,
+		"key":   EventKeyQinglongMenu,
+	})
+
+	card := TemplateCard{
+		"card_type": "button_interaction",
+		"main_title": map[string]interface{}{
+			"title": "跨实例搜索",
+			"desc":  "关键词：" + keyword,
+		},
+		"button_list": buttons,
+	}
+	return applyDefaultSource(card)
+}
+
 type QinglongCronOption struct {
 	ID   int
 	Name string
@@ -885,6 +1719,144 @@ func NewConfirmCard(actionDisplayName, target string) TemplateCard {
 	return applyDefaultSource(card)
 }
 
+// CardOption 为投票选择型/多项选择型卡片的一个选项。
+type CardOption struct {
+	ID      string
+	Text    string
+	Checked bool
+}
+
+// VoteCardOptions 定义投票选择型卡片（vote_interaction）：一个问题，复选框展示选项（最多 20 个）。
+type VoteCardOptions struct {
+	Title       string
+	Desc        string
+	QuestionKey string
+	Options     []CardOption
+	// Multiple 为 true 时可多选（mode=1），否则单选。
+	Multiple   bool
+	SubmitText string
+	SubmitKey  string
+}
+
+// NewVoteInteractionCard 构造投票选择型卡片；提交后回调 template_card_event，EventKey 为 SubmitKey，选择结果见 SelectedItems。
+//
+// 官方文档（SSOT）：模板卡片消息 - 投票选择型
+// https://developer.work.weixin.qq.com/document/path/90236
+func NewVoteInteractionCard(opts VoteCardOptions) TemplateCard {
+	options := opts.Options
+	if len(options) > 20 {
+		options = options[:20]
+	}
+	optionList := make([]map[string]interface{}, 0, len(options))
+	for _, o := range options {
+		optionList = append(optionList, map[string]interface{}{
+			"id":         o.ID,
+			"text":       o.Text,
+			"is_checked": o.Checked,
+		})
+	}
+	mode := 0
+	if opts.Multiple {
+		mode = 1
+	}
+	submitText := opts.SubmitText
+	if strings.TrimSpace(submitText) == "" {
+		submitText = "提交"
+	}
+
+	card := TemplateCard{
+		"card_type": "vote_interaction",
+		"main_title": map[string]interface{}{
+			"title": opts.Title,
+			"desc":  opts.Desc,
+		},
+		"checkbox": map[string]interface{}{
+			"question_key": opts.QuestionKey,
+			"option_list":  optionList,
+			"mode":         mode,
+		},
+		"submit_button": map[string]interface{}{
+			"text": submitText,
+			"key":  opts.SubmitKey,
+		},
+	}
+	return applyDefaultSource(card)
+}
+
+// CardSelect 为多项选择型卡片的一个下拉选择器（最多 10 个选项）。
+type CardSelect struct {
+	QuestionKey string
+	Title       string
+	// SelectedID 为默认选中的选项 id（为空时默认第一项）。
+	SelectedID string
+	Options    []CardOption
+}
+
+// MultipleCardOptions 定义多项选择型卡片（multiple_interaction）：最多 3 个下拉选择器，每个单选。
+type MultipleCardOptions struct {
+	Title      string
+	Desc       string
+	Selects    []CardSelect
+	SubmitText string
+	SubmitKey  string
+}
+
+// NewMultipleInteractionCard 构造多项选择型卡片；提交后回调 template_card_event，EventKey 为 SubmitKey，选择结果见 SelectedItems。
+//
+// 官方文档（SSOT）：模板卡片消息 - 多项选择型
+// https://developer.work.weixin.qq.com/document/path/90236
+func NewMultipleInteractionCard(opts MultipleCardOptions) TemplateCard {
+	selects := opts.Selects
+	if len(selects) > 3 {
+		selects = selects[:3]
+	}
+	selectList := make([]map[string]interface{}, 0, len(selects))
+	for _, sel := range selects {
+		options := sel.Options
+		if len(options) > 10 {
+			options = options[:10]
+		}
+		optionList := make([]map[string]interface{}, 0, len(options))
+		selectedID := sel.SelectedID
+		for _, o := range options {
+			optionList = append(optionList, map[string]interface{}{
+				"id":   o.ID,
+				"text": o.Text,
+			})
+			if selectedID == "" && o.Checked {
+				selectedID = o.ID
+			}
+		}
+		if selectedID == "" && len(options) > 0 {
+			selectedID = options[0].ID
+		}
+		selectList = append(selectList, map[string]interface{}{
+			"question_key": sel.QuestionKey,
+			"title":        sel.Title,
+			"selected_id":  selectedID,
+			"option_list":  optionList,
+		})
+	}
+	submitText := opts.SubmitText
+	if strings.TrimSpace(submitText) == "" {
+		submitText = "提交"
+	}
+
+	card := TemplateCard{
+		"card_type": "multiple_interaction",
+		"main_title": map[string]interface{}{
+			"title": opts.Title,
+			"desc":  opts.Desc,
+		},
+		"select_list": selectList,
+		"submit_button": map[string]interface{}{
+			"text": submitText,
+			"key":  opts.SubmitKey,
+		},
+	}
+	return applyDefaultSource(card)
+}
+
 func intToString(v int) string {
 	if v == 0 {
 		return "0"
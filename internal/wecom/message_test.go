@@ -0,0 +1,80 @@
+package wecom
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestIncomingMessage_ParsesSelectedItems(t *testing.T) {
+	t.Parallel()
+
+	plain := `<xml>
+<FromUserName><![CDATA[u]]></FromUserName>
+<MsgType><![CDATA[event]]></MsgType>
+<Event><![CDATA[template_card_event]]></Event>
+<EventKey><![CDATA[submit]]></EventKey>
+<CardType><![CDATA[vote_interaction]]></CardType>
+<SelectedItems>
+  <SelectedItem>
+    <QuestionKey><![CDATA[q1]]></QuestionKey>
+    <OptionIds><OptionId><![CDATA[a]]></OptionId><OptionId><![CDATA[c]]></OptionId></OptionIds>
+  </SelectedItem>
+  <SelectedItem>
+    <QuestionKey><![CDATA[q2]]></QuestionKey>
+    <OptionIds><OptionId><![CDATA[x]]></OptionId></OptionIds>
+  </SelectedItem>
+</SelectedItems>
+</xml>`
+	var msg IncomingMessage
+	if err := xml.Unmarshal([]byte(plain), &msg); err != nil {
+		t.Fatalf("xml.Unmarshal() error: %v", err)
+	}
+	if got := msg.SelectedOptions("q1"); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("SelectedOptions(q1) = %v", got)
+	}
+	if got := msg.SelectedOptions("q2"); len(got) != 1 || got[0] != "x" {
+		t.Fatalf("SelectedOptions(q2) = %v", got)
+	}
+	if got := msg.SelectedOptions("missing"); got != nil {
+		t.Fatalf("SelectedOptions(missing) = %v, want nil", got)
+	}
+}
+
+func TestRenderTextMenu_MultipleInteractionDefaultsAndSingleChoice(t *testing.T) {
+	t.Parallel()
+
+	card := NewMultipleInteractionCard(MultipleCardOptions{
+		Title: "批量操作",
+		Selects: []CardSelect{
+			{QuestionKey: "action", Title: "操作", Options: []CardOption{{ID: "restart", Text: "重启"}, {ID: "stop", Text: "停止", Checked: true}}},
+			{QuestionKey: "scope", Title: "范围", Options: []CardOption{{ID: "all", Text: "全部"}, {ID: "running", Text: "运行中"}}},
+		},
+		SubmitKey: "batch.submit",
+	})
+	sel, _ := card["select_list"].([]map[string]interface{})
+	if len(sel) != 2 || sel[0]["selected_id"] != "stop" || sel[1]["selected_id"] != "all" {
+		t.Fatalf("select_list = %v", card["select_list"])
+	}
+
+	text, buttons, ok := RenderButtonInteractionTextMenu(card)
+	if !ok || len(buttons) != 4 {
+		t.Fatalf("RenderButtonInteractionTextMenu() ok=%v buttons=%v", ok, buttons)
+	}
+	for _, want := range []string{"【操作】", "【范围】", "2. 停止（默认）", "4. 运行中", "每组选择一项"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text = %q, want contains %q", text, want)
+		}
+	}
+
+	key, items, err := SelectedItemsFromButtons(buttons, []int{4})
+	if err != nil || key != "batch.submit" {
+		t.Fatalf("SelectedItemsFromButtons([4]) = %q, %v", key, err)
+	}
+	if len(items) != 2 || items[0].QuestionKey != "scope" || items[0].OptionIDs[0] != "running" || items[1].OptionIDs[0] != "stop" {
+		t.Fatalf("items = %+v, want scope=running and default action=stop", items)
+	}
+	if _, _, err := SelectedItemsFromButtons(buttons, []int{1, 2}); err == nil {
+		t.Fatalf("SelectedItemsFromButtons([1 2]) error = nil, want single-choice error")
+	}
+}
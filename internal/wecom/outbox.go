@@ -0,0 +1,451 @@
+package wecom
+
+// outbox.go 提供出站消息队列：按成员/全局限流、合并相同的待发消息、失败按计划重试，
+// 并可选落盘以便重启后继续投递未发送的告警。
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboxSender 为出站队列的底层发送端（通常为 *Client）。
+type OutboxSender interface {
+	SendText(ctx context.Context, msg TextMessage) error
+	SendTemplateCard(ctx context.Context, msg TemplateCardMessage) error
+	SendMarkdown(ctx context.Context, msg MarkdownMessage) error
+}
+
+// OutboxConfig 定义出站队列参数；零值字段使用默认。
+//
+// 官方限制（SSOT：https://developer.work.weixin.qq.com/document/path/90236）：
+// 每应用对同一成员不可超过 30 次/分钟，超出部分会被丢弃不下发。
+type OutboxConfig struct {
+	// PerUserPerMinute 为单个成员每分钟最多发送条数（默认 20）。
+	PerUserPerMinute int
+	// GlobalPerMinute 为全部成员合计每分钟最多发送条数（默认 600）。
+	GlobalPerMinute int
+	// RetrySchedule 为失败后的重试间隔（默认 30s/2m/10m），用尽后丢弃并记录日志。
+	RetrySchedule []time.Duration
+	// MaxPending 为待发送消息上限（默认 500），超出时拒绝入队。
+	MaxPending int
+	// SpoolPath 非空时将待发送消息落盘（JSON），启动时加载继续投递。
+	SpoolPath string
+}
+
+func (c OutboxConfig) withDefaults() OutboxConfig {
+	if c.PerUserPerMinute <= 0 {
+		c.PerUserPerMinute = 20
+	}
+	if c.GlobalPerMinute <= 0 {
+		c.GlobalPerMinute = 600
+	}
+	if len(c.RetrySchedule) == 0 {
+		c.RetrySchedule = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute}
+	}
+	if c.MaxPending <= 0 {
+		c.MaxPending = 500
+	}
+	return c
+}
+
+// ErrOutboxFull 表示待发送队列已满。
+var ErrOutboxFull = errors.New("wecom outbox: 待发送队列已满")
+
+const (
+	outboxKindText         = "text"
+	outboxKindMarkdown     = "markdown"
+	outboxKindTemplateCard = "template_card"
+)
+
+// outboxTick 为队列轮询间隔（被限流的消息在下一次轮询时重新尝试）。
+const outboxTick = time.Second
+
+type outboxItem struct {
+	Kind      string       `json:"kind"`
+	ToUser    string       `json:"to_user"`
+	ToParty   string       `json:"to_party,omitempty"`
+	ToTag     string       `json:"to_tag,omitempty"`
+	Content   string       `json:"content,omitempty"`
+	Card      TemplateCard `json:"card,omitempty"`
+	Key       string       `json:"key"`
+	Attempts  int          `json:"attempts"`
+	Merged    int          `json:"merged,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	NextAt    time.Time    `json:"next_at"`
+
+	sending bool
+}
+
+// Outbox 为异步出站队列：Send* 仅入队（队列满时返回 ErrOutboxFull），由后台协程按限流投递。
+// 适用于告警等非交互消息；交互回复请直接使用 Client 以便即时返回错误。
+type Outbox struct {
+	base OutboxSender
+	cfg  OutboxConfig
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending []*outboxItem
+	global  *tokenBucket
+	users   map[string]*tokenBucket
+
+	persistMu sync.Mutex
+
+	wakeCh chan struct{}
+	stopCh chan struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewOutbox(base OutboxSender, cfg OutboxConfig) *Outbox {
+	cfg = cfg.withDefaults()
+	o := &Outbox{
+		base:   base,
+		cfg:    cfg,
+		now:    time.Now,
+		users:  make(map[string]*tokenBucket),
+		wakeCh: make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+	}
+	o.global = newTokenBucket(cfg.GlobalPerMinute, o.now())
+	return o
+}
+
+func (o *Outbox) SendText(_ context.Context, msg TextMessage) error {
+	return o.enqueue(&outboxItem{Kind: outboxKindText, ToUser: msg.ToUser, ToParty: msg.ToParty, ToTag: msg.ToTag, Content: msg.Content})
+}
+
+func (o *Outbox) SendMarkdown(_ context.Context, msg MarkdownMessage) error {
+	return o.enqueue(&outboxItem{Kind: outboxKindMarkdown, ToUser: msg.ToUser, Content: msg.Content})
+}
+
+func (o *Outbox) SendTemplateCard(_ context.Context, msg TemplateCardMessage) error {
+	return o.enqueue(&outboxItem{Kind: outboxKindTemplateCard, ToUser: msg.ToUser, ToParty: msg.ToParty, ToTag: msg.ToTag, Card: msg.Card})
+}
+
+// Start 加载落盘队列并启动后台投递。
+func (o *Outbox) Start() {
+	if o == nil || o.base == nil {
+		return
+	}
+	o.startOnce.Do(func() {
+		o.load()
+		go o.loop()
+	})
+}
+
+// Close 停止后台投递；未发送的消息保留在落盘文件中（如已配置）。
+func (o *Outbox) Close() {
+	if o == nil {
+		return
+	}
+	o.stopOnce.Do(func() { close(o.stopCh) })
+}
+
+// Pending 返回待发送消息数量。
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+func (o *Outbox) loop() {
+	ticker := time.NewTicker(outboxTick)
+	defer ticker.Stop()
+
+	for {
+		o.drain(context.Background())
+		select {
+		case <-o.stopCh:
+			return
+		case <-ticker.C:
+		case <-o.wakeCh:
+		}
+	}
+}
+
+func (o *Outbox) enqueue(item *outboxItem) error {
+	key, err := outboxKey(item)
+	if err != nil {
+		return err
+	}
+	item.Key = key
+
+	o.mu.Lock()
+	for _, p := range o.pending {
+		if p.Key == key && !p.sending {
+			p.Merged++
+			o.mu.Unlock()
+			slog.Info("wecom outbox 合并相同消息", "kind", item.Kind, "to_user", item.ToUser, "merged", p.Merged)
+			return nil
+		}
+	}
+	if len(o.pending) >= o.cfg.MaxPending {
+		o.mu.Unlock()
+		slog.Error("wecom outbox 队列已满，消息被拒绝", "kind", item.Kind, "to_user", item.ToUser, "max_pending", o.cfg.MaxPending)
+		return ErrOutboxFull
+	}
+	now := o.now()
+	item.CreatedAt = now
+	item.NextAt = now
+	o.pending = append(o.pending, item)
+	o.mu.Unlock()
+
+	o.persist()
+	select {
+	case o.wakeCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// drain 投递所有到期且未被限流的消息。
+func (o *Outbox) drain(ctx context.Context) {
+	for {
+		item := o.next()
+		if item == nil {
+			return
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := o.deliver(sendCtx, item)
+		cancel()
+		o.complete(item, err)
+	}
+}
+
+// next 按入队顺序取出一条到期消息并扣减令牌；全局限流时返回 nil，成员限流时跳过该成员的消息。
+func (o *Outbox) next() *outboxItem {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	for _, item := range o.pending {
+		if item.sending || now.Before(item.NextAt) {
+			continue
+		}
+		if !o.global.allow(now) {
+			return nil
+		}
+		users := item.recipients()
+		buckets := make([]*tokenBucket, 0, len(users))
+		allowed := true
+		for _, u := range users {
+			b := o.users[u]
+			if b == nil {
+				b = newTokenBucket(o.cfg.PerUserPerMinute, now)
+				o.users[u] = b
+			}
+			if !b.allow(now) {
+				allowed = false
+				break
+			}
+			buckets = append(buckets, b)
+		}
+		if !allowed {
+			continue
+		}
+		o.global.take()
+		for _, b := range buckets {
+			b.take()
+		}
+		item.sending = true
+		return item
+	}
+	return nil
+}
+
+func (o *Outbox) deliver(ctx context.Context, item *outboxItem) error {
+	switch item.Kind {
+	case outboxKindText:
+		return o.base.SendText(ctx, TextMessage{ToUser: item.ToUser, ToParty: item.ToParty, ToTag: item.ToTag, Content: item.Content})
+	case outboxKindMarkdown:
+		return o.base.SendMarkdown(ctx, MarkdownMessage{ToUser: item.ToUser, Content: item.Content})
+	case outboxKindTemplateCard:
+		return o.base.SendTemplateCard(ctx, TemplateCardMessage{ToUser: item.ToUser, ToParty: item.ToParty, ToTag: item.ToTag, Card: item.Card})
+	default:
+		return errors.New("wecom outbox: 未知消息类型 " + item.Kind)
+	}
+}
+
+// complete 根据发送结果移除消息或按重试计划重新排期。
+func (o *Outbox) complete(item *outboxItem, err error) {
+	attrs := []any{"kind", item.Kind, "to_user", item.ToUser, "attempts", item.Attempts + 1, "merged", item.Merged}
+
+	o.mu.Lock()
+	item.sending = false
+	remove := true
+	if err != nil && outboxRetryable(err) && item.Attempts < len(o.cfg.RetrySchedule) {
+		delay := o.cfg.RetrySchedule[item.Attempts]
+		item.Attempts++
+		item.NextAt = o.now().Add(delay)
+		remove = false
+		slog.Warn("wecom outbox 发送失败，稍后重试", append(attrs, "error", err, "retry_in", delay.String())...)
+	}
+	if remove {
+		for i, p := range o.pending {
+			if p == item {
+				o.pending = append(o.pending[:i], o.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	o.mu.Unlock()
+
+	switch {
+	case err == nil:
+		slog.Info("wecom outbox 发送成功", attrs...)
+	case remove:
+		slog.Error("wecom outbox 发送失败，已放弃", append(attrs, "error", err)...)
+	}
+	o.persist()
+}
+
+// outboxRetryable 判断失败是否值得按计划重试：频率超限、暂时性错误（Client 内部的快速重试已用尽），
+// 以及 Client 不立即重试的超时/连接中断（间隔较长，降低重复投递的概率）。
+func outboxRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || isRetryable(err) || isNetworkError(err)
+}
+
+func (o *Outbox) persist() {
+	path := strings.TrimSpace(o.cfg.SpoolPath)
+	if path == "" {
+		return
+	}
+	o.persistMu.Lock()
+	defer o.persistMu.Unlock()
+
+	o.mu.Lock()
+	data, err := json.Marshal(o.pending)
+	o.mu.Unlock()
+	if err != nil {
+		slog.Warn("wecom outbox 落盘失败", "path", path, "error", err)
+		return
+	}
+
+	// 先写临时文件再 rename，避免进程中断时留下半截 JSON。
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.Warn("wecom outbox 落盘失败", "path", path, "error", err)
+		return
+	}
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		slog.Warn("wecom outbox 落盘失败", "path", path, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		slog.Warn("wecom outbox 落盘失败", "path", path, "error", err)
+	}
+}
+
+func (o *Outbox) load() {
+	path := strings.TrimSpace(o.cfg.SpoolPath)
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("wecom outbox 加载失败", "path", path, "error", err)
+		}
+		return
+	}
+	var items []*outboxItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		slog.Warn("wecom outbox 加载失败", "path", path, "error", err)
+		return
+	}
+
+	o.mu.Lock()
+	for _, item := range items {
+		if item == nil || len(o.pending) >= o.cfg.MaxPending {
+			continue
+		}
+		o.pending = append(o.pending, item)
+	}
+	count := len(o.pending)
+	o.mu.Unlock()
+	slog.Info("wecom outbox 已加载未发送消息", "path", path, "pending", count)
+}
+
+// outboxKey 计算消息指纹（类型 + 接收人 + 内容），用于合并相同的待发消息。
+func outboxKey(item *outboxItem) (string, error) {
+	body := item.Content
+	if item.Kind == outboxKindTemplateCard {
+		card := make(TemplateCard, len(item.Card))
+		for k, v := range item.Card {
+			if k == "task_id" {
+				continue
+			}
+			card[k] = v
+		}
+		b, err := json.Marshal(card)
+		if err != nil {
+			return "", err
+		}
+		body = string(b)
+	}
+	sum := sha256.Sum256([]byte(item.Kind + "\x00" + item.ToUser + "\x00" + item.ToParty + "\x00" + item.ToTag + "\x00" + body))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// recipients 返回限流使用的接收者键：成员按 userid，部门/标签整体各占一个令牌桶。
+func (item *outboxItem) recipients() []string {
+	out := splitToUser(item.ToUser)
+	for _, p := range splitToUser(item.ToParty) {
+		out = append(out, "party:"+p)
+	}
+	for _, t := range splitToUser(item.ToTag) {
+		out = append(out, "tag:"+t)
+	}
+	return out
+}
+
+func splitToUser(toUser string) []string {
+	var out []string
+	for _, u := range strings.Split(toUser, "|") {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// tokenBucket 为每分钟 perMinute 个令牌的令牌桶（容量同 perMinute，允许短时突发）。
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // 每秒补充的令牌数
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+	return b.tokens >= 1
+}
+
+func (b *tokenBucket) take() {
+	b.tokens--
+}
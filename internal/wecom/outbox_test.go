@@ -0,0 +1,143 @@
+package wecom
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordOutboxSender struct {
+	mu    sync.Mutex
+	texts []TextMessage
+	cards []TemplateCardMessage
+	errs  []error
+}
+
+func (s *recordOutboxSender) SendText(_ context.Context, msg TextMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.texts = append(s.texts, msg)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	return nil
+}
+
+func (s *recordOutboxSender) SendTemplateCard(_ context.Context, msg TemplateCardMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards = append(s.cards, msg)
+	return nil
+}
+
+func (s *recordOutboxSender) SendMarkdown(context.Context, MarkdownMessage) error { return nil }
+
+func (s *recordOutboxSender) sent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.texts) + len(s.cards)
+}
+
+func TestOutbox_RateLimitCoalesceAndRetry(t *testing.T) {
+	t.Parallel()
+
+	base := &recordOutboxSender{}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := NewOutbox(base, OutboxConfig{PerUserPerMinute: 2, GlobalPerMinute: 3, RetrySchedule: []time.Duration{time.Minute}})
+	o.now = func() time.Time { return now }
+	o.global = newTokenBucket(3, now)
+	ctx := context.Background()
+
+	for _, m := range []TextMessage{
+		{ToUser: "a", Content: "1"},
+		{ToUser: "a", Content: "1"}, // 与上一条相同：合并
+		{ToUser: "a", Content: "2"},
+		{ToUser: "a", Content: "3"}, // 超出成员限流
+		{ToUser: "b", Content: "1"},
+		{ToUser: "c", Content: "1"}, // 超出全局限流
+	} {
+		if err := o.SendText(ctx, m); err != nil {
+			t.Fatalf("SendText(%v) error: %v", m, err)
+		}
+	}
+	if got := o.Pending(); got != 5 {
+		t.Fatalf("Pending() = %d, want 5 (one merged)", got)
+	}
+
+	o.drain(ctx)
+	if got := base.sent(); got != 3 {
+		t.Fatalf("sent after first drain = %d, want 3", got)
+	}
+	if base.texts[2].ToUser != "b" {
+		t.Fatalf("third send = %v, want user b (user a rate limited)", base.texts[2])
+	}
+
+	// 一分钟后令牌恢复，剩余消息发出；a 的第一条发送失败（频率超限）进入重试。
+	now = now.Add(time.Minute)
+	base.errs = []error{&APIError{ErrCode: 45009, ErrMsg: "freq out of limit"}}
+	o.drain(ctx)
+	if got := base.sent(); got != 5 {
+		t.Fatalf("sent after second drain = %d, want 5", got)
+	}
+	if got := o.Pending(); got != 1 {
+		t.Fatalf("Pending() = %d, want 1 (scheduled retry)", got)
+	}
+
+	// 重试计划未到期时不发送；到期后发送成功并出队。
+	o.drain(ctx)
+	if got := base.sent(); got != 5 {
+		t.Fatalf("sent before retry due = %d, want 5", got)
+	}
+	now = now.Add(time.Minute)
+	o.drain(ctx)
+	if got := o.Pending(); got != 0 {
+		t.Fatalf("Pending() after retry = %d, want 0", got)
+	}
+
+	// 不可重试的错误直接放弃。
+	base.errs = []error{errors.New("invaliduser")}
+	_ = o.SendText(ctx, TextMessage{ToUser: "d", Content: "x"})
+	o.drain(ctx)
+	if got := o.Pending(); got != 0 {
+		t.Fatalf("Pending() after permanent failure = %d, want 0", got)
+	}
+}
+
+func TestOutbox_SpoolSurvivesRestart(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	first := NewOutbox(&recordOutboxSender{}, OutboxConfig{SpoolPath: path})
+	card := TemplateCard{"card_type": "button_interaction", "main_title": map[string]interface{}{"title": "UPS"}}
+	if err := first.SendTemplateCard(context.Background(), TemplateCardMessage{ToUser: "a", Card: card}); err != nil {
+		t.Fatalf("SendTemplateCard() error: %v", err)
+	}
+	if err := first.SendText(context.Background(), TextMessage{ToUser: "a", Content: "告警"}); err != nil {
+		t.Fatalf("SendText() error: %v", err)
+	}
+
+	base := &recordOutboxSender{}
+	second := NewOutbox(base, OutboxConfig{SpoolPath: path})
+	second.load()
+	if got := second.Pending(); got != 2 {
+		t.Fatalf("Pending() after load = %d, want 2", got)
+	}
+	second.drain(context.Background())
+	if len(base.cards) != 1 || base.cards[0].Card["card_type"] != "button_interaction" {
+		t.Fatalf("cards = %v", base.cards)
+	}
+	if len(base.texts) != 1 || base.texts[0].Content != "告警" {
+		t.Fatalf("texts = %v", base.texts)
+	}
+
+	third := NewOutbox(&recordOutboxSender{}, OutboxConfig{SpoolPath: path})
+	third.load()
+	if got := third.Pending(); got != 0 {
+		t.Fatalf("Pending() after drained spool = %d, want 0", got)
+	}
+}
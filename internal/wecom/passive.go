@@ -0,0 +1,35 @@
+package wecom
+
+// passive.go 定义被动回复：在回调响应中直接返回加密消息，省去一次 message/send 调用（需在 5 秒内响应）。
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// PassiveReplier 为 MessageHandler 的可选能力：返回 ok=true 时以被动回复响应，且不再调用 HandleMessage。
+// 仅适用于无需调用外部服务即可立即给出的简短文本答复（例如自检、帮助）。
+type PassiveReplier interface {
+	PassiveReply(ctx context.Context, msg IncomingMessage) (content string, ok bool)
+}
+
+type textReply struct {
+	XMLName      xml.Name `xml:"xml"`
+	ToUserName   cdata    `xml:"ToUserName"`
+	FromUserName cdata    `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      cdata    `xml:"MsgType"`
+	Content      cdata    `xml:"Content"`
+}
+
+// BuildTextReply 构造被动回复的文本消息明文：收发方与来信相反（ToUserName 为成员，FromUserName 为企业 ID）。
+func BuildTextReply(msg IncomingMessage, content string, now time.Time) ([]byte, error) {
+	return xml.Marshal(textReply{
+		ToUserName:   cdata{strings.TrimSpace(msg.FromUserName)},
+		FromUserName: cdata{strings.TrimSpace(msg.ToUserName)},
+		CreateTime:   now.Unix(),
+		MsgType:      cdata{"text"},
+		Content:      cdata{content},
+	})
+}
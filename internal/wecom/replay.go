@@ -0,0 +1,91 @@
+package wecom
+
+// replay.go 为回调提供重放防护：timestamp 需在允许的时钟偏差内，且 (timestamp, nonce) 在窗口内只接受一次。
+// 与 Deduper（按消息去重、TTL 10 分钟）互补：即使截获的请求在去重过期后重放，也会因 timestamp 过期被拒绝。
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrCallbackStale 表示 timestamp 缺失/非法或超出允许的时钟偏差。
+	ErrCallbackStale = errors.New("wecom callback timestamp 超出允许范围")
+	// ErrCallbackReplay 表示 (timestamp, nonce) 已被接受过。
+	ErrCallbackReplay = errors.New("wecom callback nonce 重复")
+)
+
+// ReplayStats 为重放防护的拒绝计数（与验签失败分开统计）。
+type ReplayStats struct {
+	Stale    uint64
+	Replayed uint64
+}
+
+// ReplayGuard 校验回调的 timestamp 与 nonce；零值不可用，请使用 NewReplayGuard。
+type ReplayGuard struct {
+	maxSkew time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+
+	stale    atomic.Uint64
+	replayed atomic.Uint64
+}
+
+// NewReplayGuard 创建重放防护；maxSkew 为 timestamp 与本机时间允许的最大偏差（默认 5 分钟）。
+func NewReplayGuard(maxSkew time.Duration) *ReplayGuard {
+	if maxSkew <= 0 {
+		maxSkew = 5 * time.Minute
+	}
+	return &ReplayGuard{
+		maxSkew: maxSkew,
+		now:     time.Now,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+// Check 校验并记录一次回调；应在验签通过后调用，避免未签名请求占用 nonce 缓存。
+func (g *ReplayGuard) Check(timestamp, nonce string) error {
+	now := g.now()
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		g.stale.Add(1)
+		return ErrCallbackStale
+	}
+	ts := time.Unix(sec, 0)
+	if skew := now.Sub(ts); skew > g.maxSkew || skew < -g.maxSkew {
+		g.stale.Add(1)
+		return ErrCallbackStale
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.lastSweep) >= time.Minute {
+		g.lastSweep = now
+		for k, exp := range g.nonces {
+			if now.After(exp) {
+				delete(g.nonces, k)
+			}
+		}
+	}
+	key := timestamp + ":" + nonce
+	if exp, ok := g.nonces[key]; ok && !now.After(exp) {
+		g.replayed.Add(1)
+		return ErrCallbackReplay
+	}
+	// 超过 ts+maxSkew 后 timestamp 校验即会拒绝，nonce 无需再保留。
+	g.nonces[key] = ts.Add(g.maxSkew)
+	return nil
+}
+
+// Stats 返回累计拒绝次数。
+func (g *ReplayGuard) Stats() ReplayStats {
+	if g == nil {
+		return ReplayStats{}
+	}
+	return ReplayStats{Stale: g.stale.Load(), Replayed: g.replayed.Load()}
+}
@@ -0,0 +1,105 @@
+package wecom
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReplayGuard_RejectsStaleAndReusedNonce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	g := NewReplayGuard(5 * time.Minute)
+	g.now = func() time.Time { return now }
+
+	ts := strconv.FormatInt(now.Unix(), 10)
+	if err := g.Check(ts, "n1"); err != nil {
+		t.Fatalf("first Check() error = %v", err)
+	}
+	if err := g.Check(ts, "n1"); !errors.Is(err, ErrCallbackReplay) {
+		t.Fatalf("reused nonce error = %v, want %v", err, ErrCallbackReplay)
+	}
+	if err := g.Check(ts, "n2"); err != nil {
+		t.Fatalf("new nonce error = %v", err)
+	}
+
+	old := strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10)
+	if err := g.Check(old, "n3"); !errors.Is(err, ErrCallbackStale) {
+		t.Fatalf("stale timestamp error = %v, want %v", err, ErrCallbackStale)
+	}
+	future := strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10)
+	if err := g.Check(future, "n4"); !errors.Is(err, ErrCallbackStale) {
+		t.Fatalf("future timestamp error = %v, want %v", err, ErrCallbackStale)
+	}
+	if err := g.Check("abc", "n5"); !errors.Is(err, ErrCallbackStale) {
+		t.Fatalf("invalid timestamp error = %v, want %v", err, ErrCallbackStale)
+	}
+
+	// 窗口过后 nonce 缓存被清理，但同一 timestamp 已过期，仍会被拒绝。
+	now = now.Add(10 * time.Minute)
+	if err := g.Check(ts, "n1"); !errors.Is(err, ErrCallbackStale) {
+		t.Fatalf("expired replay error = %v, want %v", err, ErrCallbackStale)
+	}
+	if err := g.Check(strconv.FormatInt(now.Unix(), 10), "n1"); err != nil {
+		t.Fatalf("fresh Check() error = %v", err)
+	}
+	if n := len(g.nonces); n != 1 {
+		t.Fatalf("nonces = %d, want 1 after sweep", n)
+	}
+
+	if got, want := g.Stats(), (ReplayStats{Stale: 4, Replayed: 1}); got != want {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCallbackHandler_ReplayGuard(t *testing.T) {
+	t.Parallel()
+
+	token := "test-token"
+	crypto := mustTestCrypto(t, token, "ww123")
+	core := &testCoreHandler{}
+	replay := NewReplayGuard(5 * time.Minute)
+	replay.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	h := NewCallbackHandler(CallbackDeps{
+		Crypto: crypto,
+		Core:   core,
+		Replay: replay,
+	})
+
+	plain := []byte("<xml>" +
+		"<ToUserName><![CDATA[to]]></ToUserName>" +
+		"<FromUserName><![CDATA[user]]></FromUserName>" +
+		"<CreateTime>1700000000</CreateTime>" +
+		"<MsgType><![CDATA[text]]></MsgType>" +
+		"<Content><![CDATA[菜单]]></Content>" +
+		"</xml>")
+	encrypted := mustEncrypt(t, crypto, plain)
+	body := []byte("<xml><Encrypt><![CDATA[" + encrypted + "]]></Encrypt></xml>")
+
+	post := func(timestamp, nonce string) *httptest.ResponseRecorder {
+		sig := signature(token, timestamp, nonce, encrypted)
+		req := httptest.NewRequest(http.MethodPost, "/wecom/callback?msg_signature="+sig+"&timestamp="+timestamp+"&nonce="+nonce, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("1700000001", "nonce"); w.Code != http.StatusOK {
+		t.Fatalf("first status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := post("1700000001", "nonce"); w.Code != http.StatusOK || w.Body.String() != "success" {
+		t.Fatalf("replay status = %d body = %q, want 200 success", w.Code, w.Body.String())
+	}
+	if w := post("1699990000", "other"); w.Code != http.StatusForbidden {
+		t.Fatalf("stale status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if got := core.Calls(); got != 1 {
+		t.Fatalf("core calls = %d, want 1", got)
+	}
+}
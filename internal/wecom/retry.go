@@ -0,0 +1,206 @@
+package wecom
+
+// retry.go 为应用接口提供错误分类与重试：access_token 失效时强制刷新并重试一次，
+// 系统繁忙/5xx/建连失败按指数退避（带抖动）重试，频率超限以 ErrRateLimited 上抛由调用方处理。
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrRateLimited 表示接口调用频率超限（45009 等）；可用 errors.Is 判断。
+var ErrRateLimited = errors.New("wecom 接口调用频率超限")
+
+// APIError 为企业微信接口返回的非 0 errcode。
+type APIError struct {
+	Endpoint string
+	ErrCode  int
+	ErrMsg   string
+}
+
+func (e *APIError) Error() string {
+	if e.Endpoint == "gettoken" {
+		return fmt.Sprintf("wecom gettoken error: %d %s", e.ErrCode, e.ErrMsg)
+	}
+	return fmt.Sprintf("wecom api error: %d %s", e.ErrCode, e.ErrMsg)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrRateLimited && e.RateLimited()
+}
+
+// TokenInvalid 判断是否为 access_token 失效（不合法/过期/凭证错误）。
+func (e *APIError) TokenInvalid() bool {
+	switch e.ErrCode {
+	case 40001, 40014, 42001:
+		return true
+	default:
+		return false
+	}
+}
+
+// Busy 判断是否为系统繁忙（可稍后重试）。
+func (e *APIError) Busy() bool {
+	return e.ErrCode == -1
+}
+
+// RateLimited 判断是否为调用频率/并发超限。
+//
+// 官方文档（SSOT）：全局错误码
+// https://developer.work.weixin.qq.com/document/path/90313
+func (e *APIError) RateLimited() bool {
+	switch e.ErrCode {
+	case 45009, 45011, 45033:
+		return true
+	default:
+		return false
+	}
+}
+
+// HTTPStatusError 表示接口返回了非 2xx 的 HTTP 状态（响应体通常不是 JSON）。
+type HTTPStatusError struct {
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("wecom %s HTTP 状态异常: %d", e.Endpoint, e.StatusCode)
+}
+
+// checkHTTPStatus 在解析响应前拦截 5xx，便于按可重试错误处理。
+func checkHTTPStatus(endpoint string, res *http.Response) error {
+	if res.StatusCode >= http.StatusInternalServerError {
+		return &HTTPStatusError{Endpoint: endpoint, StatusCode: res.StatusCode}
+	}
+	return nil
+}
+
+// RetryPolicy 定义退避重试参数；零值使用默认（最多 3 次，200ms 起步，上限 2s）。
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 200 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 2 * time.Second
+	}
+	return p
+}
+
+// backoff 返回第 attempt 次失败后的等待时长：指数增长并在 [d/2, d] 内随机抖动。
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+func isTokenInvalid(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.TokenInvalid()
+}
+
+// isRetryable 判断错误是否可由 Client 立即重试：系统繁忙、5xx，或请求未发出（建连/DNS 失败）。
+// 超时、连接中断等请求可能已送达的网络错误不在此重试，避免 message/send 等非幂等接口重复投递；
+// 此类错误由 Outbox 按重试计划处理（见 outboxRetryable）。
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Busy()
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isDialError(err)
+}
+
+// isDialError 判断请求是否在建立连接阶段失败（服务端一定未收到请求）。
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isNetworkError 判断是否为传输层错误（含超时与连接中断）。
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// withRetry 获取 access_token 并执行 call，按错误分类刷新 token 或退避重试。
+// token 失效的强制刷新仅一次，且不计入退避次数。
+func (c *Client) withRetry(ctx context.Context, endpoint string, call func(token string) error) error {
+	policy := c.cfg.Retry.withDefaults()
+	tokenRefreshed := false
+	for attempt := 1; ; attempt++ {
+		token, err := c.getAccessToken(ctx)
+		if err != nil {
+			slog.Error("wecom "+endpoint+" 获取 access_token 失败", "error", err, "attempt", attempt)
+		} else {
+			err = call(token)
+			if err == nil {
+				return nil
+			}
+			if !tokenRefreshed && isTokenInvalid(err) {
+				tokenRefreshed = true
+				c.invalidateAccessToken(token)
+				slog.Warn("wecom access_token 已失效，强制刷新后重试", "endpoint", endpoint, "error", err)
+				attempt--
+				continue
+			}
+		}
+
+		if !isRetryable(err) || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		delay := policy.backoff(attempt)
+		slog.Warn("wecom 接口暂时失败，退避后重试",
+			"endpoint", endpoint,
+			"attempt", attempt,
+			"max_attempts", policy.MaxAttempts,
+			"delay_ms", delay.Milliseconds(),
+			"error", err,
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// invalidateAccessToken 清除缓存的 token（仅当仍是失效的那一个，避免覆盖并发刷新后的新 token）。
+func (c *Client) invalidateAccessToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken == token {
+		c.accessToken = ""
+		c.accessTokenExp = time.Time{}
+	}
+}
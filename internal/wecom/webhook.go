@@ -0,0 +1,156 @@
+package wecom
+
+// webhook.go 封装群机器人（webhook/send）发送，作为只有机器人的群的通知出口。
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type WebhookConfig struct {
+	// APIBaseURL 与应用接口一致（默认 https://qyapi.weixin.qq.com/cgi-bin），实际地址为 {APIBaseURL}/webhook/send?key=...
+	APIBaseURL string
+	Key        string
+}
+
+// NewsArticle 定义图文消息的一篇文章（群机器人最多 8 篇）。
+type NewsArticle struct {
+	Title       string
+	Description string
+	URL         string
+	PicURL      string
+}
+
+type NewsMessage struct {
+	Articles []NewsArticle
+}
+
+// ErrWebhookTemplateCardUnsupported 表示群机器人无法发送交互型模板卡片（无回调）。
+var ErrWebhookTemplateCardUnsupported = errors.New("群机器人不支持交互型模板卡片")
+
+// WebhookClient 为群机器人发送端：实现与应用消息一致的 SendText/SendMarkdown（ToUser 被忽略，消息发到机器人所在群）。
+type WebhookClient struct {
+	cfg        WebhookConfig
+	httpClient *http.Client
+}
+
+func NewWebhookClient(cfg WebhookConfig, httpClient *http.Client) *WebhookClient {
+	return &WebhookClient{cfg: cfg, httpClient: httpClient}
+}
+
+// SendText 发送文本消息（content 上限 2048 字节）。
+//
+// 官方文档（SSOT）：群机器人配置说明
+// https://developer.work.weixin.qq.com/document/path/91770
+func (c *WebhookClient) SendText(ctx context.Context, msg TextMessage) error {
+	return c.send(ctx, "text", map[string]interface{}{"content": msg.Content})
+}
+
+// SendMarkdown 发送 markdown 消息（content 上限 4096 字节）。
+func (c *WebhookClient) SendMarkdown(ctx context.Context, msg MarkdownMessage) error {
+	return c.send(ctx, "markdown", map[string]interface{}{"content": msg.Content})
+}
+
+// SendNews 发送图文消息。
+func (c *WebhookClient) SendNews(ctx context.Context, msg NewsMessage) error {
+	if len(msg.Articles) == 0 {
+		return errors.New("wecom webhook: articles 为空")
+	}
+	articles := make([]map[string]interface{}, 0, len(msg.Articles))
+	for _, a := range msg.Articles {
+		item := map[string]interface{}{
+			"title": a.Title,
+			"url":   a.URL,
+		}
+		if a.Description != "" {
+			item["description"] = a.Description
+		}
+		if a.PicURL != "" {
+			item["picurl"] = a.PicURL
+		}
+		articles = append(articles, item)
+	}
+	return c.send(ctx, "news", map[string]interface{}{"articles": articles})
+}
+
+// SendTemplateCard 群机器人无法接收按钮回调，交互型卡片直接返回 ErrWebhookTemplateCardUnsupported。
+func (c *WebhookClient) SendTemplateCard(_ context.Context, _ TemplateCardMessage) error {
+	return ErrWebhookTemplateCardUnsupported
+}
+
+// Notify 以文本形式推送通知（满足 core.Notifier）。
+func (c *WebhookClient) Notify(ctx context.Context, content string) error {
+	return c.SendText(ctx, TextMessage{Content: content})
+}
+
+func (c *WebhookClient) send(ctx context.Context, msgType string, body map[string]interface{}) error {
+	start := time.Now()
+	key := strings.TrimSpace(c.cfg.Key)
+	if key == "" {
+		return errors.New("wecom webhook: key 为空")
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"msgtype": msgType,
+		msgType:   body,
+	})
+	if err != nil {
+		return err
+	}
+
+	u := strings.TrimRight(c.cfg.APIBaseURL, "/") + "/webhook/send?key=" + url.QueryEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		// url.Error 会携带完整地址，避免 key 出现在日志与错误信息中。
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			ue.URL = strings.Replace(ue.URL, url.QueryEscape(key), "***", 1)
+		}
+		slog.Error("wecom webhook/send HTTP 请求失败",
+			"error", err,
+			"msgtype", msgType,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		return err
+	}
+	defer res.Body.Close()
+
+	var out apiResult
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		slog.Error("wecom webhook/send 解析响应失败",
+			"error", err,
+			"msgtype", msgType,
+			"status_code", res.StatusCode,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		return err
+	}
+
+	attrs := []any{
+		"msgtype", msgType,
+		"status_code", res.StatusCode,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"errcode", out.ErrCode,
+		"errmsg", out.ErrMsg,
+	}
+	if out.ErrCode != 0 {
+		apiErr := fmt.Errorf("wecom webhook error: %d %s", out.ErrCode, out.ErrMsg)
+		slog.Error("wecom webhook/send 返回错误", append(attrs, "error", apiErr)...)
+		return apiErr
+	}
+	slog.Info("wecom webhook/send 成功", attrs...)
+	return nil
+}